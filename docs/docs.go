@@ -15,41 +15,49 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/auth/login": {
-            "post": {
-                "description": "Авторизация пользователя",
-                "consumes": [
-                    "application/json"
-                ],
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Набор открытых ключей (JWKS) для проверки подписи токенов, выпущенных шлюзом",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Authentication"
                 ],
-                "summary": "Авторизация",
-                "parameters": [
-                    {
-                        "description": "Данные для авторизации пользователя",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                "summary": "Открытые ключи JWT",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.LoginRequest"
+                            "$ref": "#/definitions/token.JWKS"
                         }
                     }
+                }
+            }
+        },
+        "/api/v1/apikeys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Все выпущенные ключи API, включая отозванные и истекшие, со временем последнего использования",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
+                "tags": [
+                    "ApiKeys"
+                ],
+                "summary": "Список ключей API",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.LoginResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/server.HTTPError"
+                            "$ref": "#/definitions/server.ApiKeysResponse"
                         }
                     },
                     "401": {
@@ -58,8 +66,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -71,11 +79,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/auth/registration": {
+            },
             "post": {
-                "description": "Регистрация нового пользователя пользователя",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выпускает ключ API для межсервисных запросов. Ключ возвращается только в этом ответе, хранится только его хеш",
                 "consumes": [
                     "application/json"
                 ],
@@ -83,16 +94,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "ApiKeys"
                 ],
-                "summary": "Регистрация пользователя",
+                "summary": "Выпуск ключа API",
                 "parameters": [
                     {
-                        "description": "Данные для регистрации",
-                        "name": "user",
+                        "description": "Назначение, области и срок действия ключа",
+                        "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/server.RegistrationRequest"
+                            "$ref": "#/definitions/server.ApiKeyRequest"
                         }
                     }
                 ],
@@ -100,7 +112,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.LoginResponse"
+                            "$ref": "#/definitions/server.ApiKeyResponse"
                         }
                     },
                     "400": {
@@ -109,8 +121,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -124,14 +142,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/card/company": {
-            "get": {
+        "/api/v1/apikeys/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Банковская карта компании в базе данных",
+                "description": "Отзывает ключ API, запросы с ним перестают приниматься сразу",
                 "consumes": [
                     "application/json"
                 ],
@@ -139,18 +157,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "CardCompany"
+                    "ApiKeys"
+                ],
+                "summary": "Отзыв ключа API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Банковская карта компании",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CardsCompaniesResponse"
+                            "$ref": "#/definitions/server.ApiKeyResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -168,14 +201,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/v1/auth/2fa/confirm": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновление банковской карты компании",
+                "description": "Включает двухфакторную аутентификацию, если код соответствует секрету из /auth/2fa/setup",
                 "consumes": [
                     "application/json"
                 ],
@@ -183,16 +218,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "CardCompany"
+                    "Authentication"
                 ],
-                "summary": "Обновление банковской карты компании",
+                "summary": "Подтверждение настройки 2FA",
                 "parameters": [
                     {
-                        "description": "Модель для обновления",
-                        "name": "card",
+                        "description": "Код из приложения-аутентификатора",
+                        "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
+                            "$ref": "#/definitions/server.MfaCodeRequest"
                         }
                     }
                 ],
@@ -200,7 +236,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
+                            "$ref": "#/definitions/server.MfaStatusResponse"
                         }
                     },
                     "400": {
@@ -209,10 +245,28 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд разрешена следующая попытка"
+                            }
                         }
                     },
                     "500": {
@@ -222,9 +276,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/auth/2fa/disable": {
             "post": {
-                "description": "Создание банковской карты компании",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отключает двухфакторную аутентификацию по действующему коду. Для ролей с обязательной 2FA недоступно",
                 "consumes": [
                     "application/json"
                 ],
@@ -232,16 +293,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "CardCompany"
+                    "Authentication"
                 ],
-                "summary": "Создание банковской карты компании",
+                "summary": "Отключение 2FA",
                 "parameters": [
                     {
-                        "description": "Сущность банковской карты компании",
-                        "name": "card",
+                        "description": "Код из приложения-аутентификатора",
+                        "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateCardCompanyRequest"
+                            "$ref": "#/definitions/server.MfaCodeRequest"
                         }
                     }
                 ],
@@ -249,7 +311,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Card"
+                            "$ref": "#/definitions/server.MfaStatusResponse"
                         }
                     },
                     "400": {
@@ -258,12 +320,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд разрешена следующая попытка"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -273,14 +353,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/card/company/deleteModel": {
+        "/api/v1/auth/2fa/login": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Удаляет банковскую карту компании опираясь на всю сущность модели",
+                "description": "Обменивает токен второго шага входа и код TOTP на пару токенов. Для ролей с обязательной 2FA первый код подтверждает настройку",
                 "consumes": [
                     "application/json"
                 ],
@@ -288,16 +363,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "CardCompany"
+                    "Authentication"
                 ],
-                "summary": "Удаление банковской карты компании по модели",
+                "summary": "Второй шаг входа",
                 "parameters": [
                     {
-                        "description": "Сущность банковской карты компании",
-                        "name": "card",
+                        "description": "Токен второго шага и код TOTP",
+                        "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
+                            "$ref": "#/definitions/server.MfaLoginRequest"
                         }
                     }
                 ],
@@ -305,7 +381,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.LoginResponse"
                         }
                     },
                     "400": {
@@ -314,8 +390,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -329,48 +411,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/card/company/{id}": {
-            "get": {
+        "/api/v1/auth/2fa/setup": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Поиск банковской карты компании по ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Создает секрет TOTP и возвращает URI otpauth:// для приложения-аутентификатора. 2FA включается после подтверждения кодом",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "CardCompany"
-                ],
-                "summary": "Поиск банковской карты компании",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Card ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Authentication"
                 ],
+                "summary": "Настройка 2FA",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
+                            "$ref": "#/definitions/server.MfaSetupResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -382,14 +452,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Удаление банковской карты компании по ID",
+            }
+        },
+        "/api/v1/auth/login": {
+            "post": {
+                "description": "Авторизация пользователя",
                 "consumes": [
                     "application/json"
                 ],
@@ -397,23 +464,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "CardCompany"
+                    "Authentication"
                 ],
-                "summary": "Удаление банковской карты компании",
+                "summary": "Авторизация",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID банковской карты компании",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Данные для авторизации пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.LoginResponse"
                         }
                     },
                     "400": {
@@ -422,12 +491,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд разрешена следующая попытка"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -437,14 +524,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/cards": {
-            "get": {
+        "/api/v1/auth/logout": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Массив банковских карт в базе данных",
+                "description": "Завершает текущую сессию, refresh токен сессии становится недействительным",
                 "consumes": [
                     "application/json"
                 ],
@@ -452,18 +539,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Cards"
+                    "Authentication"
                 ],
-                "summary": "Список всех банковских карт",
+                "summary": "Выход из аккаунта",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CardsResponse"
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -481,9 +568,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/auth/otp/request": {
             "post": {
-                "description": "Создание банковской карты пользователя",
+                "description": "Отправляет одноразовый код на номер телефона. Ответ не зависит от существования аккаунта",
                 "consumes": [
                     "application/json"
                 ],
@@ -491,16 +580,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Cards"
+                    "Authentication"
                 ],
-                "summary": "Создание банковской карты пользователя",
+                "summary": "Запрос кода для входа по SMS",
                 "parameters": [
                     {
-                        "description": "Сущность банковской карты",
-                        "name": "card",
+                        "description": "Номер телефона",
+                        "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateCardRequest"
+                            "$ref": "#/definitions/server.OtpRequest"
                         }
                     }
                 ],
@@ -508,7 +598,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Card"
+                            "$ref": "#/definitions/server.CodeSentResponse"
                         }
                     },
                     "400": {
@@ -517,10 +607,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд разрешен следующий запрос"
+                            }
                         }
                     },
                     "500": {
@@ -532,14 +628,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/cards/deleteModel": {
+        "/api/v1/auth/otp/verify": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Удаляет банковскую карту опираясь на всю сущность модели",
+                "description": "Обменивает одноразовый код на пару токенов, как при входе по паролю",
                 "consumes": [
                     "application/json"
                 ],
@@ -547,16 +638,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Cards"
+                    "Authentication"
                 ],
-                "summary": "Удаление банковской карты по модели",
+                "summary": "Вход по коду из SMS",
                 "parameters": [
                     {
-                        "description": "Модель банковской карты",
-                        "name": "card",
+                        "description": "Номер телефона и код",
+                        "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Card"
+                            "$ref": "#/definitions/server.OtpVerifyRequest"
                         }
                     }
                 ],
@@ -564,7 +656,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.LoginResponse"
                         }
                     },
                     "400": {
@@ -573,10 +665,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд разрешена следующая попытка"
+                            }
                         }
                     },
                     "500": {
@@ -588,14 +686,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/cards/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Поиск банковской карты по ID",
+        "/api/v1/auth/password/forgot": {
+            "post": {
+                "description": "Отправляет одноразовый код на телефон или email аккаунта. Ответ не зависит от существования аккаунта",
                 "consumes": [
                     "application/json"
                 ],
@@ -603,23 +696,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Cards"
+                    "Authentication"
                 ],
-                "summary": "Поиск банковской карты",
+                "summary": "Запрос кода для сброса пароля",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Card ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Телефон или email аккаунта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.ForgotPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Card"
+                            "$ref": "#/definitions/server.CodeSentResponse"
                         }
                     },
                     "400": {
@@ -628,10 +723,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд разрешен следующий запрос"
+                            }
                         }
                     },
                     "500": {
@@ -641,14 +742,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Обновление банковской карты",
+            }
+        },
+        "/api/v1/auth/password/reset": {
+            "post": {
+                "description": "Устанавливает новый пароль по одноразовому коду, все сессии пользователя завершаются",
                 "consumes": [
                     "application/json"
                 ],
@@ -656,24 +754,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Cards"
+                    "Authentication"
                 ],
-                "summary": "Обновление банковской карты",
+                "summary": "Сброс пароля",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID банковской карты",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Модель для обновления",
-                        "name": "card",
+                        "description": "Телефон или email аккаунта, код и новый пароль",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.UpdateUserCardRequest1"
+                            "$ref": "#/definitions/server.ResetPasswordRequest"
                         }
                     }
                 ],
@@ -681,7 +772,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.UpdateUserCardResponse"
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
                     },
                     "400": {
@@ -690,10 +781,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд разрешена следующая попытка"
+                            }
                         }
                     },
                     "500": {
@@ -703,14 +800,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Удаление банковской карты пользователя по ID",
+            }
+        },
+        "/api/v1/auth/refresh": {
+            "post": {
+                "description": "Ротация refresh токена и выпуск нового токена доступа. Повторное использование уже ротированного refresh токена отзывает сессию",
                 "consumes": [
                     "application/json"
                 ],
@@ -718,23 +812,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Cards"
+                    "Authentication"
                 ],
-                "summary": "Удаление банковской карты пользователя",
+                "summary": "Обновление токенов",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID банковской карты",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Refresh токен",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.RefreshRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.LoginResponse"
                         }
                     },
                     "400": {
@@ -743,8 +839,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -758,14 +854,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/companies": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Массив компаний в базе данных",
+        "/api/v1/auth/registration": {
+            "post": {
+                "description": "Регистрация нового пользователя пользователя",
                 "consumes": [
                     "application/json"
                 ],
@@ -773,14 +864,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
+                    "Authentication"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные для регистрации",
+                        "name": "user",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/server.RegistrationRequest"
+                        }
+                    }
                 ],
-                "summary": "Список всех компаний",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CompaniesResponse"
+                            "$ref": "#/definitions/server.LoginResponse"
                         }
                     },
                     "400": {
@@ -795,6 +896,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -802,14 +909,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/v1/auth/sessions": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновление сущности компании",
+                "description": "Устройства, на которых выполнен вход в аккаунт текущего пользователя. DatabaseService ищет по пользователю\nтолько одну сессию, поэтому список содержит ее и текущую сессию, остальные устройства не показываются",
                 "consumes": [
                     "application/json"
                 ],
@@ -817,34 +926,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
-                ],
-                "summary": "Обновление компании",
-                "parameters": [
-                    {
-                        "description": "Модель для обновления",
-                        "name": "company",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.UpdateCompanyRequest"
-                        }
-                    }
+                    "Authentication"
                 ],
+                "summary": "Список сессий пользователя",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/server.HTTPError"
+                            "$ref": "#/definitions/server.SessionsResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -856,9 +949,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Создание новой сущности компании",
+            }
+        },
+        "/api/v1/auth/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаленное завершение сессии текущего пользователя по ID (например, с утерянного устройства)",
                 "consumes": [
                     "application/json"
                 ],
@@ -866,24 +966,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
+                    "Authentication"
                 ],
-                "summary": "Создание компании",
+                "summary": "Завершение сессии",
                 "parameters": [
                     {
-                        "description": "Сущность компании",
-                        "name": "company",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateCompanyRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Company"
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
                     },
                     "400": {
@@ -892,6 +991,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -907,43 +1012,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/companies/": {
+        "/api/v1/auth/verify": {
             "get": {
-                "description": "Поиск компании по ее phone",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Показывает, подтверждены ли текущие телефон и email пользователя",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
-                ],
-                "summary": "Поиск компании по номеру телефона",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Phone",
-                        "name": "phone",
-                        "in": "query",
-                        "required": true
-                    }
+                    "Authentication"
                 ],
+                "summary": "Состояние подтверждения контактов",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Company"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/server.HTTPError"
+                            "$ref": "#/definitions/server.VerificationResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -957,14 +1049,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/companies/addCard": {
+        "/api/v1/auth/verify/email": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Добавляет банковскую карту компании, поле companyId это ID компании в базе данных, которой будем добавлять карту",
+                "description": "Без кода отправляет одноразовый код на email пользователя, с кодом - подтверждает email",
                 "consumes": [
                     "application/json"
                 ],
@@ -972,16 +1064,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
+                    "Authentication"
                 ],
-                "summary": "Добавляет банковскую карту компании",
+                "summary": "Подтверждение email",
                 "parameters": [
                     {
-                        "description": "Сущность банковской карты",
-                        "name": "card",
+                        "description": "Код из письма",
+                        "name": "request",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.AddCardToCompanyRequest"
+                            "$ref": "#/definitions/server.VerifyRequest"
                         }
                     }
                 ],
@@ -989,7 +1081,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.AddCardToCompanyResponse"
+                            "$ref": "#/definitions/server.VerificationResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/server.CodeSentResponse"
                         }
                     },
                     "400": {
@@ -998,8 +1096,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -1013,14 +1117,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/companies/deleteModel": {
+        "/api/v1/auth/verify/phone": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет компании опираясь на всю сущность модели",
+                "description": "Без кода отправляет одноразовый код на телефон пользователя, с кодом - подтверждает телефон",
                 "consumes": [
                     "application/json"
                 ],
@@ -1028,16 +1132,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
+                    "Authentication"
                 ],
-                "summary": "Удаление компании по модели",
+                "summary": "Подтверждение телефона",
                 "parameters": [
                     {
-                        "description": "Модель компании",
-                        "name": "company",
+                        "description": "Код из SMS",
+                        "name": "request",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.DeleteCompanyByModelRequest"
+                            "$ref": "#/definitions/server.VerifyRequest"
                         }
                     }
                 ],
@@ -1045,7 +1149,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.VerificationResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/server.CodeSentResponse"
                         }
                     },
                     "400": {
@@ -1054,8 +1164,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -1069,14 +1185,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/companies/{id}": {
+        "/api/v1/card/company": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Поиск компании по ID",
+                "description": "Банковская карта компании в базе данных",
                 "consumes": [
                     "application/json"
                 ],
@@ -1084,23 +1200,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
+                    "CardCompany"
                 ],
-                "summary": "Поиск компании",
+                "summary": "Банковская карта компании",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Company ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из заголовка Link",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Сортировка: id, companyId, createdAt, updatedAt, \\",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр поле:значение через запятую, поля: companyId",
+                        "name": "filter",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Company"
+                            "$ref": "#/definitions/server.CardCompaniesResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Количество элементов с учетом фильтра"
+                            }
                         }
                     },
                     "400": {
@@ -1109,6 +1253,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1122,16 +1272,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/companies/{id}/card": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Извлечение банковской карты компании по ее ID",
+                "description": "Обновление банковской карты компании",
                 "consumes": [
                     "application/json"
                 ],
@@ -1139,23 +1287,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
+                    "CardCompany"
                 ],
-                "summary": "Извлечение банковской карты компании",
+                "summary": "Обновление банковской карты компании",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Company ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Модель для обновления",
+                        "name": "card",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/server.CardCompanyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
+                            "$ref": "#/definitions/server.CardCompanyResponse"
                         }
                     },
                     "400": {
@@ -1164,6 +1313,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1177,16 +1332,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/company/{id}": {
-            "delete": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаление компании по ID",
+                "description": "Создание банковской карты компании",
                 "consumes": [
                     "application/json"
                 ],
@@ -1194,23 +1347,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Company"
+                    "CardCompany"
                 ],
-                "summary": "Удаление компании",
+                "summary": "Создание банковской карты компании",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID компании",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Сущность банковской карты компании",
+                        "name": "card",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/server.CardCompanyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.CardCompanyResponse"
                         }
                     },
                     "400": {
@@ -1219,49 +1373,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
-                    }
-                }
-            }
-        },
-        "/api/v1/donations": {
-            "get": {
-                "description": "Список всех пожертвований в базе данных",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Donations"
-                ],
-                "summary": "Список всех пожертвований",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.DonationsResponse"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -1273,14 +1404,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/v1/card/company/deleteModel": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновление пожертвования",
+                "description": "Удаляет банковскую карту компании опираясь на всю сущность модели",
                 "consumes": [
                     "application/json"
                 ],
@@ -1288,16 +1421,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Donations"
+                    "CardCompany"
                 ],
-                "summary": "Обновление пожертвования",
+                "summary": "Удаление банковской карты компании по модели",
                 "parameters": [
                     {
-                        "description": "Модель для обновления",
-                        "name": "donation",
+                        "description": "Сущность банковской карты компании",
+                        "name": "card",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.UpdateDonationsRequest"
+                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
                         }
                     }
                 ],
@@ -1305,7 +1438,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateDonationsResponse"
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
                     },
                     "400": {
@@ -1314,6 +1447,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1327,9 +1466,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Создание пожертвования",
+            }
+        },
+        "/api/v1/card/company/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Поиск банковской карты компании по ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1337,24 +1483,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Donations"
+                    "CardCompany"
                 ],
-                "summary": "Создание пожертвования",
+                "summary": "Поиск банковской карты компании",
                 "parameters": [
                     {
-                        "description": "Сущность пожертвования",
-                        "name": "donation",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateDonationsRequest"
-                        }
+                        "type": "integer",
+                        "description": "Card ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Card"
+                            "$ref": "#/definitions/server.CardCompanyResponse"
                         }
                     },
                     "400": {
@@ -1363,6 +1508,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1376,16 +1527,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/donations/deleteModel": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет пожертвование опираясь на всю сущность модели",
+                "description": "Удаление банковской карты компании по ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1393,17 +1542,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Donations"
+                    "CardCompany"
                 ],
-                "summary": "Удаление пожертвования по модели",
+                "summary": "Удаление банковской карты компании",
                 "parameters": [
                     {
-                        "description": "Сущность пожертвования",
-                        "name": "donation",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.DeleteDonationByModelRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID банковской карты компании",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1419,6 +1567,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1434,14 +1588,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/donations/{id}": {
+        "/api/v1/cards": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Поиск пожертвовани по ID",
+                "description": "Массив банковских карт в базе данных",
                 "consumes": [
                     "application/json"
                 ],
@@ -1449,23 +1603,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Donations"
+                    "Cards"
                 ],
-                "summary": "Поиск пожертвования",
+                "summary": "Список всех банковских карт",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Donation ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из заголовка Link",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Сортировка: id, userId, createdAt, updatedAt, \\",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр поле:значение через запятую, поля: userId",
+                        "name": "filter",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateDonationsResponse"
+                            "$ref": "#/definitions/server.CardsResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Количество элементов с учетом фильтра"
+                            }
                         }
                     },
                     "400": {
@@ -1474,6 +1656,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1488,13 +1676,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаление пожертвования по ID",
+                "description": "Создание банковской карты пользователя",
                 "consumes": [
                     "application/json"
                 ],
@@ -1502,23 +1690,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Donations"
+                    "Cards"
                 ],
-                "summary": "Удаление пожертвования",
+                "summary": "Создание банковской карты пользователя",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID пожертвования",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Сущность банковской карты",
+                        "name": "card",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/server.CardRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.CardResponse"
                         }
                     },
                     "400": {
@@ -1527,8 +1716,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -1542,14 +1749,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/donations/{id}/user": {
-            "get": {
+        "/api/v1/cards/deleteModel": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Извлечение пользователя из пожертвования по ID пожертвования",
+                "description": "Удаляет банковскую карту опираясь на всю сущность модели",
                 "consumes": [
                     "application/json"
                 ],
@@ -1557,23 +1764,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Donations"
+                    "Cards"
                 ],
-                "summary": "Извлечение пользователя из пожертвования",
+                "summary": "Удаление банковской карты по модели",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Donation ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Модель банковской карты",
+                        "name": "card",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.Card"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.FindDonationWardsResponse"
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
                     },
                     "400": {
@@ -1582,6 +1790,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1597,14 +1811,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/donations/{id}/wards": {
+        "/api/v1/cards/shared": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Извлечение подопечных пожертвования по ID пожертвования",
+                "description": "Карты, номер которых добавлен нескольким пользователям или компаниям (признак мошенничества).\nКарты сравниваются по отпечатку номера, сам номер не возвращается",
                 "consumes": [
                     "application/json"
                 ],
@@ -1612,33 +1826,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Donations"
-                ],
-                "summary": "Извлечение подопечных пожертвования",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Donation ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Cards"
                 ],
+                "summary": "Карты нескольких владельцев",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.FindDonationWardsResponse"
+                            "$ref": "#/definitions/server.SharedCardsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -1652,14 +1857,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/payment": {
-            "post": {
+        "/api/v1/cards/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Пожертвования",
+                "description": "Поиск банковской карты по ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1667,25 +1872,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payments"
+                    "Cards"
                 ],
-                "summary": "Пожертвования",
+                "summary": "Поиск банковской карты",
                 "parameters": [
                     {
-                        "description": "Данные для оплаты",
-                        "name": "payment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/server.PaymentRequest"
-                        }
+                        "type": "integer",
+                        "description": "Card ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.HTTPError"
+                            "$ref": "#/definitions/server.CardResponse"
                         }
                     },
                     "400": {
@@ -1694,6 +1897,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1707,16 +1916,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/users": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Массив пользователей в базе данных",
+                "description": "Обновление банковской карты",
                 "consumes": [
                     "application/json"
                 ],
@@ -1724,14 +1931,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Cards"
+                ],
+                "summary": "Обновление банковской карты",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID банковской карты",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Модель для обновления",
+                        "name": "card",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateCardRequest"
+                        }
+                    }
                 ],
-                "summary": "Список всех пользователей",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.UsersResponse"
+                            "$ref": "#/definitions/server.CardResponse"
                         }
                     },
                     "400": {
@@ -1740,12 +1965,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1754,13 +1991,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создание новой сущности пользователя",
+                "description": "Удаление банковской карты пользователя по ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1768,24 +2005,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Cards"
                 ],
-                "summary": "Создание пользователя",
+                "summary": "Удаление банковской карты пользователя",
                 "parameters": [
                     {
-                        "description": "Сущность пользователя",
-                        "name": "user",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateUserRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID банковской карты",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateUserResponse"
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
                     },
                     "400": {
@@ -1794,6 +2030,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1809,9 +2051,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/": {
+        "/api/v1/companies": {
             "get": {
-                "description": "Поиск пользователя по его phone",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Массив компаний в базе данных",
                 "consumes": [
                     "application/json"
                 ],
@@ -1819,23 +2066,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Company"
                 ],
-                "summary": "Поиск пользователя по номеру телефона",
+                "summary": "Список всех компаний",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Phone",
-                        "name": "phone",
-                        "in": "query",
-                        "required": true
+                        "description": "Курсор следующей страницы из заголовка Link",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Сортировка: id, title, userId, createdAt, updatedAt, \\",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр поле:значение через запятую, поля: title, phone, inn, userId",
+                        "name": "filter",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateUserResponse"
+                            "$ref": "#/definitions/server.CompaniesResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Количество элементов с учетом фильтра"
+                            }
                         }
                     },
                     "400": {
@@ -1844,6 +2119,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1857,16 +2138,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/users/addCard": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Добавляет банковскую карту пользователю, поле userId это ID пользователя в базе данных, которому будем добавлять карту",
+                "description": "Обновление сущности компании, сменить владельца (userId) может только администратор",
                 "consumes": [
                     "application/json"
                 ],
@@ -1874,16 +2153,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Company"
                 ],
-                "summary": "Добавляет банковскую карту пользователю",
+                "summary": "Обновление компании",
                 "parameters": [
                     {
-                        "description": "Сущность банковской карты",
-                        "name": "card",
+                        "description": "Модель для обновления",
+                        "name": "company",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.AddCardToUserRequest"
+                            "$ref": "#/definitions/DatabaseServicev1.UpdateCompanyRequest"
                         }
                     }
                 ],
@@ -1891,7 +2170,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateUserResponse"
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
                     },
                     "400": {
@@ -1900,6 +2179,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1913,11 +2198,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/users/comparePassword": {
+            },
             "post": {
-                "description": "Сравнивает пароль что ввел пользователь, с тем что есть в базе данных у его аккаунта",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создание новой сущности компании",
                 "consumes": [
                     "application/json"
                 ],
@@ -1925,17 +2213,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Company"
                 ],
-                "summary": "Сравнение вводимого пароля от пользователя",
+                "summary": "Создание компании",
                 "parameters": [
                     {
-                        "description": "Данные пользователя",
-                        "name": "request",
+                        "description": "Сущность компании",
+                        "name": "company",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.ComparePasswordRequest"
+                            "$ref": "#/definitions/DatabaseServicev1.CreateCompanyRequest"
                         }
                     }
                 ],
@@ -1943,7 +2230,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.ComparePasswordResponse"
+                            "$ref": "#/definitions/server.CompanyResponse"
                         }
                     },
                     "400": {
@@ -1952,6 +2239,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1967,14 +2266,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/deleteModel": {
-            "post": {
+        "/api/v1/companies/": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет пользователя опираясь на всю сущность модели",
+                "description": "Поиск компании по ее phone",
                 "consumes": [
                     "application/json"
                 ],
@@ -1982,24 +2281,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Company"
                 ],
-                "summary": "Удаление пользователя по модели",
+                "summary": "Поиск компании по номеру телефона",
                 "parameters": [
                     {
-                        "description": "Модель пользователя",
-                        "name": "user",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.DeleteUserByModelRequest"
-                        }
-                    }
-                ],
-                "responses": {
+                        "type": "string",
+                        "description": "Phone",
+                        "name": "phone",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.CompanyResponse"
                         }
                     },
                     "400": {
@@ -2008,6 +2306,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2023,9 +2333,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/isExists": {
+        "/api/v1/companies/addCard": {
             "post": {
-                "description": "Проверка существует ли пользователь, проверка по номеру телефона",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет банковскую карту компании, поле companyId это ID компании в базе данных, которой будем добавлять карту",
                 "consumes": [
                     "application/json"
                 ],
@@ -2033,17 +2348,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Company"
                 ],
-                "summary": "Проверка существует ли пользователь",
+                "summary": "Добавляет банковскую карту компании",
                 "parameters": [
                     {
-                        "description": "Номер телефона",
-                        "name": "phone",
+                        "description": "Сущность банковской карты",
+                        "name": "card",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.UserIsExistsRequest"
+                            "$ref": "#/definitions/DatabaseServicev1.AddCardToCompanyRequest"
                         }
                     }
                 ],
@@ -2051,7 +2365,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.UserIsExistsResponse"
+                            "$ref": "#/definitions/server.CompanyResponse"
                         }
                     },
                     "400": {
@@ -2060,12 +2374,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2075,9 +2401,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/isRole": {
+        "/api/v1/companies/deleteModel": {
             "post": {
-                "description": "Проверяет пользователя на принадлежность к определенной роли",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет компании опираясь на всю сущность модели",
                 "consumes": [
                     "application/json"
                 ],
@@ -2085,17 +2416,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Company"
                 ],
-                "summary": "Проверяет принадлежность к роли",
+                "summary": "Удаление компании по модели",
                 "parameters": [
                     {
-                        "description": "Request",
-                        "name": "request",
+                        "description": "Модель компании",
+                        "name": "company",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.IsRoleRequest"
+                            "$ref": "#/definitions/DatabaseServicev1.DeleteCompanyByModelRequest"
                         }
                     }
                 ],
@@ -2103,7 +2433,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.IsRoleResponse"
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
                     },
                     "400": {
@@ -2112,6 +2442,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2127,9 +2463,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/{id}": {
+        "/api/v1/companies/{id}": {
             "get": {
-                "description": "Поиск пользователя по ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Поиск компании по ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -2137,13 +2478,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Company"
                 ],
-                "summary": "Поиск пользователя",
+                "summary": "Поиск компании",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Company ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2153,7 +2494,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateUserResponse"
+                            "$ref": "#/definitions/server.CompanyResponse"
                         }
                     },
                     "400": {
@@ -2162,6 +2503,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2175,9 +2522,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Обновление сущности пользователя",
+            }
+        },
+        "/api/v1/companies/{id}/card": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Извлечение банковской карты компании по ее ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -2185,32 +2539,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Company"
                 ],
-                "summary": "Обновление пользователя",
+                "summary": "Извлечение банковской карты компании",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID пользователя",
+                        "description": "Company ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Модель для обновления",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.UpdateUserRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateUserResponse"
+                            "$ref": "#/definitions/server.CardCompanyResponse"
                         }
                     },
                     "400": {
@@ -2219,6 +2564,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2232,9 +2583,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/company/{id}": {
             "delete": {
-                "description": "Удаление пользователя по ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаление компании по ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -2242,13 +2600,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Company"
                 ],
-                "summary": "Удаление пользователя",
+                "summary": "Удаление компании",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID пользователя",
+                        "description": "ID компании",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2267,6 +2625,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2280,45 +2644,63 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Обновление типа пользователя (0 - юридическое лицо, 1 - физическое лицо)",
+            }
+        },
+        "/api/v1/donations": {
+            "get": {
+                "description": "Список всех пожертвований в базе данных",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Donations"
                 ],
-                "summary": "Меняет тип пользователя",
+                "summary": "Список всех пожертвований",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID пользователя",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Type поле пользователя, 0 - юридическое лицо, 1 - физическое лицо",
-                        "name": "type",
-                        "in": "formData",
-                        "required": true
+                        "type": "string",
+                        "description": "Курсор следующей страницы из заголовка Link",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-id",
+                        "description": "Сортировка: id, amount, wardId, userId, createdAt, \\",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр поле:значение через запятую, поля: wardId, userId",
+                        "name": "filter",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.ChangeUserTypeResponse"
+                            "$ref": "#/definitions/server.DonationsResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Количество элементов с учетом фильтра"
+                            }
                         }
                     },
                     "400": {
@@ -2340,16 +2722,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/users/{id}/card": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Извлечение карт пользователя по его ID",
+                "description": "Обновление пожертвования",
                 "consumes": [
                     "application/json"
                 ],
@@ -2357,23 +2737,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Donations"
                 ],
-                "summary": "Извлечение карт пользователя",
+                "summary": "Обновление пожертвования",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Модель для обновления",
+                        "name": "donation",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/server.DonationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.FindUserCardResponse"
+                            "$ref": "#/definitions/server.DonationResponse"
                         }
                     },
                     "400": {
@@ -2382,6 +2763,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2395,16 +2782,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/users/{id}/company": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Извлечение компании пользователя по его ID",
+                "description": "Создание пожертвования",
                 "consumes": [
                     "application/json"
                 ],
@@ -2412,23 +2797,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Donations"
                 ],
-                "summary": "Извлечение компании пользователя",
+                "summary": "Создание пожертвования",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Сущность пожертвования",
+                        "name": "donation",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/server.DonationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Company"
+                            "$ref": "#/definitions/server.DonationResponse"
                         }
                     },
                     "400": {
@@ -2437,6 +2823,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2452,14 +2850,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/{id}/donation": {
-            "get": {
+        "/api/v1/donations/deleteModel": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Извлечение пожертвований пользователя по его ID",
+                "description": "Удаляет пожертвование опираясь на всю сущность модели",
                 "consumes": [
                     "application/json"
                 ],
@@ -2467,23 +2865,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Donations"
                 ],
-                "summary": "Извлечение пожертвований пользователя",
+                "summary": "Удаление пожертвования по модели",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Сущность пожертвования",
+                        "name": "donation",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.DeleteDonationByModelRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.FindUserDonationsResponse"
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
                     },
                     "400": {
@@ -2492,6 +2891,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2507,24 +2912,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/{id}/photo": {
+        "/api/v1/donations/{id}": {
             "get": {
-                "description": "Поиск фото профиля пользователя по ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Поиск пожертвовани по ID",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "image/png",
-                    " image/jpeg"
+                    "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Donations"
                 ],
-                "summary": "Поиск фото профиля пользователя",
+                "summary": "Поиск пожертвования",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Donation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2534,7 +2943,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/server.DonationResponse"
                         }
                     },
                     "400": {
@@ -2543,6 +2952,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2557,37 +2972,30 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Устанавливает фото пользователя по ID",
+                "description": "Удаление пожертвования по ID",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Donations"
                 ],
-                "summary": "Устанавливает фото пользователя",
+                "summary": "Удаление пожертвования",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID пользователя",
+                        "description": "ID пожертвования",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Фото пользователя",
-                        "name": "photo",
-                        "in": "formData",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -2603,6 +3011,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2616,14 +3030,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/donations/{id}/user": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаление фото пользователя по ID",
+                "description": "Извлечение пользователя из пожертвования по ID пожертвования",
                 "consumes": [
                     "application/json"
                 ],
@@ -2631,13 +3047,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Donations"
                 ],
-                "summary": "Удаление фото пользователя",
+                "summary": "Извлечение пользователя из пожертвования",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID пользователя",
+                        "description": "Donation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2647,7 +3063,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.DonationUserResponse"
                         }
                     },
                     "400": {
@@ -2656,6 +3072,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2671,9 +3093,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/wards": {
+        "/api/v1/donations/{id}/wards": {
             "get": {
-                "description": "Список всех подопечных в базе данных",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Извлечение подопечных пожертвования по ID пожертвования",
                 "consumes": [
                     "application/json"
                 ],
@@ -2681,14 +3108,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Wards"
+                    "Donations"
+                ],
+                "summary": "Извлечение подопечных пожертвования",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Donation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Список всех подопечных",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.WardsResponse"
+                            "$ref": "#/definitions/server.DonationWardResponse"
                         }
                     },
                     "400": {
@@ -2697,6 +3133,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2710,14 +3152,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/v1/payment": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновление подопечного",
+                "description": "Списывает сумму с карты пользователя через платежного провайдера и записывает пожертвование подопечному.\nЕсли провайдер подтверждает платеж асинхронно, возвращается 202 с состоянием pending, пожертвование\nзаписывается после уведомления провайдера, состояние платежа можно получить по paymentId",
                 "consumes": [
                     "application/json"
                 ],
@@ -2725,24 +3169,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Wards"
+                    "Payments"
                 ],
-                "summary": "Обновление подопечного",
+                "summary": "Пожертвования",
                 "parameters": [
                     {
-                        "description": "Модель для обновления",
-                        "name": "ward",
+                        "description": "Данные для оплаты",
+                        "name": "payment",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Ward"
+                            "$ref": "#/definitions/server.PaymentRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности, повтор с тем же ключом возвращает ответ на первый запрос",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Ward"
+                            "$ref": "#/definitions/server.PaymentResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/server.PaymentResponse"
                         }
                     },
                     "400": {
@@ -2751,27 +3208,48 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/payment/webhook/{provider}": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Создание подопечного",
+                "description": "Принимает уведомление платежного провайдера о результате асинхронного платежа. Уведомление подписывается\nHMAC-SHA256 секретом webhook_secret в заголовке X-Payment-Signature: \"t=\u003cunix время\u003e,v1=\u003chex подпись\nстроки t.тело\u003e\". Повторная доставка уведомления с тем же id возвращает ответ на первую доставку",
                 "consumes": [
                     "application/json"
                 ],
@@ -2779,16 +3257,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Wards"
+                    "Payments"
                 ],
-                "summary": "Создание подопечного",
+                "summary": "Пожертвования",
                 "parameters": [
                     {
-                        "description": "Сущность подопечного",
-                        "name": "ward",
+                        "type": "string",
+                        "description": "Платежный провайдер",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подпись уведомления",
+                        "name": "X-Payment-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Уведомление",
+                        "name": "event",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateWardRequest"
+                            "$ref": "#/definitions/payment.Event"
                         }
                     }
                 ],
@@ -2796,7 +3289,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Ward"
+                            "$ref": "#/definitions/server.WebhookResponse"
                         }
                     },
                     "400": {
@@ -2805,29 +3298,53 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/wards/deleteModel": {
+        "/api/v1/payment/{donationId}/refund": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет подопечного опираясь на всю сущность модели",
+                "description": "Возвращает на карту всю сумму пожертвования или ее часть и уменьшает собранную сумму подопечного.\nВладелец может вернуть платеж в течение refund_window, администратор - без ограничения срока",
                 "consumes": [
                     "application/json"
                 ],
@@ -2835,24 +3352,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Wards"
+                    "Payments"
                 ],
-                "summary": "Удаление подопечного по модели",
+                "summary": "Пожертвования",
                 "parameters": [
                     {
-                        "description": "Сущность подопечного",
-                        "name": "ward",
+                        "type": "integer",
+                        "description": "ID пожертвования",
+                        "name": "donationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Сумма и причина возврата",
+                        "name": "refund",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Ward"
+                            "$ref": "#/definitions/server.RefundRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности, повтор с тем же ключом возвращает ответ на первый запрос",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.RefundResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Деньги возвращены, возврат будет завершен при восстановлении саги",
+                        "schema": {
+                            "$ref": "#/definitions/server.RefundResponse"
                         }
                     },
                     "400": {
@@ -2861,38 +3397,64 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/wards/{id}": {
+        "/api/v1/payment/{id}": {
             "get": {
-                "description": "Поиск подопечного по ID",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает состояние платежа, доступно владельцу платежа и администратору",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Wards"
+                    "Payments"
                 ],
-                "summary": "Поиск подопечного",
+                "summary": "Пожертвования",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID подопечного",
+                        "type": "string",
+                        "description": "ID платежа",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2902,11 +3464,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.Ward"
+                            "$ref": "#/definitions/server.PaymentResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
@@ -2916,22 +3478,53 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/server.HTTPError"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/server.HTTPError"
-                        }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/subscriptions": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаление подопечного по ID",
+                "description": "Список ежемесячных пожертвований пользователя, администратору - всех пользователей",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscriptions"
+                ],
+                "summary": "Ежемесячные пожертвования",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.SubscriptionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает ежемесячное пожертвование подопечному. Первый платеж выполняется в ближайший день dayOfMonth",
                 "consumes": [
                     "application/json"
                 ],
@@ -2939,23 +3532,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Wards"
+                    "Subscriptions"
                 ],
-                "summary": "Удаление подопечного",
+                "summary": "Ежемесячные пожертвования",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID подопечного",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Подопечный, сумма, день месяца и карта",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.SubscriptionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                            "$ref": "#/definitions/server.SubscriptionResponse"
                         }
                     },
                     "400": {
@@ -2964,6 +3559,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2979,14 +3580,58 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/wards/{id}/donations": {
+        "/api/v1/subscriptions/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Извлечение пожертвований подопечного по его ID",
+                "description": "Ежемесячное пожертвование по ID, доступно владельцу и администратору",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscriptions"
+                ],
+                "summary": "Ежемесячные пожертвования",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.SubscriptionResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет сумму, день месяца или карту ежемесячного пожертвования, приостанавливает или возобновляет его.\nПосле изменения дня или возобновления следующий платеж выполняется в ближайший день dayOfMonth,\nплатежи за время паузы не выполняются",
                 "consumes": [
                     "application/json"
                 ],
@@ -2994,23 +3639,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Wards"
+                    "Subscriptions"
                 ],
-                "summary": "Извлечение пожертвований подопечного",
+                "summary": "Ежемесячные пожертвования",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Ward ID",
+                        "type": "string",
+                        "description": "ID подписки",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля, незаполненные поля не меняются",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.SubscriptionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/DatabaseServicev1.DonationsResponse"
+                            "$ref": "#/definitions/server.SubscriptionResponse"
                         }
                     },
                     "400": {
@@ -3019,6 +3673,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -3032,1076 +3692,3525 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "DatabaseServicev1.AddCardToCompanyRequest": {
-            "type": "object",
-            "properties": {
-                "card": {
-                    "description": "* Банковская карта",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateCardCompanyRequest"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет ежемесячное пожертвование, выполненные платежи не возвращаются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscriptions"
+                ],
+                "summary": "Ежемесячные пожертвования",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
                         }
-                    ]
-                }
-            }
-        },
-        "DatabaseServicev1.AddCardToCompanyResponse": {
-            "type": "object",
-            "properties": {
-                "address": {
-                    "description": "* Адрес офиса компании",
-                    "type": "string"
-                },
-                "card": {
-                    "description": "* Банковская карта компании",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
                         }
-                    ]
-                },
-                "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
-                    "type": "string"
-                },
-                "id": {
-                    "description": "* ID компании в базе данных",
-                    "type": "integer"
-                },
-                "inn": {
-                    "description": "* ИНН юридического лица",
-                    "type": "string"
-                },
-                "kpp": {
-                    "description": "* КПП юридического лица",
-                    "type": "string"
-                },
-                "okpo": {
-                    "description": "* ОКПО предприятия/организации",
-                    "type": "string"
-                },
-                "phone": {
-                    "description": "* Номер телефона компании",
-                    "type": "string"
-                },
-                "site": {
-                    "description": "* Сайт компании",
-                    "type": "string"
-                },
-                "title": {
-                    "description": "* Название компании",
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
-                    "type": "string"
-                },
-                "userId": {
-                    "description": "* ID пользователя к которому относится данная компания",
-                    "type": "integer"
-                }
-            }
-        },
-        "DatabaseServicev1.AddCardToUserRequest": {
-            "type": "object",
-            "properties": {
-                "card": {
-                    "description": "* Банковская карта",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateCardRequest"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
                         }
-                    ]
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
                 }
             }
         },
-        "DatabaseServicev1.Card": {
-            "type": "object",
-            "properties": {
-                "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
-                    "type": "string"
-                },
+        "/api/v1/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Массив пользователей в базе данных",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Список всех пользователей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из заголовка Link",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Сортировка: id, email, username, role, type, createdAt, updatedAt, \\",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр поле:значение через запятую, поля: email, username, phone, role, type",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UsersResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Количество элементов с учетом фильтра"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создание новой сущности пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Создание пользователя",
+                "parameters": [
+                    {
+                        "description": "Сущность пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Поиск пользователя по его phone",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Поиск пользователя по номеру телефона",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Phone",
+                        "name": "phone",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/addCard": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет банковскую карту пользователю, поле userId это ID пользователя в базе данных, которому будем добавлять карту",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Добавляет банковскую карту пользователю",
+                "parameters": [
+                    {
+                        "description": "Сущность банковской карты",
+                        "name": "card",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.AddCardToUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/comparePassword": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сравнивает пароль что ввел пользователь, с тем что есть в базе данных у его аккаунта",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Сравнение вводимого пароля от пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.ComparePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.ComparePasswordResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд разрешена следующая попытка"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/deleteModel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет пользователя опираясь на всю сущность модели",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Удаление пользователя по модели",
+                "parameters": [
+                    {
+                        "description": "Модель пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.DeleteUserByModelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/isExists": {
+            "post": {
+                "description": "Проверка существует ли пользователь, проверка по номеру телефона",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Проверка существует ли пользователь",
+                "parameters": [
+                    {
+                        "description": "Номер телефона",
+                        "name": "phone",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.UserIsExistsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.UserIsExistsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/isRole": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Проверяет пользователя на принадлежность к определенной роли",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Проверяет принадлежность к роли",
+                "parameters": [
+                    {
+                        "description": "Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.IsRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.IsRoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Поиск пользователя по ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Поиск пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновление сущности пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Обновление пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Модель для обновления",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаление пользователя по ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Удаление пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновление типа пользователя (0 - юридическое лицо, 1 - физическое лицо)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Меняет тип пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Type поле пользователя, 0 - юридическое лицо, 1 - физическое лицо",
+                        "name": "type",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.ChangeUserTypeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/card": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Извлечение карт пользователя по его ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Извлечение карт пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.CardsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/company": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Извлечение компании пользователя по его ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Извлечение компании пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.CompanyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/donation": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Извлечение пожертвований пользователя по его ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Извлечение пожертвований пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.DonationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/photo": {
+            "get": {
+                "description": "Поиск фото профиля пользователя по ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "image/png",
+                    " image/jpeg"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Поиск фото профиля пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Устанавливает фото пользователя по ID",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Устанавливает фото пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Фото пользователя",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаление фото пользователя по ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Удаление фото пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vault/reencrypt": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перешифровывает активным ключом (vault.active_key) номера карт и секреты TOTP, зашифрованные\nпредыдущими ключами, и пересчитывает отпечатки карт после замены vault.fingerprint_key.\nПосле успешного выполнения предыдущие ключи можно удалить из конфигурации",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Перешифрование номеров карт",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.VaultReencryptResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wards": {
+            "get": {
+                "description": "Список всех подопечных в базе данных",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wards"
+                ],
+                "summary": "Список всех подопечных",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из заголовка Link",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Сортировка: id, fullName, want, collected, necessary, createdAt, \\",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фильтр поле:значение через запятую, поля: want",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.WardsResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Количество элементов с учетом фильтра"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновление подопечного. Собранная сумма (collected) изменяется только платежами и запросом не перезаписывается. Если указан updatedAt и подопечный с тех пор изменен, возвращается 409",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wards"
+                ],
+                "summary": "Обновление подопечного",
+                "parameters": [
+                    {
+                        "description": "Модель для обновления",
+                        "name": "ward",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/server.WardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.WardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создание подопечного",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wards"
+                ],
+                "summary": "Создание подопечного",
+                "parameters": [
+                    {
+                        "description": "Сущность подопечного",
+                        "name": "ward",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/server.WardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.WardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wards/deleteModel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет подопечного опираясь на всю сущность модели",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wards"
+                ],
+                "summary": "Удаление подопечного по модели",
+                "parameters": [
+                    {
+                        "description": "Сущность подопечного",
+                        "name": "ward",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.Ward"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wards/{id}": {
+            "get": {
+                "description": "Поиск подопечного по ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wards"
+                ],
+                "summary": "Поиск подопечного",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подопечного",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.WardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаление подопечного по ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wards"
+                ],
+                "summary": "Удаление подопечного",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подопечного",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DatabaseServicev1.HTTPCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wards/{id}/donations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Извлечение пожертвований подопечного по его ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wards"
+                ],
+                "summary": "Извлечение пожертвований подопечного",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Ward ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.DonationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "DatabaseServicev1.AddCardToCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "description": "* Банковская карта",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.CreateCardCompanyRequest"
+                        }
+                    ]
+                }
+            }
+        },
+        "DatabaseServicev1.AddCardToUserRequest": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "description": "* Банковская карта",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.CreateCardRequest"
+                        }
+                    ]
+                }
+            }
+        },
+        "DatabaseServicev1.Card": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "* Дата создания сущности в базе данных",
+                    "type": "string"
+                },
+                "cvv": {
+                    "description": "* CVV код карты",
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "* Дата до которой активна карта",
+                    "type": "string"
+                },
+                "fullName": {
+                    "description": "* ФИО с банковской карты",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "* ID банковской карты в базе данных",
+                    "type": "integer"
+                },
+                "number": {
+                    "description": "* Номер карты",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "* Дата последнего обновления сущности в базе данных",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "* ID пользователя которому принадлежит данная карта",
+                    "type": "integer"
+                }
+            }
+        },
+        "DatabaseServicev1.CardCompany": {
+            "type": "object",
+            "properties": {
+                "companyId": {
+                    "description": "* ID компании которой принадлежит данная карта",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "* Дата создания сущности в базе данных",
+                    "type": "string"
+                },
+                "cvv": {
+                    "description": "* CVV код карты",
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "* Дата до которой активна карта",
+                    "type": "string"
+                },
+                "fullName": {
+                    "description": "* ФИО с банковской карты",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "* ID банковской карты в базе данных",
+                    "type": "integer"
+                },
+                "number": {
+                    "description": "* Номер карты",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "* Дата последнего обновления сущности в базе данных",
+                    "type": "string"
+                }
+            }
+        },
+        "DatabaseServicev1.ChangeUserTypeResponse": {
+            "type": "object",
+            "properties": {
+                "accessory": {
+                    "description": "* Успешность операции изменения типа (true/false)",
+                    "type": "boolean"
+                }
+            }
+        },
+        "DatabaseServicev1.Company": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "* Адрес офиса компании",
+                    "type": "string"
+                },
+                "card": {
+                    "description": "* Банковская карта компании",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
+                        }
+                    ]
+                },
+                "createdAt": {
+                    "description": "* Дата создания сущности в базе данных",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "* ID компании в базе данных",
+                    "type": "integer"
+                },
+                "inn": {
+                    "description": "* ИНН юридического лица",
+                    "type": "string"
+                },
+                "kpp": {
+                    "description": "* КПП юридического лица",
+                    "type": "string"
+                },
+                "okpo": {
+                    "description": "* ОКПО предприятия/организации",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "* Номер телефона компании",
+                    "type": "string"
+                },
+                "site": {
+                    "description": "* Сайт компании",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "* Название компании",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "* Дата последнего обновления сущности в базе данных",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "* ID пользователя к которому относится данная компания",
+                    "type": "integer"
+                }
+            }
+        },
+        "DatabaseServicev1.ComparePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "* Пароль который будем сравнивать с тем, что есть в базе данных",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "* Номер телефона пользователя, чей пароль будем искать для сравнения",
+                    "type": "string"
+                }
+            }
+        },
+        "DatabaseServicev1.ComparePasswordResponse": {
+            "type": "object",
+            "properties": {
+                "accessory": {
+                    "description": "* Совпадает ли пароль (true/false)",
+                    "type": "boolean"
+                }
+            }
+        },
+        "DatabaseServicev1.CreateCardCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "companyId": {
+                    "description": "* ID компании которой принадлежит данная карта",
+                    "type": "integer"
+                },
+                "cvv": {
+                    "description": "* CVV код банковской карты",
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "* Дата до которой активна карта",
+                    "type": "string"
+                },
+                "fullName": {
+                    "description": "* ФИО с банковской карты",
+                    "type": "string"
+                },
+                "number": {
+                    "description": "* Номер банковской карты",
+                    "type": "string"
+                }
+            }
+        },
+        "DatabaseServicev1.CreateCardRequest": {
+            "type": "object",
+            "properties": {
+                "cvv": {
+                    "description": "* CVV код карты",
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "* Дата до которой активна карта",
+                    "type": "string"
+                },
+                "fullName": {
+                    "description": "* ФИО с банковской карты",
+                    "type": "string"
+                },
+                "number": {
+                    "description": "* Номер карты",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "* ID пользователя кому принадлежит карта",
+                    "type": "integer"
+                }
+            }
+        },
+        "DatabaseServicev1.CreateCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "* Адрес офиса компании",
+                    "type": "string"
+                },
+                "card": {
+                    "description": "* Банковская карта компании",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
+                        }
+                    ]
+                },
+                "inn": {
+                    "description": "* ИНН юридического лица",
+                    "type": "string"
+                },
+                "kpp": {
+                    "description": "* КПП юридического лица",
+                    "type": "string"
+                },
+                "okpo": {
+                    "description": "* ОКПО предприятия/организации",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "* Номер телефона компании",
+                    "type": "string"
+                },
+                "site": {
+                    "description": "* Сайт компании",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "* Название компании",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "* ID пользователя к которому относится данная компания",
+                    "type": "integer"
+                }
+            }
+        },
+        "DatabaseServicev1.CreateDonationsRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "* Сумма пожертвования",
+                    "type": "number"
+                },
+                "title": {
+                    "description": "* Произвольное название пожертвования, примерно так: \"На лекарства\"",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "* ID пользователя, чье это пожертвование",
+                    "type": "integer"
+                },
+                "wardId": {
+                    "description": "* ID подопечный этого пожертвования",
+                    "type": "integer"
+                }
+            }
+        },
+        "DatabaseServicev1.CreateUserRequest": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DatabaseServicev1.CreateCardRequest"
+                    }
+                },
+                "company": {
+                    "description": "* Компания пользователя, если он является юридическим лицом",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.CreateCompanyRequest"
+                        }
+                    ]
+                },
+                "donations": {
+                    "description": "* Пожертвования пользователя",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DatabaseServicev1.CreateDonationsRequest"
+                    }
+                },
+                "email": {
+                    "description": "* Email пользователя (обязательно проверяем на формат email@email.com)",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "* Пароль пользователя (необходимо дополнительно валидировать корректность пароля)",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "* Номер телефона (необходимо дополнительно валидировать формат)",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "* Роль пользователя",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "* Тип пользователя, 0 - физическое лицо, 1 - юридическое лицо",
+                    "type": "integer"
+                },
+                "username": {
+                    "description": "* Имя (никнейм) пользователя",
+                    "type": "string"
+                }
+            }
+        },
+        "DatabaseServicev1.CreateUserResponse": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "description": "* Банковские карты пользователя",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DatabaseServicev1.Card"
+                    }
+                },
+                "company": {
+                    "description": "* Компания пользователя, если он является юридическим лицом",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.Company"
+                        }
+                    ]
+                },
+                "createdAt": {
+                    "description": "* Дата создания сущности записи в базе данных",
+                    "type": "string"
+                },
+                "donations": {
+                    "description": "* Пожертвования пользователя",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DatabaseServicev1.Donations"
+                    }
+                },
+                "email": {
+                    "description": "* Email пользователя",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "* ID пользователя",
+                    "type": "integer"
+                },
+                "password": {
+                    "description": "* Пароль пользователя (закодирован в MD5Hash)",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "* Номер телефона пользователя",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "* Роль пользователя",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "* Тип пользователя, 0 - физическое лицо, 1 - юридическое лицо",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "description": "* Дата последнего обновления сущности в базе данных",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "* Имя (никнейм) пользователя",
+                    "type": "string"
+                }
+            }
+        },
+        "DatabaseServicev1.DeleteCompanyByModelRequest": {
+            "type": "object",
+            "properties": {
+                "company": {
+                    "description": "* Полная сущность модели для удаления",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.Company"
+                        }
+                    ]
+                }
+            }
+        },
+        "DatabaseServicev1.DeleteDonationByModelRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "* Сумма пожертвования",
+                    "type": "number"
+                },
+                "createdAt": {
+                    "description": "* Дата создания сущности в базе данных",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "* ID пожертвования в базе данных",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "* Название пожертвования (например \"На лекарства\")",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "* Дата последнего обновления сущности в базе данных",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "* ID пользователя, которому принадлежит пожертвование",
+                    "type": "integer"
+                },
+                "wardId": {
+                    "description": "* ID подопечный этого пожертвования",
+                    "type": "integer"
+                }
+            }
+        },
+        "DatabaseServicev1.DeleteUserByModelRequest": {
+            "type": "object",
+            "properties": {
+                "user": {
+                    "description": "* Сущность пользователя для удаления (полная модель пользователя)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.CreateUserResponse"
+                        }
+                    ]
+                }
+            }
+        },
+        "DatabaseServicev1.Donations": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "* Сумма пожертвования",
+                    "type": "number"
+                },
+                "createdAt": {
+                    "description": "* Дата создания сущности в базе данных",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "* ID пожертвования в базе данных",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "* Название пожертвования (например \"На лекарства\")",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "* Дата последнего обновления сущности в базе данных",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "* ID пользователя, которому принадлежит пожертвование",
+                    "type": "integer"
+                },
+                "wardId": {
+                    "description": "* ID подопечного для которого предназначено данное пожертвование",
+                    "type": "integer"
+                }
+            }
+        },
+        "DatabaseServicev1.HTTPCodes": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "* HTTP код ответа",
+                    "type": "integer"
+                }
+            }
+        },
+        "DatabaseServicev1.IsRoleRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "* ID пользователя",
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "* Роль пользователя которую мы ожиданием",
+                    "type": "string"
+                }
+            }
+        },
+        "DatabaseServicev1.IsRoleResponse": {
+            "type": "object",
+            "properties": {
+                "accessory": {
+                    "description": "* Принадлежность к роли (true/false)",
+                    "type": "boolean"
+                }
+            }
+        },
+        "DatabaseServicev1.UpdateCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "company": {
+                    "description": "* Компания которую обновляем",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.Company"
+                        }
+                    ]
+                }
+            }
+        },
+        "DatabaseServicev1.UpdateUserRequest": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "description": "* Массив банковских карт пользователя",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DatabaseServicev1.Card"
+                    }
+                },
+                "company": {
+                    "description": "* Компания пользователя, если он является юридическим лицом (type = 1)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/DatabaseServicev1.Company"
+                        }
+                    ]
+                },
+                "createdAt": {
+                    "description": "* Дата создания сущности в базе данных",
+                    "type": "string"
+                },
+                "donations": {
+                    "description": "* Массив пожертвований пользователя",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DatabaseServicev1.Donations"
+                    }
+                },
+                "email": {
+                    "description": "* Email пользователя",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "* ID пользователя в базе данных",
+                    "type": "integer"
+                },
+                "password": {
+                    "description": "* Пароль пользователя (закодирован в MD5Hash)",
+                    "type": "string"
+                },
+                "phone": {
+                    "description": "* Номер телефона пользователя",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "* Роль пользователя",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "* Тип пользователя (0 - физическое лицо, 1 - юридическое лицо)",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "description": "* Дата последнего обновления сущности в базе данных",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "* Имя (никнейм) пользователя",
+                    "type": "string"
+                }
+            }
+        },
+        "DatabaseServicev1.UserIsExistsRequest": {
+            "type": "object",
+            "properties": {
+                "phone": {
+                    "description": "* Номер телефона пользователя, по которому будем искать сущность",
+                    "type": "string"
+                }
+            }
+        },
+        "DatabaseServicev1.UserIsExistsResponse": {
+            "type": "object",
+            "properties": {
+                "isExists": {
+                    "description": "* Существует ли пользователь в базе данных (true/false)",
+                    "type": "boolean"
+                }
+            }
+        },
+        "DatabaseServicev1.Ward": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "* Адрес подопечного",
+                    "type": "string"
+                },
+                "collected": {
+                    "description": "* Количество собранных средств",
+                    "type": "number"
+                },
+                "createdAt": {
+                    "description": "* Дата создания сущности в базе данных",
+                    "type": "string"
+                },
+                "donations": {
+                    "description": "* пожертвования для подопечного",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DatabaseServicev1.Donations"
+                    }
+                },
+                "fullName": {
+                    "description": "* Полное имя подопечного",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "* ID подопечного в базе данных",
+                    "type": "integer"
+                },
+                "necessary": {
+                    "description": "* Необходимая сумма денег на потребность",
+                    "type": "number"
+                },
+                "title": {
+                    "description": "* Дополнительный текст к подопечному",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "* Дата последнего обновления сущности в базе данных",
+                    "type": "string"
+                },
+                "want": {
+                    "description": "* Потребность подопечного (то в чем он нуждается, например \"Лекарства\")",
+                    "type": "string"
+                }
+            }
+        },
+        "payment.Event": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Уникальный ID уведомления, повторная доставка приходит с тем же ID",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Причина отказа для payment.failed",
+                    "type": "string"
+                },
+                "transactionId": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/payment.EventType"
+                }
+            }
+        },
+        "payment.EventType": {
+            "type": "string",
+            "enum": [
+                "payment.succeeded",
+                "payment.failed"
+            ],
+            "x-enum-comments": {
+                "EventFailed": "Платеж отклонен",
+                "EventSucceeded": "Средства списаны"
+            },
+            "x-enum-varnames": [
+                "EventSucceeded",
+                "EventFailed"
+            ]
+        },
+        "server.ApiKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expiresIn": {
+                    "description": "Срок действия (например, 720h), по умолчанию api_keys.default_ttl",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Области доступа, например [\"wards:write\", \"donations:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "server.ApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "description": "Ключ для заголовка Authorization, повторно не показывается",
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "server.ApiKeysResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.ApiKeyResponse"
+                    }
+                }
+            }
+        },
+        "server.CardCompaniesResponse": {
+            "type": "object",
+            "properties": {
+                "cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.CardCompanyResponse"
+                    }
+                }
+            }
+        },
+        "server.CardCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "companyId": {
+                    "type": "integer"
+                },
+                "cvv": {
+                    "type": "string",
+                    "example": "012"
+                },
+                "date": {
+                    "description": "Срок действия MM/YY",
+                    "type": "string",
+                    "example": "12/30"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string",
+                    "example": "4111111111111111"
+                }
+            }
+        },
+        "server.CardCompanyResponse": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "description": "Платежная система",
+                    "type": "string",
+                    "enum": [
+                        "mir",
+                        "visa",
+                        "mastercard",
+                        "unionpay"
+                    ],
+                    "example": "visa"
+                },
+                "companyId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last4": {
+                    "type": "string",
+                    "example": "1111"
+                },
+                "number": {
+                    "description": "Маскированный номер карты",
+                    "type": "string",
+                    "example": "************1111"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.CardOwnerResponse": {
+            "type": "object",
+            "properties": {
+                "cardId": {
+                    "description": "ID карты в DatabaseService",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "ID пользователя или компании",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "company"
+                    ]
+                }
+            }
+        },
+        "server.CardRequest": {
+            "type": "object",
+            "properties": {
                 "cvv": {
-                    "description": "* CVV код карты",
-                    "type": "integer"
+                    "type": "string",
+                    "example": "012"
                 },
                 "date": {
-                    "description": "* Дата до которой активна карта",
-                    "type": "string"
+                    "description": "Срок действия MM/YY",
+                    "type": "string",
+                    "example": "12/30"
                 },
                 "fullName": {
-                    "description": "* ФИО с банковской карты",
                     "type": "string"
                 },
-                "id": {
-                    "description": "* ID банковской карты в базе данных",
-                    "type": "integer"
-                },
                 "number": {
-                    "description": "* Номер карты",
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
-                    "type": "string"
+                    "type": "string",
+                    "example": "4111111111111111"
                 },
                 "userId": {
-                    "description": "* ID пользователя которому принадлежит данная карта",
                     "type": "integer"
                 }
             }
         },
-        "DatabaseServicev1.CardCompany": {
+        "server.CardResponse": {
             "type": "object",
             "properties": {
-                "companyId": {
-                    "description": "* ID компании которой принадлежит данная карта",
-                    "type": "integer"
+                "brand": {
+                    "description": "Платежная система",
+                    "type": "string",
+                    "enum": [
+                        "mir",
+                        "visa",
+                        "mastercard",
+                        "unionpay"
+                    ],
+                    "example": "visa"
                 },
                 "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
                     "type": "string"
                 },
-                "cvv": {
-                    "description": "* CVV код карты",
-                    "type": "integer"
-                },
                 "date": {
-                    "description": "* Дата до которой активна карта",
                     "type": "string"
                 },
                 "fullName": {
-                    "description": "* ФИО с банковской карты",
                     "type": "string"
                 },
                 "id": {
-                    "description": "* ID банковской карты в базе данных",
                     "type": "integer"
                 },
+                "last4": {
+                    "type": "string",
+                    "example": "1111"
+                },
                 "number": {
-                    "description": "* Номер карты",
-                    "type": "string"
+                    "description": "Маскированный номер карты",
+                    "type": "string",
+                    "example": "************1111"
                 },
                 "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
                     "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
                 }
             }
         },
-        "DatabaseServicev1.CardsCompaniesResponse": {
-            "type": "object",
-            "properties": {
-                "cards": {
-                    "description": "* Массив банковских карт компаний",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.CardCompany"
-                    }
-                }
-            }
-        },
-        "DatabaseServicev1.CardsResponse": {
+        "server.CardsResponse": {
             "type": "object",
             "properties": {
                 "cards": {
-                    "description": "* Массив банковских карт пользователя",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Card"
+                        "$ref": "#/definitions/server.CardResponse"
                     }
                 }
             }
         },
-        "DatabaseServicev1.ChangeUserTypeResponse": {
+        "server.CodeSentResponse": {
             "type": "object",
             "properties": {
-                "accessory": {
-                    "description": "* Успешность операции изменения типа (true/false)",
-                    "type": "boolean"
+                "expiresIn": {
+                    "description": "Срок действия кода в секундах",
+                    "type": "integer"
                 }
             }
         },
-        "DatabaseServicev1.CompaniesResponse": {
+        "server.CompaniesResponse": {
             "type": "object",
             "properties": {
                 "companies": {
-                    "description": "* Массив компаний",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Company"
+                        "$ref": "#/definitions/server.CompanyResponse"
                     }
                 }
             }
         },
-        "DatabaseServicev1.Company": {
+        "server.CompanyResponse": {
             "type": "object",
             "properties": {
                 "address": {
-                    "description": "* Адрес офиса компании",
                     "type": "string"
                 },
                 "card": {
-                    "description": "* Банковская карта компании",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
-                        }
-                    ]
+                    "$ref": "#/definitions/server.CardCompanyResponse"
                 },
                 "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
                     "type": "string"
                 },
                 "id": {
-                    "description": "* ID компании в базе данных",
                     "type": "integer"
                 },
                 "inn": {
-                    "description": "* ИНН юридического лица",
                     "type": "string"
                 },
                 "kpp": {
-                    "description": "* КПП юридического лица",
                     "type": "string"
                 },
                 "okpo": {
-                    "description": "* ОКПО предприятия/организации",
                     "type": "string"
                 },
                 "phone": {
-                    "description": "* Номер телефона компании",
                     "type": "string"
                 },
                 "site": {
-                    "description": "* Сайт компании",
                     "type": "string"
                 },
                 "title": {
-                    "description": "* Название компании",
                     "type": "string"
                 },
                 "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
                     "type": "string"
                 },
                 "userId": {
-                    "description": "* ID пользователя к которому относится данная компания",
                     "type": "integer"
                 }
             }
         },
-        "DatabaseServicev1.ComparePasswordRequest": {
+        "server.DonationRefund": {
             "type": "object",
             "properties": {
-                "password": {
-                    "description": "* Пароль который будем сравнивать с тем, что есть в базе данных",
-                    "type": "string"
+                "refunded": {
+                    "description": "Возвращенная сумма",
+                    "type": "string",
+                    "example": "100.50"
                 },
-                "phone": {
-                    "description": "* Номер телефона пользователя, чей пароль будем искать для сравнения",
+                "refunds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.RefundResponse"
+                    }
+                },
+                "status": {
+                    "description": "pending - возврат выполняется, partial - возвращена часть, refunded - вся сумма, failed - возвраты не выполнены",
                     "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.ComparePasswordResponse": {
-            "type": "object",
-            "properties": {
-                "accessory": {
-                    "description": "* Совпадает ли пароль (true/false)",
-                    "type": "boolean"
-                }
-            }
-        },
-        "DatabaseServicev1.CreateCardCompanyRequest": {
+        "server.DonationRequest": {
             "type": "object",
             "properties": {
-                "companyId": {
-                    "description": "* ID компании которой принадлежит данная карта",
-                    "type": "integer"
+                "amount": {
+                    "type": "string",
+                    "example": "100.50"
                 },
-                "cvv": {
-                    "description": "* CVV код банковской карты",
+                "id": {
+                    "description": "Только при обновлении",
                     "type": "integer"
                 },
-                "date": {
-                    "description": "* Дата до которой активна карта",
+                "title": {
                     "type": "string"
                 },
-                "fullName": {
-                    "description": "* ФИО с банковской карты",
-                    "type": "string"
+                "userId": {
+                    "type": "integer"
                 },
-                "number": {
-                    "description": "* Номер банковской карты",
-                    "type": "string"
+                "wardId": {
+                    "type": "integer"
                 }
             }
         },
-        "DatabaseServicev1.CreateCardRequest": {
+        "server.DonationResponse": {
             "type": "object",
             "properties": {
-                "cvv": {
-                    "description": "* CVV код карты",
-                    "type": "integer"
-                },
-                "date": {
-                    "description": "* Дата до которой активна карта",
-                    "type": "string"
+                "amount": {
+                    "type": "string",
+                    "example": "100.50"
                 },
-                "fullName": {
-                    "description": "* ФИО с банковской карты",
+                "createdAt": {
                     "type": "string"
                 },
-                "number": {
-                    "description": "* Номер карты",
-                    "type": "string"
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
                 },
-                "userId": {
-                    "description": "* ID пользователя кому принадлежит карта",
+                "id": {
                     "type": "integer"
-                }
-            }
-        },
-        "DatabaseServicev1.CreateCompanyRequest": {
-            "type": "object",
-            "properties": {
-                "address": {
-                    "description": "* Адрес офиса компании",
-                    "type": "string"
                 },
-                "card": {
-                    "description": "* Банковская карта компании",
+                "refund": {
+                    "description": "Только для пожертвований, по которым запрашивался возврат",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/DatabaseServicev1.CardCompany"
+                            "$ref": "#/definitions/server.DonationRefund"
                         }
                     ]
                 },
-                "inn": {
-                    "description": "* ИНН юридического лица",
-                    "type": "string"
-                },
-                "kpp": {
-                    "description": "* КПП юридического лица",
-                    "type": "string"
-                },
-                "okpo": {
-                    "description": "* ОКПО предприятия/организации",
-                    "type": "string"
-                },
-                "phone": {
-                    "description": "* Номер телефона компании",
-                    "type": "string"
-                },
-                "site": {
-                    "description": "* Сайт компании",
+                "title": {
                     "type": "string"
                 },
-                "title": {
-                    "description": "* Название компании",
+                "updatedAt": {
                     "type": "string"
                 },
                 "userId": {
-                    "description": "* ID пользователя к которому относится данная компания",
+                    "type": "integer"
+                },
+                "wardId": {
                     "type": "integer"
                 }
             }
         },
-        "DatabaseServicev1.CreateDonationsRequest": {
+        "server.DonationUserResponse": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "description": "* Сумма пожертвования",
-                    "type": "number"
-                },
-                "title": {
-                    "description": "* Произвольное название пожертвования, примерно так: \"На лекарства\"",
-                    "type": "string"
-                },
-                "userId": {
-                    "description": "* ID пользователя, чье это пожертвование",
-                    "type": "integer"
-                },
-                "wardId": {
-                    "description": "* ID подопечный этого пожертвования",
-                    "type": "integer"
+                "user": {
+                    "$ref": "#/definitions/server.UserResponse"
                 }
             }
         },
-        "DatabaseServicev1.CreateDonationsResponse": {
+        "server.DonationWardResponse": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "description": "* Сумма пожертвования",
-                    "type": "number"
-                },
-                "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
-                    "type": "string"
-                },
-                "id": {
-                    "description": "* ID записи в базе данных",
-                    "type": "integer"
-                },
-                "title": {
-                    "description": "* Название пожертвования (например \"На лекарства\")",
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
-                    "type": "string"
-                },
-                "userId": {
-                    "description": "* ID пользователя, которому принадлежит пожертвование",
-                    "type": "integer"
-                },
-                "wardId": {
-                    "description": "* ID подопечный этого пожертвования",
-                    "type": "integer"
+                "wards": {
+                    "$ref": "#/definitions/server.WardResponse"
                 }
             }
         },
-        "DatabaseServicev1.CreateUserRequest": {
+        "server.DonationsResponse": {
             "type": "object",
             "properties": {
-                "card": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.CreateCardRequest"
-                    }
-                },
-                "company": {
-                    "description": "* Компания пользователя, если он является юридическим лицом",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateCompanyRequest"
-                        }
-                    ]
-                },
                 "donations": {
-                    "description": "* Пожертвования пользователя",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.CreateDonationsRequest"
+                        "$ref": "#/definitions/server.DonationResponse"
                     }
+                }
+            }
+        },
+        "server.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "number"
                 },
+                "message": {
+                    "type": "string",
+                    "example": "неверный номер карты"
+                }
+            }
+        },
+        "server.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
                 "email": {
-                    "description": "* Email пользователя (обязательно проверяем на формат email@email.com)",
                     "type": "string"
                 },
-                "password": {
-                    "description": "* Пароль пользователя (необходимо дополнительно валидировать корректность пароля)",
-                    "type": "string"
-                },
-                "phone": {
-                    "description": "* Номер телефона (необходимо дополнительно валидировать формат)",
-                    "type": "string"
-                },
-                "role": {
-                    "description": "* Роль пользователя",
-                    "type": "string"
-                },
-                "type": {
-                    "description": "* Тип пользователя, 0 - физическое лицо, 1 - юридическое лицо",
-                    "type": "integer"
-                },
-                "username": {
-                    "description": "* Имя (никнейм) пользователя",
+                "phone": {
                     "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.CreateUserResponse": {
+        "server.HTTPError": {
             "type": "object",
             "properties": {
-                "card": {
-                    "description": "* Банковские карты пользователя",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Card"
-                    }
-                },
-                "company": {
-                    "description": "* Компания пользователя, если он является юридическим лицом",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.Company"
-                        }
-                    ]
-                },
-                "createdAt": {
-                    "description": "* Дата создания сущности записи в базе данных",
-                    "type": "string"
+                "code": {
+                    "type": "integer"
                 },
-                "donations": {
-                    "description": "* Пожертвования пользователя",
+                "details": {
+                    "description": "Ошибки в отдельных полях запроса",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Donations"
+                        "$ref": "#/definitions/server.FieldError"
                     }
                 },
-                "email": {
-                    "description": "* Email пользователя",
+                "message": {
                     "type": "string"
-                },
-                "id": {
-                    "description": "* ID пользователя",
-                    "type": "integer"
-                },
+                }
+            }
+        },
+        "server.LoginRequest": {
+            "type": "object",
+            "properties": {
                 "password": {
-                    "description": "* Пароль пользователя (закодирован в MD5Hash)",
                     "type": "string"
                 },
                 "phone": {
-                    "description": "* Номер телефона пользователя",
                     "type": "string"
-                },
-                "role": {
-                    "description": "* Роль пользователя",
+                }
+            }
+        },
+        "server.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
                     "type": "string"
                 },
-                "type": {
-                    "description": "* Тип пользователя, 0 - физическое лицо, 1 - юридическое лицо",
-                    "type": "integer"
-                },
-                "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
+                "token": {
                     "type": "string"
-                },
-                "username": {
-                    "description": "* Имя (никнейм) пользователя",
+                }
+            }
+        },
+        "server.MfaCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.CreateWardRequest": {
+        "server.MfaLoginRequest": {
             "type": "object",
             "properties": {
-                "address": {
-                    "description": "* Адрес подопечного",
+                "code": {
                     "type": "string"
                 },
-                "fullName": {
-                    "description": "* Полное имя подопечного",
+                "mfaToken": {
                     "type": "string"
-                },
-                "necessary": {
-                    "description": "* Необходимая сумма денег на необходимость",
-                    "type": "number"
-                },
-                "title": {
-                    "description": "* Дополнительный текст к подопечному",
+                }
+            }
+        },
+        "server.MfaSetupResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
                     "type": "string"
                 },
-                "want": {
-                    "description": "* Необходимость подопечного (то в чем он нуждается, например \"Лекарства\")",
+                "uri": {
+                    "description": "URI otpauth:// для QR-кода",
                     "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.DeleteCompanyByModelRequest": {
+        "server.MfaStatusResponse": {
             "type": "object",
             "properties": {
-                "company": {
-                    "description": "* Полная сущность модели для удаления",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.Company"
-                        }
-                    ]
+                "enabled": {
+                    "type": "boolean"
                 }
             }
         },
-        "DatabaseServicev1.DeleteDonationByModelRequest": {
+        "server.OtpRequest": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "description": "* Сумма пожертвования",
-                    "type": "number"
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.OtpVerifyRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
                 },
-                "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
+                "phone": {
                     "type": "string"
+                }
+            }
+        },
+        "server.PaymentRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Не более знаков после точки, чем у валюты платежа, строкой или числом",
+                    "type": "string",
+                    "example": "100.50"
                 },
-                "id": {
-                    "description": "* ID пожертвования в базе данных",
+                "cardId": {
+                    "description": "Карта пользователя для оплаты, можно не указывать, если карта одна",
                     "type": "integer"
                 },
-                "title": {
-                    "description": "* Название пожертвования (например \"На лекарства\")",
+                "cvv": {
+                    "description": "Используется только для авторизации этого платежа и не сохраняется",
                     "type": "string"
                 },
-                "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
+                "description": {
                     "type": "string"
                 },
-                "userId": {
-                    "description": "* ID пользователя, которому принадлежит пожертвование",
+                "toWardId": {
                     "type": "integer"
+                }
+            }
+        },
+        "server.PaymentResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "100.50"
                 },
-                "wardId": {
-                    "description": "* ID подопечный этого пожертвования",
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "donationId": {
                     "type": "integer"
+                },
+                "failureReason": {
+                    "type": "string"
+                },
+                "paymentId": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending, processing, succeeded или failed",
+                    "type": "string"
+                },
+                "transactionId": {
+                    "description": "ID транзакции у платежного провайдера",
+                    "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.DeleteUserByModelRequest": {
+        "server.RefreshRequest": {
             "type": "object",
             "properties": {
-                "user": {
-                    "description": "* Сущность пользователя для удаления (полная модель пользователя)",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.CreateUserResponse"
-                        }
-                    ]
+                "refreshToken": {
+                    "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.Donations": {
+        "server.RefundRequest": {
             "type": "object",
             "properties": {
                 "amount": {
-                    "description": "* Сумма пожертвования",
-                    "type": "number"
+                    "description": "Сумма возврата, 0 - весь остаток платежа",
+                    "type": "string",
+                    "example": "100.50"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.RefundResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "100.50"
                 },
                 "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
                     "type": "string"
                 },
-                "id": {
-                    "description": "* ID пожертвования в базе данных",
+                "donationId": {
                     "type": "integer"
                 },
-                "title": {
-                    "description": "* Название пожертвования (например \"На лекарства\")",
+                "failureReason": {
                     "type": "string"
                 },
-                "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
+                "id": {
                     "type": "string"
                 },
-                "userId": {
-                    "description": "* ID пользователя, которому принадлежит пожертвование",
-                    "type": "integer"
+                "paymentId": {
+                    "type": "string"
                 },
-                "wardId": {
-                    "description": "* ID подопечного для которого предназначено данное пожертвование",
-                    "type": "integer"
-                }
-            }
-        },
-        "DatabaseServicev1.DonationsResponse": {
-            "type": "object",
-            "properties": {
-                "donations": {
-                    "description": "* Массив пожертвований",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Donations"
-                    }
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending, succeeded или failed",
+                    "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.FindDonationWardsResponse": {
+        "server.RegistrationRequest": {
             "type": "object",
             "properties": {
-                "wards": {
-                    "description": "* Подопечный пожертвования",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.Ward"
-                        }
-                    ]
+                "card": {
+                    "$ref": "#/definitions/DatabaseServicev1.CreateCardRequest"
+                },
+                "company": {
+                    "$ref": "#/definitions/DatabaseServicev1.CreateCompanyRequest"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.FindUserCardResponse": {
+        "server.ResetPasswordRequest": {
             "type": "object",
             "properties": {
-                "cards": {
-                    "description": "* Массив банковских карт",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Card"
-                    }
+                "code": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.FindUserDonationsResponse": {
+        "server.SessionResponse": {
             "type": "object",
             "properties": {
-                "donations": {
-                    "description": "* Массив пожертвований пользователя",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Donations"
-                    }
+                "createdAt": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Сессия, в рамках которой выполнен запрос",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userAgent": {
+                    "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.HTTPCodes": {
+        "server.SessionsResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "* HTTP код ответа",
-                    "type": "integer"
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.SessionResponse"
+                    }
                 }
             }
         },
-        "DatabaseServicev1.IsRoleRequest": {
+        "server.SharedCardResponse": {
             "type": "object",
             "properties": {
-                "id": {
-                    "description": "* ID пользователя",
-                    "type": "integer"
+                "brand": {
+                    "type": "string",
+                    "example": "visa"
                 },
-                "role": {
-                    "description": "* Роль пользователя которую мы ожиданием",
+                "fingerprint": {
+                    "description": "Отпечаток номера карты (HMAC-SHA256)",
                     "type": "string"
+                },
+                "last4": {
+                    "type": "string",
+                    "example": "1111"
+                },
+                "owners": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.CardOwnerResponse"
+                    }
                 }
             }
         },
-        "DatabaseServicev1.IsRoleResponse": {
+        "server.SharedCardsResponse": {
             "type": "object",
             "properties": {
-                "accessory": {
-                    "description": "* Принадлежность к роли (true/false)",
-                    "type": "boolean"
+                "cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.SharedCardResponse"
+                    }
                 }
             }
         },
-        "DatabaseServicev1.UpdateCompanyRequest": {
+        "server.SubscriptionRequest": {
             "type": "object",
             "properties": {
-                "company": {
-                    "description": "* Компания которую обновляем",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.Company"
-                        }
-                    ]
+                "amount": {
+                    "type": "string",
+                    "example": "100.50"
+                },
+                "cardId": {
+                    "description": "Карта пользователя, можно не указывать, если карта одна",
+                    "type": "integer"
+                },
+                "dayOfMonth": {
+                    "description": "1-31, в коротких месяцах платеж выполняется в последний день",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Только при изменении: active - возобновить, paused - приостановить",
+                    "type": "string"
+                },
+                "wardId": {
+                    "description": "Только при создании",
+                    "type": "integer"
                 }
             }
         },
-        "DatabaseServicev1.UpdateDonationsRequest": {
+        "server.SubscriptionResponse": {
             "type": "object",
             "properties": {
                 "amount": {
-                    "description": "* Сумма пожертвования",
-                    "type": "number"
+                    "type": "string",
+                    "example": "100.50"
+                },
+                "cardId": {
+                    "type": "integer"
                 },
                 "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
                     "type": "string"
                 },
-                "id": {
-                    "description": "* ID пожертвования в базе данных",
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "dayOfMonth": {
                     "type": "integer"
                 },
-                "title": {
-                    "description": "* Название пожертвования (например \"На лекарства\")",
+                "id": {
                     "type": "string"
                 },
-                "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
+                "lastError": {
+                    "type": "string"
+                },
+                "lastPaymentId": {
+                    "type": "string"
+                },
+                "lastRunAt": {
+                    "type": "string"
+                },
+                "nextRun": {
+                    "description": "Плановое время следующего платежа",
+                    "type": "string"
+                },
+                "pauseReason": {
+                    "type": "string"
+                },
+                "retryAt": {
+                    "description": "Время повтора после отказа",
+                    "type": "string"
+                },
+                "status": {
                     "type": "string"
                 },
                 "userId": {
-                    "description": "* ID пользователя, которому принадлежит пожертвование",
                     "type": "integer"
                 },
                 "wardId": {
-                    "description": "* ID подопечный этого пожертвования",
                     "type": "integer"
                 }
             }
         },
-        "DatabaseServicev1.UpdateUserCardRequest1": {
+        "server.SubscriptionsResponse": {
             "type": "object",
             "properties": {
-                "cvv": {
-                    "description": "* CVV код карты",
-                    "type": "integer"
-                },
-                "date": {
-                    "description": "* Дата до которой активна карта",
-                    "type": "string"
-                },
-                "fullName": {
-                    "description": "* ФИО с банковской карты",
-                    "type": "string"
-                },
-                "number": {
-                    "description": "* Номер карты",
-                    "type": "string"
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.SubscriptionResponse"
+                    }
                 }
             }
         },
-        "DatabaseServicev1.UpdateUserCardResponse": {
+        "server.UpdateCardRequest": {
             "type": "object",
             "properties": {
-                "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
-                    "type": "string"
-                },
                 "cvv": {
-                    "description": "* CVV код карты",
-                    "type": "integer"
+                    "type": "string",
+                    "example": "012"
                 },
                 "date": {
-                    "description": "* Дата до которой активна карта",
-                    "type": "string"
+                    "description": "Срок действия MM/YY",
+                    "type": "string",
+                    "example": "12/30"
                 },
                 "fullName": {
-                    "description": "* ФИО с банковской карты",
                     "type": "string"
                 },
-                "id": {
-                    "description": "* ID банковской карты в базе данных",
-                    "type": "integer"
-                },
                 "number": {
-                    "description": "* Номер карты",
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
-                    "type": "string"
-                },
-                "userId": {
-                    "description": "* ID пользователя которому принадлежит данная карта",
-                    "type": "integer"
+                    "type": "string",
+                    "example": "4111111111111111"
                 }
             }
         },
-        "DatabaseServicev1.UpdateUserRequest": {
+        "server.UserResponse": {
             "type": "object",
             "properties": {
                 "card": {
-                    "description": "* Массив банковских карт пользователя",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Card"
+                        "$ref": "#/definitions/server.CardResponse"
                     }
                 },
                 "company": {
-                    "description": "* Компания пользователя, если он является юридическим лицом (type = 1)",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/DatabaseServicev1.Company"
-                        }
-                    ]
+                    "$ref": "#/definitions/server.CompanyResponse"
                 },
                 "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
                     "type": "string"
                 },
                 "donations": {
-                    "description": "* Массив пожертвований пользователя",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Donations"
+                        "$ref": "#/definitions/server.DonationResponse"
                     }
                 },
                 "email": {
-                    "description": "* Email пользователя",
                     "type": "string"
                 },
                 "id": {
-                    "description": "* ID пользователя в базе данных",
                     "type": "integer"
                 },
-                "password": {
-                    "description": "* Пароль пользователя (закодирован в MD5Hash)",
-                    "type": "string"
-                },
                 "phone": {
-                    "description": "* Номер телефона пользователя",
                     "type": "string"
                 },
                 "role": {
-                    "description": "* Роль пользователя",
                     "type": "string"
                 },
                 "type": {
-                    "description": "* Тип пользователя (0 - физическое лицо, 1 - юридическое лицо)",
+                    "description": "0 - физическое лицо, 1 - юридическое лицо",
                     "type": "integer"
                 },
                 "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
                     "type": "string"
                 },
                 "username": {
-                    "description": "* Имя (никнейм) пользователя",
                     "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.UserIsExistsRequest": {
+        "server.UsersResponse": {
             "type": "object",
             "properties": {
-                "phone": {
-                    "description": "* Номер телефона пользователя, по которому будем искать сущность",
-                    "type": "string"
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.UserResponse"
+                    }
                 }
             }
         },
-        "DatabaseServicev1.UserIsExistsResponse": {
+        "server.VaultReencryptResponse": {
             "type": "object",
             "properties": {
-                "isExists": {
-                    "description": "* Существует ли пользователь в базе данных (true/false)",
+                "mfaSecrets": {
+                    "description": "Количество секретов TOTP, перешифрованных активным ключом",
+                    "type": "integer"
+                },
+                "reencrypted": {
+                    "description": "Количество номеров, перешифрованных активным ключом или с новым отпечатком",
+                    "type": "integer"
+                }
+            }
+        },
+        "server.VerificationResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Текущий email подтвержден",
+                    "type": "boolean"
+                },
+                "phone": {
+                    "description": "Текущий телефон подтвержден",
                     "type": "boolean"
                 }
             }
         },
-        "DatabaseServicev1.UsersResponse": {
+        "server.VerifyRequest": {
             "type": "object",
             "properties": {
-                "users": {
-                    "description": "* Массив пользователей",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.CreateUserResponse"
-                    }
+                "code": {
+                    "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.Ward": {
+        "server.WardRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Только при обновлении",
+                    "type": "integer"
+                },
+                "necessary": {
+                    "type": "string",
+                    "example": "10000.00"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "Версия подопечного при обновлении, см. PUT /api/v1/wards",
+                    "type": "string"
+                },
+                "want": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.WardResponse": {
             "type": "object",
             "properties": {
                 "address": {
-                    "description": "* Адрес подопечного",
                     "type": "string"
                 },
                 "collected": {
-                    "description": "* Количество собранных средств",
-                    "type": "number"
+                    "type": "string",
+                    "example": "2500.50"
                 },
                 "createdAt": {
-                    "description": "* Дата создания сущности в базе данных",
                     "type": "string"
                 },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
                 "donations": {
-                    "description": "* пожертвования для подопечного",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Donations"
+                        "$ref": "#/definitions/server.DonationResponse"
                     }
                 },
                 "fullName": {
-                    "description": "* Полное имя подопечного",
                     "type": "string"
                 },
                 "id": {
-                    "description": "* ID подопечного в базе данных",
                     "type": "integer"
                 },
                 "necessary": {
-                    "description": "* Необходимая сумма денег на потребность",
-                    "type": "number"
+                    "type": "string",
+                    "example": "10000.00"
                 },
                 "title": {
-                    "description": "* Дополнительный текст к подопечному",
                     "type": "string"
                 },
                 "updatedAt": {
-                    "description": "* Дата последнего обновления сущности в базе данных",
                     "type": "string"
                 },
                 "want": {
-                    "description": "* Потребность подопечного (то в чем он нуждается, например \"Лекарства\")",
                     "type": "string"
                 }
             }
         },
-        "DatabaseServicev1.WardsResponse": {
+        "server.WardsResponse": {
             "type": "object",
             "properties": {
                 "wards": {
-                    "description": "* Массив подопечных",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/DatabaseServicev1.Ward"
+                        "$ref": "#/definitions/server.WardResponse"
                     }
                 }
             }
         },
-        "server.HTTPError": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer"
-                },
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "server.LoginRequest": {
+        "server.WebhookResponse": {
             "type": "object",
             "properties": {
-                "password": {
+                "paymentId": {
                     "type": "string"
                 },
-                "phone": {
+                "status": {
+                    "description": "Состояние платежа после обработки уведомления",
                     "type": "string"
                 }
             }
         },
-        "server.LoginResponse": {
+        "token.JWK": {
             "type": "object",
             "properties": {
-                "token": {
+                "alg": {
                     "type": "string"
-                }
-            }
-        },
-        "server.PaymentRequest": {
-            "type": "object",
-            "properties": {
-                "amount": {
-                    "type": "number"
                 },
-                "description": {
+                "crv": {
+                    "description": "Кривая OKP ключа",
                     "type": "string"
                 },
-                "toWardId": {
-                    "type": "integer"
-                }
-            }
-        },
-        "server.RegistrationRequest": {
-            "type": "object",
-            "properties": {
-                "card": {
-                    "$ref": "#/definitions/DatabaseServicev1.CreateCardRequest"
-                },
-                "company": {
-                    "$ref": "#/definitions/DatabaseServicev1.CreateCompanyRequest"
+                "e": {
+                    "description": "Экспонента RSA ключа",
+                    "type": "string"
                 },
-                "email": {
+                "kid": {
                     "type": "string"
                 },
-                "password": {
+                "kty": {
                     "type": "string"
                 },
-                "phone": {
+                "n": {
+                    "description": "Модуль RSA ключа",
                     "type": "string"
                 },
-                "type": {
-                    "type": "integer"
+                "use": {
+                    "type": "string"
                 },
-                "username": {
+                "x": {
+                    "description": "Открытый ключ Ed25519",
                     "type": "string"
                 }
             }
+        },
+        "token.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/token.JWK"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -6,7 +6,10 @@ import (
 	"apiGateway/pkg/token"
 	"apiGateway/pkg/utilities"
 	"encoding/json"
+	"errors"
 	"github.com/nyaruka/phonenumbers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"net/http"
 	"regexp"
 	"unicode"
@@ -18,7 +21,12 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
 }
 
 type RegistrationRequest struct {
@@ -42,9 +50,11 @@ type RegistrationRequest struct {
 // @Failure      400  {object}  HTTPError
 // @Failure      401  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Header       429  {integer}  Retry-After  "Через сколько секунд разрешена следующая попытка"
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/auth/login [post]
-func (route Router) Login(w http.ResponseWriter, r *http.Request) {
+func (route *Router) Login(w http.ResponseWriter, r *http.Request) {
 	request := new(LoginRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -64,9 +74,17 @@ func (route Router) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !route.allowLoginAttempt(w, r, request.Phone) {
+		return
+	}
+
 	user, err := route.databaseService.FindUserByPhone(r.Context(),
 		&DatabaseServicev1.FindUserByPhoneRequest{Phone: request.Phone})
 	if err != nil {
+		// Попытка входа с несуществующим телефоном тоже считается неудачной, иначе перебор телефонов не ограничен
+		if status.Code(err) == codes.NotFound {
+			route.loginFailed(r, request.Phone)
+		}
 		SetGRPCError(w, err)
 		return
 	}
@@ -85,24 +103,14 @@ func (route Router) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !responseComparePassword.Accessory {
+		route.loginFailed(r, request.Phone)
 		SetHTTPError(w, "Неверный пароль", http.StatusUnauthorized)
 		return
 	}
 
-	jwtToken, err := token.CreateToken(user, route.cfg)
-	if err != nil {
-		logger.Error("Ошибка при создании JWT токена: %v", err)
-		SetHTTPError(w, "Ошибка при создании JWT токена", http.StatusInternalServerError)
-		return
-	}
+	route.loginSucceeded(r, request.Phone)
 
-	response := &LoginResponse{Token: jwtToken}
-
-	str := utilities.ToJSON(response)
-	_, err = w.Write([]byte(str))
-	if err != nil {
-		logger.Error("%s", err.Error())
-	}
+	route.completeLogin(w, r, user)
 }
 
 func hasUppercase(s string) bool {
@@ -114,6 +122,23 @@ func hasUppercase(s string) bool {
 	return false
 }
 
+// validatePassword - проверяет требования к паролю, возвращает текст ошибки или пустую строку
+func validatePassword(password string) string {
+	if password == "" {
+		return "Пароль не может быть пустым"
+	}
+
+	if len(password) < 8 {
+		return "Пароль не может быть меньше 8 символов"
+	}
+
+	if !hasUppercase(password) {
+		return "Должна быть хотя бы одна заглавная буква"
+	}
+
+	return ""
+}
+
 // Registration godoc
 // @Summary      Регистрация пользователя
 // @Description  Регистрация нового пользователя пользователя
@@ -124,9 +149,10 @@ func hasUppercase(s string) bool {
 // @Success      200  {object}  LoginResponse
 // @Failure      400  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/auth/registration [post]
-func (route Router) Registration(w http.ResponseWriter, r *http.Request) {
+func (route *Router) Registration(w http.ResponseWriter, r *http.Request) {
 	registrationRequest := new(RegistrationRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(registrationRequest); err != nil {
@@ -134,7 +160,7 @@ func (route Router) Registration(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.Info("registrationRequest: %s", utilities.ToJSON(registrationRequest))
+	logger.Info("Регистрация пользователя %s", registrationRequest.Phone)
 
 	phone, err := phonenumbers.Parse(registrationRequest.Phone, "RU")
 	if err != nil {
@@ -156,18 +182,8 @@ func (route Router) Registration(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if registrationRequest.Password == "" || len(registrationRequest.Password) == 0 {
-		SetHTTPError(w, "Пароль не может быть пустым", http.StatusBadRequest)
-		return
-	}
-
-	if len(registrationRequest.Password) < 8 {
-		SetHTTPError(w, "Пароль не может быть меньше 8 символов", http.StatusBadRequest)
-		return
-	}
-
-	if !hasUppercase(registrationRequest.Password) {
-		SetHTTPError(w, "Должна быть хотя бы одна заглавная буква", http.StatusBadRequest)
+	if errStr := validatePassword(registrationRequest.Password); errStr != "" {
+		SetHTTPError(w, errStr, http.StatusBadRequest)
 		return
 	}
 
@@ -183,25 +199,119 @@ func (route Router) Registration(w http.ResponseWriter, r *http.Request) {
 		Donations: nil,
 	}
 
+	if err := route.sealNewUser(r.Context(), newUser); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	respService, err := route.databaseService.CreateUser(r.Context(), newUser)
 	if err != nil {
 		SetGRPCError(w, err)
 		return
 	}
 
-	jwtToken, err := token.CreateToken(respService, route.cfg)
+	route.completeLogin(w, r, respService)
+}
+
+// Refresh godoc
+// @Summary      Обновление токенов
+// @Description  Ротация refresh токена и выпуск нового токена доступа. Повторное использование уже ротированного refresh токена отзывает сессию
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        token body RefreshRequest true "Refresh токен"
+// @Success      200  {object}  LoginResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/refresh [post]
+func (route *Router) Refresh(w http.ResponseWriter, r *http.Request) {
+	request := new(RefreshRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		return
+	}
+
+	if request.RefreshToken == "" {
+		SetHTTPError(w, "Поле \"RefreshToken\" не может быть пустым", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := route.tokens.ParseRefreshToken(request.RefreshToken)
 	if err != nil {
-		logger.Error("Ошибка при создании JWT токена: %v", err)
-		SetHTTPError(w, "Ошибка при создании JWT токена", http.StatusInternalServerError)
+		if errors.Is(err, token.ErrTokenExpired) {
+			SetHTTPError(w, "Срок действия refresh токена истек", http.StatusUnauthorized)
+			return
+		}
+		SetHTTPError(w, "Неверный refresh токен", http.StatusUnauthorized)
 		return
 	}
 
-	response := &LoginResponse{Token: jwtToken}
+	response, err := route.rotateSession(r.Context(), claims, r.UserAgent())
+	switch {
+	case errors.Is(err, errSessionNotFound):
+		SetHTTPError(w, "Сессия не найдена или отозвана", http.StatusUnauthorized)
+		return
+	case errors.Is(err, errRefreshTokenReused):
+		SetHTTPError(w, "Refresh токен уже был использован, сессия отозвана", http.StatusUnauthorized)
+		return
+	case err != nil:
+		logger.Error("Ошибка при обновлении сессии: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
 
 	str := utilities.ToJSON(response)
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
 
+// Logout godoc
+// @Summary      Выход из аккаунта
+// @Description  Завершает текущую сессию, refresh токен сессии становится недействительным
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  DatabaseServicev1.HTTPCodes
+// @Failure      401  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/logout [post]
+func (route *Router) Logout(w http.ResponseWriter, r *http.Request) {
+	sessionId := r.Context().Value("user").(token.IUser).GetSessionId()
+
+	response, err := route.databaseService.DeleteSessionById(r.Context(),
+		&DatabaseServicev1.DeleteSessionByIdRequest{Id: sessionId})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	str := utilities.ToJSON(response)
 	_, err = w.Write([]byte(str))
 	if err != nil {
-		logger.Error("Ошибка при формировании ответа: %v", err)
+		logger.Error("%s", err.Error())
+	}
+}
+
+// JWKS godoc
+// @Summary      Открытые ключи JWT
+// @Description  Набор открытых ключей (JWKS) для проверки подписи токенов, выпущенных шлюзом
+// @Tags         Authentication
+// @Produce      json
+// @Success      200  {object}  token.JWKS
+// @Router       /.well-known/jwks.json [get]
+func (route *Router) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	str := utilities.ToJSON(route.tokens.JWKS())
+	_, err := w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
 	}
 }
@@ -2,8 +2,10 @@ package server
 
 import (
 	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/bankcard"
 	"apiGateway/pkg/logger"
 	"apiGateway/pkg/utilities"
+	"context"
 	"encoding/json"
 	"github.com/gorilla/mux"
 	"net/http"
@@ -16,22 +18,30 @@ import (
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {object}  DatabaseServicev1.CardsCompaniesResponse
+// @Param        limit   query     int     false  "Размер страницы"
+// @Param        cursor  query     string  false  "Курсор следующей страницы из заголовка Link"
+// @Param        sort    query     string  false  "Сортировка: id, companyId, createdAt, updatedAt, \"-\" перед полем - по убыванию"  default(id)
+// @Param        filter  query     string  false  "Фильтр поле:значение через запятую, поля: companyId"
+// @Success      200  {object}  CardCompaniesResponse
+// @Header       200  {string}   Link           "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+// @Header       200  {integer}  X-Total-Count  "Количество элементов с учетом фильтра"
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/card/company [get]
-func (route Router) CardCompanies(w http.ResponseWriter, r *http.Request) {
-	response, err := route.databaseService.CardsCompanies(r.Context(), nil)
-	if err != nil {
-		logger.Error("Ошибка при выполнении запроса: %v", err)
-		SetGRPCError(w, err)
+func (route *Router) CardCompanies(w http.ResponseWriter, r *http.Request) {
+	cards, ok := listPage(route, w, r, cardCompaniesList, func(ctx context.Context) ([]*DatabaseServicev1.CardCompany, error) {
+		response, err := route.databaseService.CardsCompanies(ctx, nil)
+		return response.GetCards(), err
+	})
+	if !ok {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardCompaniesResponse(r.Context(), cards))
 
-	_, err = w.Write([]byte(str))
+	_, err := w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
@@ -43,33 +53,64 @@ func (route Router) CardCompanies(w http.ResponseWriter, r *http.Request) {
 // @Tags         CardCompany
 // @Accept       json
 // @Produce      json
-// @Param        card body DatabaseServicev1.CreateCardCompanyRequest false "Сущность банковской карты компании"
-// @Success      200  {object}  DatabaseServicev1.Card
+// @Security     BearerAuth
+// @Param        card body CardCompanyRequest false "Сущность банковской карты компании"
+// @Success      200  {object}  CardCompanyResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/card/company [post]
-func (route Router) CreateCardCompany(w http.ResponseWriter, r *http.Request) {
-	request := new(DatabaseServicev1.CreateCardCompanyRequest)
+func (route *Router) CreateCardCompany(w http.ResponseWriter, r *http.Request) {
+	cardRequest := new(CardCompanyRequest)
 
-	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(cardRequest); err != nil {
 		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
 		return
 	}
 
+	request := &DatabaseServicev1.CreateCardCompanyRequest{FullName: cardRequest.FullName,
+		Number: cardRequest.Number, Date: cardRequest.Date, CompanyId: cardRequest.CompanyId}
+
 	if request.GetCompanyId() <= 0 {
 		SetHTTPError(w, "Поле \"CompanyID\" не может быть меньше или равно 0", http.StatusBadRequest)
 		return
 	}
 
-	_, err := route.databaseService.FindCompanyById(r.Context(), &DatabaseServicev1.FindCompanyByIdRequest{Id: request.
-		GetCompanyId()})
+	ownerId, err := route.companyOwnerById(r, request.GetCompanyId())
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
 		SetGRPCError(w, err)
 		return
 	}
 
+	if !route.allowOwner(w, r, ownerId) {
+		return
+	}
+
+	if err := checkCard("", cardRequest.input(), true); err != nil {
+		setCardError(w, err)
+		return
+	}
+
+	duplicate, err := route.companyHasCard(r.Context(), request.GetCompanyId(), request.GetNumber())
+	if err != nil {
+		logger.Error("Ошибка при проверке карт владельца: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+	if duplicate {
+		SetHTTPError(w, "Карта уже добавлена", http.StatusConflict)
+		return
+	}
+
+	if err := route.sealCard(r.Context(), &request.Number, &request.Cvv); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	response, err := route.databaseService.CreateCardCompany(r.Context(), request)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -77,7 +118,7 @@ func (route Router) CreateCardCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardCompanyResponse(r.Context(), response))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -92,12 +133,13 @@ func (route Router) CreateCardCompany(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Card ID"
-// @Success      200  {object}  DatabaseServicev1.CardCompany
+// @Success      200  {object}  CardCompanyResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/card/company/{id} [get]
-func (route Router) CardCompany(w http.ResponseWriter, r *http.Request) {
+func (route *Router) CardCompany(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -115,7 +157,7 @@ func (route Router) CardCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardCompanyResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -133,10 +175,11 @@ func (route Router) CardCompany(w http.ResponseWriter, r *http.Request) {
 // @Param        card body DatabaseServicev1.CardCompany false "Сущность банковской карты компании"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/card/company/deleteModel [post]
-func (route Router) DeleteCardCompaniesByModel(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteCardCompaniesByModel(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.CardCompany)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -168,10 +211,11 @@ func (route Router) DeleteCardCompaniesByModel(w http.ResponseWriter, r *http.Re
 // @Param        id   path      int  true  "ID банковской карты компании"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/card/company/{id} [delete]
-func (route Router) DeleteCardCompanyById(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteCardCompanyById(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -203,24 +247,36 @@ func (route Router) DeleteCardCompanyById(w http.ResponseWriter, r *http.Request
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        card body DatabaseServicev1.CardCompany false "Модель для обновления"
-// @Success      200  {object}  DatabaseServicev1.CardCompany
+// @Param        card body CardCompanyRequest false "Модель для обновления"
+// @Success      200  {object}  CardCompanyResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/card/company [put]
-func (route Router) UpdateCardCompany(w http.ResponseWriter, r *http.Request) {
+func (route *Router) UpdateCardCompany(w http.ResponseWriter, r *http.Request) {
 	id := utilities.StrToUint(mux.Vars(r)["id"])
 
-	request := new(DatabaseServicev1.CardCompany)
+	cardRequest := new(CardCompanyRequest)
 
-	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(cardRequest); err != nil {
 		http.Error(w, "invalid request", http.StatusBadRequest)
 		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
 		return
 	}
 
-	request.Id = id
+	if err := checkCard("", cardRequest.input(), false); err != nil {
+		setCardError(w, err)
+		return
+	}
+
+	request := &DatabaseServicev1.CardCompany{Id: id, FullName: cardRequest.FullName, Number: cardRequest.Number,
+		Date: cardRequest.Date, CompanyId: cardRequest.CompanyId}
+
+	if err := route.sealCard(r.Context(), &request.Number, &request.Cvv); err != nil {
+		setCardError(w, err)
+		return
+	}
 
 	response, err := route.databaseService.UpdateCardCompany(r.Context(), request)
 	if err != nil {
@@ -229,10 +285,75 @@ func (route Router) UpdateCardCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardCompanyResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
 }
+
+// CardCompanyRequest - банковская карта компании в запросе. CVV передается строкой, чтобы не терялись ведущие нули,
+// проверяется и в DatabaseService не передается. При обновлении проверяются только указанные поля
+type CardCompanyRequest struct {
+	FullName  string `json:"fullName"`
+	Number    string `json:"number" example:"4111111111111111"`
+	Date      string `json:"date" example:"12/30"` // Срок действия MM/YY
+	Cvv       string `json:"cvv,omitempty" example:"012"`
+	CompanyId uint64 `json:"companyId"`
+}
+
+// input - данные карты для проверки
+func (c *CardCompanyRequest) input() bankcard.Input {
+	return bankcard.Input{Number: c.Number, Date: c.Date, Cvv: c.Cvv}
+}
+
+// CardCompanyResponse - банковская карта компании. Номер карты маскируется до последних 4 цифр, CVV не передается
+type CardCompanyResponse struct {
+	Id        uint64 `json:"id"`
+	FullName  string `json:"fullName,omitempty"`
+	Number    string `json:"number,omitempty" example:"************1111"` // Маскированный номер карты
+	Last4     string `json:"last4,omitempty" example:"1111"`
+	Brand     string `json:"brand,omitempty" example:"visa" enums:"mir,visa,mastercard,unionpay"` // Платежная система
+	Date      string `json:"date,omitempty"`
+	CompanyId uint64 `json:"companyId,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+// CardCompaniesResponse - список банковских карт компаний
+type CardCompaniesResponse struct {
+	Cards []*CardCompanyResponse `json:"cards"`
+}
+
+// newCardCompanyResponse - банковская карта компании из DatabaseService без номера и CVV
+func (route *Router) newCardCompanyResponse(ctx context.Context, c *DatabaseServicev1.CardCompany) *CardCompanyResponse {
+	if c == nil {
+		return nil
+	}
+
+	masked := route.maskCard(ctx, c.GetNumber())
+
+	return &CardCompanyResponse{
+		Id:        c.GetId(),
+		FullName:  c.GetFullName(),
+		Number:    masked.Number,
+		Last4:     masked.Last4,
+		Brand:     masked.Brand,
+		Date:      c.GetDate(),
+		CompanyId: c.GetCompanyId(),
+		CreatedAt: c.GetCreatedAt(),
+		UpdatedAt: c.GetUpdatedAt(),
+	}
+}
+
+// newCardCompaniesResponse - список банковских карт компаний из DatabaseService
+func (route *Router) newCardCompaniesResponse(ctx context.Context,
+	cards []*DatabaseServicev1.CardCompany) CardCompaniesResponse {
+	response := CardCompaniesResponse{Cards: make([]*CardCompanyResponse, 0, len(cards))}
+	for _, c := range cards {
+		response.Cards = append(response.Cards, route.newCardCompanyResponse(ctx, c))
+	}
+
+	return response
+}
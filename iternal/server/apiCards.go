@@ -2,11 +2,16 @@ package server
 
 import (
 	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/bankcard"
 	"apiGateway/pkg/logger"
 	"apiGateway/pkg/utilities"
+	"apiGateway/pkg/vault"
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"net/http"
+	"sort"
 )
 
 // Cards godoc
@@ -16,22 +21,30 @@ import (
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {object}  DatabaseServicev1.CardsResponse
+// @Param        limit   query     int     false  "Размер страницы"
+// @Param        cursor  query     string  false  "Курсор следующей страницы из заголовка Link"
+// @Param        sort    query     string  false  "Сортировка: id, userId, createdAt, updatedAt, \"-\" перед полем - по убыванию"  default(id)
+// @Param        filter  query     string  false  "Фильтр поле:значение через запятую, поля: userId"
+// @Success      200  {object}  CardsResponse
+// @Header       200  {string}   Link           "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+// @Header       200  {integer}  X-Total-Count  "Количество элементов с учетом фильтра"
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/cards [get]
-func (route Router) Cards(w http.ResponseWriter, r *http.Request) {
-	response, err := route.databaseService.Cards(r.Context(), nil)
-	if err != nil {
-		logger.Error("Ошибка при выполнении запроса: %v", err)
-		SetGRPCError(w, err)
+func (route *Router) Cards(w http.ResponseWriter, r *http.Request) {
+	cards, ok := listPage(route, w, r, cardsList, func(ctx context.Context) ([]*DatabaseServicev1.Card, error) {
+		response, err := route.databaseService.Cards(ctx, nil)
+		return response.GetCards(), err
+	})
+	if !ok {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardsResponse(r.Context(), cards))
 
-	_, err = w.Write([]byte(str))
+	_, err := w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
@@ -45,12 +58,13 @@ func (route Router) Cards(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Card ID"
-// @Success      200  {object}  DatabaseServicev1.Card
+// @Success      200  {object}  CardResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/cards/{id} [get]
-func (route Router) Card(w http.ResponseWriter, r *http.Request) {
+func (route *Router) Card(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -68,7 +82,7 @@ func (route Router) Card(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -82,25 +96,36 @@ func (route Router) Card(w http.ResponseWriter, r *http.Request) {
 // @Tags         Cards
 // @Accept       json
 // @Produce      json
-// @Param        card body DatabaseServicev1.CreateCardRequest false "Сущность банковской карты"
-// @Success      200  {object}  DatabaseServicev1.Card
+// @Security     BearerAuth
+// @Param        card body CardRequest false "Сущность банковской карты"
+// @Success      200  {object}  CardResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/cards [post]
-func (route Router) CreateCard(w http.ResponseWriter, r *http.Request) {
-	request := new(DatabaseServicev1.CreateCardRequest)
+func (route *Router) CreateCard(w http.ResponseWriter, r *http.Request) {
+	cardRequest := new(CardRequest)
 
-	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(cardRequest); err != nil {
 		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
 		return
 	}
 
+	request := &DatabaseServicev1.CreateCardRequest{FullName: cardRequest.FullName, Number: cardRequest.Number,
+		Date: cardRequest.Date, UserId: cardRequest.UserId}
+
 	if request.GetUserId() <= 0 {
 		SetHTTPError(w, "Поле \"UserID\" не может быть меньше или равно 0", http.StatusBadRequest)
 		return
 	}
 
+	if !route.allowOwner(w, r, request.GetUserId()) {
+		return
+	}
+
 	_, err := route.databaseService.FindUserById(r.Context(), &DatabaseServicev1.FindUserByIdRequest{Id: request.
 		GetUserId()})
 	if err != nil {
@@ -109,6 +134,27 @@ func (route Router) CreateCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := checkCard("", cardRequest.input(), true); err != nil {
+		setCardError(w, err)
+		return
+	}
+
+	duplicate, err := route.userHasCard(r.Context(), request.GetUserId(), request.GetNumber(), 0)
+	if err != nil {
+		logger.Error("Ошибка при проверке карт владельца: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+	if duplicate {
+		SetHTTPError(w, "Карта уже добавлена", http.StatusConflict)
+		return
+	}
+
+	if err := route.sealCard(r.Context(), &request.Number, &request.Cvv); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	response, err := route.databaseService.CreateCard(r.Context(), request)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -116,7 +162,7 @@ func (route Router) CreateCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardResponse(r.Context(), response))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -133,10 +179,11 @@ func (route Router) CreateCard(w http.ResponseWriter, r *http.Request) {
 // @Param        card body DatabaseServicev1.Card false "Модель банковской карты"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/cards/deleteModel [post]
-func (route Router) DeleteCardByModel(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteCardByModel(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.Card)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -168,10 +215,11 @@ func (route Router) DeleteCardByModel(w http.ResponseWriter, r *http.Request) {
 // @Param        id   path      int  true  "ID банковской карты"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/cards/{id} [delete]
-func (route Router) DeleteCardById(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteCardById(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -199,24 +247,57 @@ func (route Router) DeleteCardById(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path int true "ID банковской карты"
-// @Param        card body DatabaseServicev1.UpdateUserCardRequest1 true "Модель для обновления"
-// @Success      200  {object}  DatabaseServicev1.UpdateUserCardResponse
+// @Param        card body UpdateCardRequest true "Модель для обновления"
+// @Success      200  {object}  CardResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/cards/{id} [put]
-func (route Router) UpdateCard(w http.ResponseWriter, r *http.Request) {
+func (route *Router) UpdateCard(w http.ResponseWriter, r *http.Request) {
 	id := utilities.StrToUint(mux.Vars(r)["id"])
 
-	request := new(DatabaseServicev1.UpdateUserCardRequest)
+	cardRequest := new(UpdateCardRequest)
 
-	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
+	if err := json.NewDecoder(r.Body).Decode(cardRequest); err != nil {
 		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
 		return
 	}
 
-	request.Id = id
+	if err := checkCard("", cardRequest.input(), false); err != nil {
+		setCardError(w, err)
+		return
+	}
+
+	// Новый номер не должен совпадать с другой картой того же владельца
+	if cardRequest.Number != "" && !vault.IsToken(cardRequest.Number) {
+		card, err := route.databaseService.FindCardById(r.Context(), &DatabaseServicev1.FindCardByIdRequest{Id: id})
+		if err != nil {
+			logger.Error("Ошибка при выполнении запроса: %v", err)
+			SetGRPCError(w, err)
+			return
+		}
+
+		duplicate, err := route.userHasCard(r.Context(), card.GetUserId(), cardRequest.Number, id)
+		if err != nil {
+			logger.Error("Ошибка при проверке карт владельца: %v", err)
+			SetGRPCError(w, err)
+			return
+		}
+		if duplicate {
+			SetHTTPError(w, "Карта уже добавлена", http.StatusConflict)
+			return
+		}
+	}
+
+	request := &DatabaseServicev1.UpdateUserCardRequest{Id: id, FullName: cardRequest.FullName,
+		Number: cardRequest.Number, Date: cardRequest.Date}
+
+	if err := route.sealCard(r.Context(), &request.Number, &request.Cvv); err != nil {
+		setCardError(w, err)
+		return
+	}
 
 	response, err := route.databaseService.UpdateCard(r.Context(), request)
 	if err != nil {
@@ -225,10 +306,213 @@ func (route Router) UpdateCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardResponse(r.Context(), response))
+
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// SharedCards godoc
+// @Summary      Карты нескольких владельцев
+// @Description  Карты, номер которых добавлен нескольким пользователям или компаниям (признак мошенничества).
+// @Description  Карты сравниваются по отпечатку номера, сам номер не возвращается
+// @Tags         Cards
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  SharedCardsResponse
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/cards/shared [get]
+func (route *Router) SharedCards(w http.ResponseWriter, r *http.Request) {
+	userCards, err := route.databaseService.Cards(r.Context(), nil)
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	companyCards, err := route.databaseService.CardsCompanies(r.Context(), nil)
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	type ownedCard struct {
+		number string
+		owner  CardOwnerResponse
+	}
+	owned := make([]ownedCard, 0, len(userCards.GetCards())+len(companyCards.GetCards()))
+	for _, c := range userCards.GetCards() {
+		owned = append(owned, ownedCard{c.GetNumber(), CardOwnerResponse{Type: cardOwnerUser, Id: c.GetUserId(),
+			CardId: c.GetId()}})
+	}
+	for _, c := range companyCards.GetCards() {
+		owned = append(owned, ownedCard{c.GetNumber(), CardOwnerResponse{Type: cardOwnerCompany, Id: c.GetCompanyId(),
+			CardId: c.GetId()}})
+	}
+
+	shared := make(map[string]*SharedCardResponse)
+	for _, c := range owned {
+		fingerprint, err := route.cardFingerprint(r.Context(), c.number)
+		if errors.Is(err, vault.ErrNotFound) || (err == nil && fingerprint == "") {
+			continue
+		}
+		if err != nil {
+			logger.Error("Ошибка хранилища карт: %v", err)
+			SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+			return
+		}
 
+		card, ok := shared[fingerprint]
+		if !ok {
+			masked := route.maskCard(r.Context(), c.number)
+			card = &SharedCardResponse{Fingerprint: fingerprint, Last4: masked.Last4, Brand: masked.Brand}
+			shared[fingerprint] = card
+		}
+		card.Owners = append(card.Owners, c.owner)
+	}
+
+	response := SharedCardsResponse{Cards: make([]SharedCardResponse, 0)}
+	for _, card := range shared {
+		if card.owners() > 1 {
+			response.Cards = append(response.Cards, *card)
+		}
+	}
+	sort.Slice(response.Cards, func(i, j int) bool {
+		if len(response.Cards[i].Owners) != len(response.Cards[j].Owners) {
+			return len(response.Cards[i].Owners) > len(response.Cards[j].Owners)
+		}
+		return response.Cards[i].Fingerprint < response.Cards[j].Fingerprint
+	})
+
+	str := utilities.ToJSON(response)
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
 }
+
+// CardRequest - банковская карта пользователя в запросе. CVV передается строкой, чтобы не терялись ведущие нули,
+// проверяется и в DatabaseService не передается
+type CardRequest struct {
+	FullName string `json:"fullName"`
+	Number   string `json:"number" example:"4111111111111111"`
+	Date     string `json:"date" example:"12/30"` // Срок действия MM/YY
+	Cvv      string `json:"cvv,omitempty" example:"012"`
+	UserId   uint64 `json:"userId"`
+}
+
+// input - данные карты для проверки
+func (c *CardRequest) input() bankcard.Input {
+	return bankcard.Input{Number: c.Number, Date: c.Date, Cvv: c.Cvv}
+}
+
+// UpdateCardRequest - обновление банковской карты пользователя, проверяются только указанные поля
+type UpdateCardRequest struct {
+	FullName string `json:"fullName,omitempty"`
+	Number   string `json:"number,omitempty" example:"4111111111111111"`
+	Date     string `json:"date,omitempty" example:"12/30"` // Срок действия MM/YY
+	Cvv      string `json:"cvv,omitempty" example:"012"`
+}
+
+// input - данные карты для проверки
+func (c *UpdateCardRequest) input() bankcard.Input {
+	return bankcard.Input{Number: c.Number, Date: c.Date, Cvv: c.Cvv}
+}
+
+// CardResponse - банковская карта пользователя. Номер карты маскируется до последних 4 цифр, CVV не передается
+type CardResponse struct {
+	Id        uint64 `json:"id"`
+	FullName  string `json:"fullName,omitempty"`
+	Number    string `json:"number,omitempty" example:"************1111"` // Маскированный номер карты
+	Last4     string `json:"last4,omitempty" example:"1111"`
+	Brand     string `json:"brand,omitempty" example:"visa" enums:"mir,visa,mastercard,unionpay"` // Платежная система
+	Date      string `json:"date,omitempty"`
+	UserId    uint64 `json:"userId,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+// CardsResponse - список банковских карт пользователей
+type CardsResponse struct {
+	Cards []CardResponse `json:"cards"`
+}
+
+// cardEntity - банковская карта пользователя в ответах DatabaseService
+type cardEntity interface {
+	GetId() uint64
+	GetFullName() string
+	GetNumber() string
+	GetDate() string
+	GetUserId() uint64
+	GetCreatedAt() string
+	GetUpdatedAt() string
+}
+
+// newCardResponse - банковская карта из DatabaseService без номера и CVV
+func (route *Router) newCardResponse(ctx context.Context, c cardEntity) CardResponse {
+	masked := route.maskCard(ctx, c.GetNumber())
+
+	return CardResponse{
+		Id:        c.GetId(),
+		FullName:  c.GetFullName(),
+		Number:    masked.Number,
+		Last4:     masked.Last4,
+		Brand:     masked.Brand,
+		Date:      c.GetDate(),
+		UserId:    c.GetUserId(),
+		CreatedAt: c.GetCreatedAt(),
+		UpdatedAt: c.GetUpdatedAt(),
+	}
+}
+
+// newCardsResponse - список банковских карт из DatabaseService
+func (route *Router) newCardsResponse(ctx context.Context, cards []*DatabaseServicev1.Card) CardsResponse {
+	response := CardsResponse{Cards: make([]CardResponse, 0, len(cards))}
+	for _, c := range cards {
+		response.Cards = append(response.Cards, route.newCardResponse(ctx, c))
+	}
+
+	return response
+}
+
+// Владельцы карт в отчете SharedCards
+const (
+	cardOwnerUser    = "user"
+	cardOwnerCompany = "company"
+)
+
+// CardOwnerResponse - владелец карты
+type CardOwnerResponse struct {
+	Type   string `json:"type" enums:"user,company"`
+	Id     uint64 `json:"id"`     // ID пользователя или компании
+	CardId uint64 `json:"cardId"` // ID карты в DatabaseService
+}
+
+// SharedCardResponse - карта, добавленная нескольким владельцам
+type SharedCardResponse struct {
+	Fingerprint string              `json:"fingerprint"` // Отпечаток номера карты (HMAC-SHA256)
+	Last4       string              `json:"last4,omitempty" example:"1111"`
+	Brand       string              `json:"brand,omitempty" example:"visa"`
+	Owners      []CardOwnerResponse `json:"owners"`
+}
+
+// owners - количество различных владельцев карты
+func (c *SharedCardResponse) owners() int {
+	distinct := make(map[CardOwnerResponse]struct{}, len(c.Owners))
+	for _, owner := range c.Owners {
+		distinct[CardOwnerResponse{Type: owner.Type, Id: owner.Id}] = struct{}{}
+	}
+
+	return len(distinct)
+}
+
+// SharedCardsResponse - отчет о картах нескольких владельцев, сначала карты с наибольшим числом владельцев
+type SharedCardsResponse struct {
+	Cards []SharedCardResponse `json:"cards"`
+}
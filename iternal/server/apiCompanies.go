@@ -2,8 +2,11 @@ package server
 
 import (
 	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/bankcard"
 	"apiGateway/pkg/logger"
+	"apiGateway/pkg/token"
 	"apiGateway/pkg/utilities"
+	"context"
 	"encoding/json"
 	"github.com/gorilla/mux"
 	"net/http"
@@ -16,22 +19,30 @@ import (
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {object}  DatabaseServicev1.CompaniesResponse
+// @Param        limit   query     int     false  "Размер страницы"
+// @Param        cursor  query     string  false  "Курсор следующей страницы из заголовка Link"
+// @Param        sort    query     string  false  "Сортировка: id, title, userId, createdAt, updatedAt, \"-\" перед полем - по убыванию"  default(id)
+// @Param        filter  query     string  false  "Фильтр поле:значение через запятую, поля: title, phone, inn, userId"
+// @Success      200  {object}  CompaniesResponse
+// @Header       200  {string}   Link           "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+// @Header       200  {integer}  X-Total-Count  "Количество элементов с учетом фильтра"
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/companies [get]
-func (route Router) Companies(w http.ResponseWriter, r *http.Request) {
-	response, err := route.databaseService.Companies(r.Context(), nil)
-	if err != nil {
-		logger.Error("Ошибка при выполнении запроса: %v", err)
-		SetGRPCError(w, err)
+func (route *Router) Companies(w http.ResponseWriter, r *http.Request) {
+	companies, ok := listPage(route, w, r, companiesList, func(ctx context.Context) ([]*DatabaseServicev1.Company, error) {
+		response, err := route.databaseService.Companies(ctx, nil)
+		return response.GetCompanies(), err
+	})
+	if !ok {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCompaniesResponse(r.Context(), companies))
 
-	_, err = w.Write([]byte(str))
+	_, err := w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
@@ -43,13 +54,16 @@ func (route Router) Companies(w http.ResponseWriter, r *http.Request) {
 // @Tags         Company
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        company body DatabaseServicev1.CreateCompanyRequest false "Сущность компании"
-// @Success      200  {object}  DatabaseServicev1.Company
+// @Success      200  {object}  CompanyResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/companies [post]
-func (route Router) CreateCompany(w http.ResponseWriter, r *http.Request) {
+func (route *Router) CreateCompany(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.CreateCompanyRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -57,6 +71,10 @@ func (route Router) CreateCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !route.allowOwner(w, r, request.GetUserId()) {
+		return
+	}
+
 	user, err := route.databaseService.FindUserById(r.Context(), &DatabaseServicev1.FindUserByIdRequest{Id: request.UserId})
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -70,6 +88,11 @@ func (route Router) CreateCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := route.sealCompanyCard(r.Context(), "card", request.GetCard()); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	response, err := route.databaseService.CreateCompany(r.Context(), request)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -77,7 +100,7 @@ func (route Router) CreateCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCompanyResponse(r.Context(), response))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -92,12 +115,13 @@ func (route Router) CreateCompany(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Company ID"
-// @Success      200  {object}  DatabaseServicev1.Company
+// @Success      200  {object}  CompanyResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/companies/{id} [get]
-func (route Router) Company(w http.ResponseWriter, r *http.Request) {
+func (route *Router) Company(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -115,7 +139,7 @@ func (route Router) Company(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCompanyResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -129,13 +153,16 @@ func (route Router) Company(w http.ResponseWriter, r *http.Request) {
 // @Tags         Company
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        phone query string true "Phone"
-// @Success      200  {object}  DatabaseServicev1.Company
+// @Success      200  {object}  CompanyResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/companies/ [get]
-func (route Router) FindCompanyByPhone(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindCompanyByPhone(w http.ResponseWriter, r *http.Request) {
 	phone := r.URL.Query().Get("phone")
 
 	if phone == "" || len(phone) == 0 {
@@ -155,7 +182,7 @@ func (route Router) FindCompanyByPhone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCompanyResponse(r.Context(), response))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -170,12 +197,13 @@ func (route Router) FindCompanyByPhone(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Company ID"
-// @Success      200  {object}  DatabaseServicev1.CardCompany
+// @Success      200  {object}  CardCompanyResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/companies/{id}/card [get]
-func (route Router) FindCompanyCard(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindCompanyCard(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -193,7 +221,7 @@ func (route Router) FindCompanyCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardCompanyResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -211,10 +239,11 @@ func (route Router) FindCompanyCard(w http.ResponseWriter, r *http.Request) {
 // @Param        company body DatabaseServicev1.DeleteCompanyByModelRequest false "Модель компании"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/companies/deleteModel [post]
-func (route Router) DeleteCompanyByModel(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteCompanyByModel(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.DeleteCompanyByModelRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -246,10 +275,11 @@ func (route Router) DeleteCompanyByModel(w http.ResponseWriter, r *http.Request)
 // @Param        id   path      int  true  "ID компании"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/company/{id} [delete]
-func (route Router) DeleteCompanyByID(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteCompanyByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -271,7 +301,7 @@ func (route Router) DeleteCompanyByID(w http.ResponseWriter, r *http.Request) {
 
 // UpdateCompany godoc
 // @Summary      Обновление компании
-// @Description  Обновление сущности компании
+// @Description  Обновление сущности компании, сменить владельца (userId) может только администратор
 // @Tags         Company
 // @Accept       json
 // @Produce      json
@@ -279,10 +309,11 @@ func (route Router) DeleteCompanyByID(w http.ResponseWriter, r *http.Request) {
 // @Param        company body DatabaseServicev1.UpdateCompanyRequest false "Модель для обновления"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/companies [put]
-func (route Router) UpdateCompany(w http.ResponseWriter, r *http.Request) {
+func (route *Router) UpdateCompany(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.UpdateCompanyRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -291,6 +322,37 @@ func (route Router) UpdateCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if request.GetCompany() == nil {
+		SetHTTPError(w, "Поле \"Company\" не может быть пустым", http.StatusBadRequest)
+		return
+	}
+
+	ownerId, err := route.companyOwnerById(r, request.GetCompany().GetId())
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	if !route.allowOwner(w, r, ownerId) {
+		return
+	}
+
+	// Владельца компании может сменить только администратор, ключ API (даже с companies:write) - нет
+	newOwnerId := request.GetCompany().GetUserId()
+	if newOwnerId != 0 && newOwnerId != ownerId && r.Context().Value("user").(token.IUser).GetRole() != RoleAdmin {
+		SetHTTPError(w, "Владельца компании может сменить только администратор", http.StatusForbidden)
+		return
+	}
+	if newOwnerId == 0 {
+		request.Company.UserId = ownerId
+	}
+
+	if err := route.sealCompanyCard(r.Context(), "company.card", request.GetCompany().GetCard()); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	response, err := route.databaseService.UpdateCompany(r.Context(), request)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -314,12 +376,14 @@ func (route Router) UpdateCompany(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        card body DatabaseServicev1.AddCardToCompanyRequest false "Сущность банковской карты"
-// @Success      200  {object}  DatabaseServicev1.AddCardToCompanyResponse
+// @Success      200  {object}  CompanyResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/companies/addCard [post]
-func (route Router) AddCardToCompany(w http.ResponseWriter, r *http.Request) {
+func (route *Router) AddCardToCompany(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.AddCardToCompanyRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -327,6 +391,44 @@ func (route Router) AddCardToCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if request.GetCard() == nil {
+		SetHTTPError(w, "Поле \"Card\" не может быть пустым", http.StatusBadRequest)
+		return
+	}
+
+	ownerId, err := route.companyOwnerById(r, request.GetCard().GetCompanyId())
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	if !route.allowOwner(w, r, ownerId) {
+		return
+	}
+
+	input := bankcard.Input{Number: request.GetCard().GetNumber(), Date: request.GetCard().GetDate()}
+	if err := checkCard("card", input, true); err != nil {
+		setCardError(w, err)
+		return
+	}
+
+	duplicate, err := route.companyHasCard(r.Context(), request.GetCard().GetCompanyId(), request.GetCard().GetNumber())
+	if err != nil {
+		logger.Error("Ошибка при проверке карт владельца: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+	if duplicate {
+		SetHTTPError(w, "Карта уже добавлена", http.StatusConflict)
+		return
+	}
+
+	if err := route.sealCard(r.Context(), &request.Card.Number, &request.Card.Cvv); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	response, err := route.databaseService.AddCardToCompany(r.Context(), request)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -334,9 +436,74 @@ func (route Router) AddCardToCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCompanyResponse(r.Context(), response))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
 }
+
+// CompanyResponse - компания с маскированной банковской картой
+type CompanyResponse struct {
+	Id        uint64               `json:"id"`
+	Title     string               `json:"title,omitempty"`
+	Phone     string               `json:"phone,omitempty"`
+	Address   string               `json:"address,omitempty"`
+	Site      string               `json:"site,omitempty"`
+	Inn       string               `json:"inn,omitempty"`
+	Kpp       string               `json:"kpp,omitempty"`
+	Okpo      string               `json:"okpo,omitempty"`
+	Card      *CardCompanyResponse `json:"card,omitempty"`
+	UserId    uint64               `json:"userId,omitempty"`
+	CreatedAt string               `json:"createdAt,omitempty"`
+	UpdatedAt string               `json:"updatedAt,omitempty"`
+}
+
+// CompaniesResponse - список компаний
+type CompaniesResponse struct {
+	Companies []*CompanyResponse `json:"companies"`
+}
+
+// companyEntity - компания в ответах DatabaseService
+type companyEntity interface {
+	GetId() uint64
+	GetTitle() string
+	GetPhone() string
+	GetAddress() string
+	GetSite() string
+	GetInn() string
+	GetKpp() string
+	GetOkpo() string
+	GetCard() *DatabaseServicev1.CardCompany
+	GetUserId() uint64
+	GetCreatedAt() string
+	GetUpdatedAt() string
+}
+
+// newCompanyResponse - компания из DatabaseService с маскированной банковской картой
+func (route *Router) newCompanyResponse(ctx context.Context, c companyEntity) *CompanyResponse {
+	return &CompanyResponse{
+		Id:        c.GetId(),
+		Title:     c.GetTitle(),
+		Phone:     c.GetPhone(),
+		Address:   c.GetAddress(),
+		Site:      c.GetSite(),
+		Inn:       c.GetInn(),
+		Kpp:       c.GetKpp(),
+		Okpo:      c.GetOkpo(),
+		Card:      route.newCardCompanyResponse(ctx, c.GetCard()),
+		UserId:    c.GetUserId(),
+		CreatedAt: c.GetCreatedAt(),
+		UpdatedAt: c.GetUpdatedAt(),
+	}
+}
+
+// newCompaniesResponse - список компаний из DatabaseService
+func (route *Router) newCompaniesResponse(ctx context.Context, companies []*DatabaseServicev1.Company) CompaniesResponse {
+	response := CompaniesResponse{Companies: make([]*CompanyResponse, 0, len(companies))}
+	for _, c := range companies {
+		response.Companies = append(response.Companies, route.newCompanyResponse(ctx, c))
+	}
+
+	return response
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestUpdateCompanyOwner(t *testing.T) {
+	owner := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79990000001", Role: RoleUser}
+	other := &DatabaseServicev1.CreateUserResponse{Id: 2, Phone: "+79990000002", Role: RoleUser}
+	admin := &DatabaseServicev1.CreateUserResponse{Id: 3, Phone: "+79990000003", Role: RoleAdmin}
+	db := newFakeDatabase(owner, other, admin)
+	db.addCompany(&DatabaseServicev1.Company{Id: 10, Title: "Фонд", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	token := func(user *DatabaseServicev1.CreateUserResponse) string {
+		tokens, err := route.openSession(context.Background(), user, "", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + tokens.Token
+	}
+	raw, _, err := route.apiKeys.Issue(context.Background(), "crm", []string{"companies:write"}, time.Hour, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		body          string
+		want          int
+		wantOwner     uint64
+	}{
+		{name: "Владелец передает компанию", authorization: token(owner),
+			body: `{"company":{"id":10,"title":"Фонд","userId":2}}`, want: http.StatusForbidden, wantOwner: 1},
+		{name: "Ключ API передает компанию", authorization: apiKeyScheme + " " + raw,
+			body: `{"company":{"id":10,"title":"Фонд","userId":2}}`, want: http.StatusForbidden, wantOwner: 1},
+		{name: "Ключ API без владельца в запросе", authorization: apiKeyScheme + " " + raw,
+			body: `{"company":{"id":10,"title":"Фонд помощи"}}`, want: http.StatusOK, wantOwner: 1},
+		{name: "Владелец без смены владельца", authorization: token(owner),
+			body: `{"company":{"id":10,"title":"Фонд","userId":1}}`, want: http.StatusOK, wantOwner: 1},
+		{name: "Администратор передает компанию", authorization: token(admin),
+			body: `{"company":{"id":10,"title":"Фонд","userId":2}}`, want: http.StatusOK, wantOwner: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveWith(route, http.MethodPut, "/api/v1/companies", tt.authorization, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("code = %d, want %d, body = %s", rec.Code, tt.want, rec.Body)
+			}
+			if got := db.company(10).GetUserId(); got != tt.wantOwner {
+				t.Errorf("владелец = %d, want %d", got, tt.wantOwner)
+			}
+		})
+	}
+}
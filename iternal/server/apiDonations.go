@@ -3,8 +3,12 @@ package server
 import (
 	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
 	"apiGateway/pkg/logger"
+	"apiGateway/pkg/money"
+	"apiGateway/pkg/payment"
 	"apiGateway/pkg/utilities"
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"net/http"
 )
@@ -15,22 +19,29 @@ import (
 // @Tags         Donations
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  DatabaseServicev1.DonationsResponse
+// @Param        limit   query     int     false  "Размер страницы"
+// @Param        cursor  query     string  false  "Курсор следующей страницы из заголовка Link"
+// @Param        sort    query     string  false  "Сортировка: id, amount, wardId, userId, createdAt, \"-\" перед полем - по убыванию"  default(-id)
+// @Param        filter  query     string  false  "Фильтр поле:значение через запятую, поля: wardId, userId"
+// @Success      200  {object}  DonationsResponse
+// @Header       200  {string}   Link           "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+// @Header       200  {integer}  X-Total-Count  "Количество элементов с учетом фильтра"
 // @Failure      400  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/donations [get]
-func (route Router) Donations(w http.ResponseWriter, r *http.Request) {
-	response, err := route.databaseService.Donations(r.Context(), nil)
-	if err != nil {
-		logger.Error("Ошибка при выполнении запроса: %v", err)
-		SetGRPCError(w, err)
+func (route *Router) Donations(w http.ResponseWriter, r *http.Request) {
+	donations, ok := listPage(route, w, r, donationsList, func(ctx context.Context) ([]*DatabaseServicev1.Donations, error) {
+		response, err := route.databaseService.Donations(ctx, nil)
+		return response.GetDonations(), err
+	})
+	if !ok {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newDonationsResponse(r.Context(), donations))
 
-	_, err = w.Write([]byte(str))
+	_, err := w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
@@ -42,50 +53,58 @@ func (route Router) Donations(w http.ResponseWriter, r *http.Request) {
 // @Tags         Donations
 // @Accept       json
 // @Produce      json
-// @Param        donation body DatabaseServicev1.CreateDonationsRequest false "Сущность пожертвования"
-// @Success      200  {object}  DatabaseServicev1.Card
+// @Security     BearerAuth
+// @Param        donation body DonationRequest false "Сущность пожертвования"
+// @Success      200  {object}  DonationResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/donations [post]
-func (route Router) CreateDonation(w http.ResponseWriter, r *http.Request) {
-	request := new(DatabaseServicev1.CreateDonationsRequest)
+func (route *Router) CreateDonation(w http.ResponseWriter, r *http.Request) {
+	request := new(DonationRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
-		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		setDecodeError(w, err)
 		return
 	}
 
-	if request.GetUserId() <= 0 {
+	if request.UserId <= 0 {
 		SetHTTPError(w, "Поле \"UserID\" не может быть меньше или равно 0", http.StatusBadRequest)
 		return
 	}
 
-	if request.GetWardId() <= 0 {
+	if request.WardId <= 0 {
 		SetHTTPError(w, "Поле \"WardID\" не может быть меньше или равно 0", http.StatusBadRequest)
 		return
 	}
 
-	if request.GetAmount() <= 0 {
+	if request.Amount <= 0 {
 		SetHTTPError(w, "Поле \"Amount\" не может быть меньше или равно 0", http.StatusBadRequest)
 		return
 	}
 
-	_, err := route.databaseService.FindUserById(r.Context(), &DatabaseServicev1.FindUserByIdRequest{Id: request.GetUserId()})
+	_, err := route.databaseService.FindUserById(r.Context(), &DatabaseServicev1.FindUserByIdRequest{Id: request.UserId})
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
 		SetGRPCError(w, err)
 		return
 	}
 
-	response, err := route.databaseService.CreateDonations(r.Context(), request)
+	response, err := route.databaseService.CreateDonations(r.Context(), &DatabaseServicev1.CreateDonationsRequest{
+		Title:  request.Title,
+		Amount: request.Amount.Float32(),
+		WardId: request.WardId,
+		UserId: request.UserId,
+	})
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
 		SetGRPCError(w, err)
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newDonationResponse(r.Context(), response))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -100,12 +119,13 @@ func (route Router) CreateDonation(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Donation ID"
-// @Success      200  {object}  DatabaseServicev1.FindDonationWardsResponse
+// @Success      200  {object}  DonationWardResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/donations/{id}/wards [get]
-func (route Router) FindDonationWards(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindDonationWards(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -123,7 +143,7 @@ func (route Router) FindDonationWards(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(&DonationWardResponse{Wards: route.newWardResponse(r.Context(), response.GetWards())})
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -139,12 +159,13 @@ func (route Router) FindDonationWards(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Donation ID"
-// @Success      200  {object}  DatabaseServicev1.FindDonationWardsResponse
+// @Success      200  {object}  DonationUserResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/donations/{id}/user [get]
-func (route Router) FindDonationUser(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindDonationUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -162,7 +183,12 @@ func (route Router) FindDonationUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	donationUser := &DonationUserResponse{}
+	if response.GetUser() != nil {
+		donationUser.User = route.newUserResponse(r.Context(), response.GetUser(), response.GetUser().GetDonations())
+	}
+
+	str := utilities.ToJSON(donationUser)
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -178,12 +204,13 @@ func (route Router) FindDonationUser(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Donation ID"
-// @Success      200  {object}  DatabaseServicev1.CreateDonationsResponse
+// @Success      200  {object}  DonationResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/donations/{id} [get]
-func (route Router) Donation(w http.ResponseWriter, r *http.Request) {
+func (route *Router) Donation(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -201,7 +228,7 @@ func (route Router) Donation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newDonationResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -219,10 +246,11 @@ func (route Router) Donation(w http.ResponseWriter, r *http.Request) {
 // @Param        donation body DatabaseServicev1.DeleteDonationByModelRequest false "Сущность пожертвования"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/donations/deleteModel [post]
-func (route Router) DeleteDonationByModel(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteDonationByModel(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.DeleteDonationByModelRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -254,10 +282,11 @@ func (route Router) DeleteDonationByModel(w http.ResponseWriter, r *http.Request
 // @Param        id   path      int  true  "ID пожертвования"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/donations/{id} [delete]
-func (route Router) DeleteDonationById(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteDonationById(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -289,32 +318,146 @@ func (route Router) DeleteDonationById(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        donation body DatabaseServicev1.UpdateDonationsRequest false "Модель для обновления"
-// @Success      200  {object}  DatabaseServicev1.CreateDonationsResponse
+// @Param        donation body DonationRequest false "Модель для обновления"
+// @Success      200  {object}  DonationResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/donations [put]
-func (route Router) UpdateDonation(w http.ResponseWriter, r *http.Request) {
-	request := new(DatabaseServicev1.UpdateDonationsRequest)
+func (route *Router) UpdateDonation(w http.ResponseWriter, r *http.Request) {
+	request := new(DonationRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
-		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		setDecodeError(w, err)
 		return
 	}
 
-	response, err := route.databaseService.UpdateDonation(r.Context(), request)
+	response, err := route.databaseService.UpdateDonation(r.Context(), &DatabaseServicev1.UpdateDonationsRequest{
+		Id:     request.Id,
+		Title:  request.Title,
+		Amount: request.Amount.Float32(),
+		WardId: request.WardId,
+		UserId: request.UserId,
+	})
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
 		SetGRPCError(w, err)
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newDonationResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
 }
+
+// DonationRequest - создание или обновление пожертвования администратором
+type DonationRequest struct {
+	Id     uint64       `json:"id,omitempty"` // Только при обновлении
+	Title  string       `json:"title"`
+	Amount money.Amount `json:"amount" swaggertype:"string" example:"100.50"`
+	WardId uint64       `json:"wardId"`
+	UserId uint64       `json:"userId"`
+}
+
+// DonationResponse - пожертвование с состоянием возврата
+type DonationResponse struct {
+	Id    uint64 `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+	money.Money
+	WardId    uint64          `json:"wardId,omitempty"`
+	UserId    uint64          `json:"userId,omitempty"`
+	CreatedAt string          `json:"createdAt,omitempty"`
+	UpdatedAt string          `json:"updatedAt,omitempty"`
+	Refund    *DonationRefund `json:"refund,omitempty"` // Только для пожертвований, по которым запрашивался возврат
+}
+
+// DonationRefund - состояние возврата пожертвования
+type DonationRefund struct {
+	Status   string           `json:"status"`                                         // pending - возврат выполняется, partial - возвращена часть, refunded - вся сумма, failed - возвраты не выполнены
+	Refunded money.Amount     `json:"refunded" swaggertype:"string" example:"100.50"` // Возвращенная сумма
+	Refunds  []RefundResponse `json:"refunds"`
+}
+
+// DonationUserResponse - автор пожертвования
+type DonationUserResponse struct {
+	User *UserResponse `json:"user,omitempty"`
+}
+
+// DonationsResponse - список пожертвований с состоянием возврата
+type DonationsResponse struct {
+	Donations []DonationResponse `json:"donations"`
+}
+
+// donation - пожертвование в ответах DatabaseService
+type donation interface {
+	GetId() uint64
+	GetTitle() string
+	GetAmount() float32
+	GetWardId() uint64
+	GetUserId() uint64
+	GetCreatedAt() string
+	GetUpdatedAt() string
+}
+
+// newDonationResponse - пожертвование из DatabaseService с состоянием возврата из реестра платежей
+func (route *Router) newDonationResponse(ctx context.Context, d donation) DonationResponse {
+	return DonationResponse{
+		Id:        d.GetId(),
+		Title:     d.GetTitle(),
+		Money:     money.New(money.FromFloat32(d.GetAmount()), route.cfg.Payment.Currency),
+		WardId:    d.GetWardId(),
+		UserId:    d.GetUserId(),
+		CreatedAt: d.GetCreatedAt(),
+		UpdatedAt: d.GetUpdatedAt(),
+		Refund:    route.donationRefund(ctx, d.GetId()),
+	}
+}
+
+// newDonationsResponse - список пожертвований из DatabaseService с состоянием возврата
+func (route *Router) newDonationsResponse(ctx context.Context, donations []*DatabaseServicev1.Donations) DonationsResponse {
+	response := DonationsResponse{Donations: make([]DonationResponse, 0, len(donations))}
+	for _, d := range donations {
+		response.Donations = append(response.Donations, route.newDonationResponse(ctx, d))
+	}
+
+	return response
+}
+
+// donationRefund - состояние возврата пожертвования, nil - возврат не запрашивался
+func (route *Router) donationRefund(ctx context.Context, donationId uint64) *DonationRefund {
+	p, err := route.ledger.FindByDonation(ctx, donationId)
+	if err != nil {
+		if !errors.Is(err, payment.ErrPaymentNotFound) {
+			logger.Error("Ошибка при поиске платежа пожертвования %d: %v", donationId, err)
+		}
+		return nil
+	}
+	if len(p.Refunds) == 0 {
+		return nil
+	}
+
+	refund := &DonationRefund{
+		Status:   "failed",
+		Refunded: p.Refunded,
+		Refunds:  make([]RefundResponse, 0, len(p.Refunds)),
+	}
+	switch {
+	case p.Status == payment.PaymentRefunded:
+		refund.Status = "refunded"
+	case p.Refunded > 0:
+		refund.Status = "partial"
+	}
+
+	for _, r := range p.Refunds {
+		if r.Status == payment.RefundPending {
+			refund.Status = "pending"
+		}
+		refund.Refunds = append(refund.Refunds, newRefundResponse(r))
+	}
+
+	return refund
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestDonationOwner(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79990000001", Role: RoleUser}
+	other := &DatabaseServicev1.CreateUserResponse{Id: 2, Phone: "+79990000002", Role: RoleUser}
+	db := newFakeDatabase(user, other)
+	route, _ := newTestRouter(t, db)
+
+	_, err := db.CreateDonations(context.Background(),
+		&DatabaseServicev1.CreateDonationsRequest{Title: "Лекарства", Amount: 100, WardId: 5, UserId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bearer := func(u *DatabaseServicev1.CreateUserResponse) string {
+		tokens, err := route.openSession(context.Background(), u, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + tokens.Token
+	}
+	userAuth, otherAuth := bearer(user), bearer(other)
+
+	// Пожертвование, его подопечные и данные жертвователя доступны только жертвователю
+	for _, path := range []string{"/api/v1/donations/1", "/api/v1/donations/1/wards", "/api/v1/donations/1/user"} {
+		if rec := serveWith(route, http.MethodGet, path, otherAuth, ""); rec.Code != http.StatusForbidden {
+			t.Errorf("%s: code = %d, want %d", path, rec.Code, http.StatusForbidden)
+		}
+	}
+
+	if rec := serveWith(route, http.MethodGet, "/api/v1/donations/1", userAuth, ""); rec.Code != http.StatusOK {
+		t.Errorf("свое пожертвование: code = %d, body = %s", rec.Code, rec.Body)
+	}
+}
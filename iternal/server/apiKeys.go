@@ -0,0 +1,267 @@
+package server
+
+import (
+	"apiGateway/pkg/apikey"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/token"
+	"apiGateway/pkg/utilities"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// apiKeyScheme - схема заголовка Authorization для ключей API: "Authorization: ApiKey agw_..."
+const apiKeyScheme = "ApiKey"
+
+// ApiKeyRequest - выпуск ключа API
+type ApiKeyRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`              // Области доступа, например ["wards:write", "donations:read"]
+	ExpiresIn string   `json:"expiresIn,omitempty"` // Срок действия (например, 720h), по умолчанию api_keys.default_ttl
+}
+
+// ApiKeyResponse - описание ключа API, сам ключ возвращается только при выпуске
+type ApiKeyResponse struct {
+	Id         string     `json:"id"`
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty"` // Ключ для заголовка Authorization, повторно не показывается
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  uint64     `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// ApiKeysResponse - список ключей API
+type ApiKeysResponse struct {
+	Keys []*ApiKeyResponse `json:"keys"`
+}
+
+// apiKeyPrincipal - субъект запроса, выполненного с ключом API
+type apiKeyPrincipal struct {
+	key apikey.Key
+}
+
+// GetUserId - ключ API не принадлежит пользователю
+func (p *apiKeyPrincipal) GetUserId() uint64 {
+	return 0
+}
+
+// GetRole - роль ключа API
+func (p *apiKeyPrincipal) GetRole() string {
+	return RoleService
+}
+
+// GetSessionId - ключ API не связан с сессией
+func (p *apiKeyPrincipal) GetSessionId() uint64 {
+	return 0
+}
+
+// apiKeyAuth - аутентификация запроса по ключу API: ключ должен быть действующим и содержать область группы маршрута
+func (route *Router) apiKeyAuth(w http.ResponseWriter, r *http.Request, next http.Handler, raw string) {
+	key, err := route.apiKeys.Authenticate(r.Context(), raw)
+	if err != nil {
+		logger.Warn("Отказано в доступе: %s [%s], %v", r.URL.String(), r.Method, err)
+		switch {
+		case errors.Is(err, apikey.ErrExpired):
+			SetHTTPError(w, "Ошибка доступа, срок действия ключа API истек", http.StatusUnauthorized)
+		case errors.Is(err, apikey.ErrRevoked):
+			SetHTTPError(w, "Ошибка доступа, ключ API отозван", http.StatusUnauthorized)
+		case errors.Is(err, apikey.ErrInvalidKey):
+			SetHTTPError(w, "Ошибка доступа, неверный ключ API", http.StatusUnauthorized)
+		default:
+			logger.Error("Ошибка при проверке ключа API: %v", err)
+			SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	scope, ok := requiredScope(r)
+	if !ok || !key.HasScope(scope) {
+		logger.Warn("Отказано в доступе: %s [%s], у ключа API %s нет области %q", r.URL.String(), r.Method,
+			key.Id, scope)
+		SetHTTPError(w, "Недостаточно прав для выполнения операции", http.StatusForbidden)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "user", token.IUser(&apiKeyPrincipal{key: key}))
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// IssueApiKey godoc
+// @Summary      Выпуск ключа API
+// @Description  Выпускает ключ API для межсервисных запросов. Ключ возвращается только в этом ответе, хранится только его хеш
+// @Tags         ApiKeys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body ApiKeyRequest true "Назначение, области и срок действия ключа"
+// @Success      200  {object}  ApiKeyResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/apikeys [post]
+func (route *Router) IssueApiKey(w http.ResponseWriter, r *http.Request) {
+	request := new(ApiKeyRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		SetHTTPError(w, "Поле \"name\" не может быть пустым", http.StatusBadRequest)
+		return
+	}
+
+	if len(request.Scopes) == 0 {
+		SetHTTPError(w, "Необходимо указать хотя бы одну область доступа", http.StatusBadRequest)
+		return
+	}
+
+	known := apiKeyScopes()
+	for _, scope := range request.Scopes {
+		if !slices.Contains(known, scope) {
+			SetHTTPError(w, "Неизвестная область доступа \""+scope+"\", допустимые области: "+
+				strings.Join(known, ", "), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ttl := route.cfg.ApiKeys.DefaultTTL
+	if request.ExpiresIn != "" {
+		var err error
+		ttl, err = time.ParseDuration(request.ExpiresIn)
+		if err != nil || ttl <= 0 {
+			SetHTTPError(w, "Поле \"expiresIn\" должно быть положительной длительностью, например 720h",
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	if route.cfg.ApiKeys.MaxTTL > 0 && (ttl <= 0 || ttl > route.cfg.ApiKeys.MaxTTL) {
+		SetHTTPError(w, "Срок действия ключа не может превышать "+route.cfg.ApiKeys.MaxTTL.String(),
+			http.StatusBadRequest)
+		return
+	}
+
+	user := r.Context().Value("user").(token.IUser)
+
+	raw, key, err := route.apiKeys.Issue(r.Context(), request.Name, request.Scopes, ttl, user.GetUserId())
+	if err != nil {
+		logger.Error("Ошибка при выпуске ключа API: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Пользователь %d выпустил ключ API %s (%s) с областями %v", user.GetUserId(), key.Id, key.Name,
+		key.Scopes)
+
+	response := newApiKeyResponse(key)
+	response.Key = raw
+
+	str := utilities.ToJSON(response)
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// ApiKeys godoc
+// @Summary      Список ключей API
+// @Description  Все выпущенные ключи API, включая отозванные и истекшие, со временем последнего использования
+// @Tags         ApiKeys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  ApiKeysResponse
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/apikeys [get]
+func (route *Router) ApiKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := route.apiKeys.List(r.Context())
+	if err != nil {
+		logger.Error("Ошибка при получении ключей API: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	response := &ApiKeysResponse{Keys: make([]*ApiKeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, newApiKeyResponse(key))
+	}
+
+	str := utilities.ToJSON(response)
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// RevokeApiKey godoc
+// @Summary      Отзыв ключа API
+// @Description  Отзывает ключ API, запросы с ним перестают приниматься сразу
+// @Tags         ApiKeys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID ключа"
+// @Success      200  {object}  ApiKeyResponse
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/apikeys/{id} [delete]
+func (route *Router) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+	key, err := route.apiKeys.Revoke(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			SetHTTPError(w, "Ключ API не найден", http.StatusNotFound)
+			return
+		}
+		logger.Error("Ошибка при отзыве ключа API: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("Пользователь %d отозвал ключ API %s (%s)", r.Context().Value("user").(token.IUser).GetUserId(),
+		key.Id, key.Name)
+
+	str := utilities.ToJSON(newApiKeyResponse(key))
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// newApiKeyResponse - описание ключа API без хеша
+func newApiKeyResponse(key apikey.Key) *ApiKeyResponse {
+	return &ApiKeyResponse{
+		Id:         key.Id,
+		Name:       key.Name,
+		Scopes:     key.Scopes,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  optionalTime(key.ExpiresAt),
+		LastUsedAt: optionalTime(key.LastUsedAt),
+		RevokedAt:  optionalTime(key.RevokedAt),
+	}
+}
+
+// optionalTime - nil для нулевого времени, чтобы поле не попадало в JSON
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveWith - выполняет запрос с заголовком Authorization
+func serveWith(route *Router, method, path, authorization, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", authorization)
+
+	rec := httptest.NewRecorder()
+	route.r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestApiKeys(t *testing.T) {
+	admin := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79990000001", Role: RoleAdmin}
+	db := newFakeDatabase(admin, &DatabaseServicev1.CreateUserResponse{Id: 2, Phone: "+79990000002", Role: RoleUser})
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), admin, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer := "Bearer " + tokens.Token
+
+	rec := serveWith(route, http.MethodPost, "/api/v1/apikeys", bearer, `{"name":"cron","scopes":["wards:admin"]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("неизвестная область: code = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = serveWith(route, http.MethodPost, "/api/v1/apikeys", bearer,
+		`{"name":"cron","scopes":["users:read","wards:write"],"expiresIn":"24h"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("выпуск ключа: code = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	issued := new(ApiKeyResponse)
+	if err = json.NewDecoder(rec.Body).Decode(issued); err != nil {
+		t.Fatal(err)
+	}
+	apiKey := apiKeyScheme + " " + issued.Key
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "Область группы, чужой ресурс", method: http.MethodGet, path: "/api/v1/users/2", want: http.StatusOK},
+		{name: "Нет области записи", method: http.MethodDelete, path: "/api/v1/users/2", want: http.StatusForbidden},
+		{name: "Нет области группы", method: http.MethodGet, path: "/api/v1/cards/1", want: http.StatusForbidden},
+		{name: "Административный маршрут", method: http.MethodDelete, path: "/api/v1/wards/1",
+			want: http.StatusForbidden},
+		{name: "Группа недоступна ключам", method: http.MethodGet, path: "/api/v1/auth/sessions",
+			want: http.StatusForbidden},
+		{name: "Управление ключами", method: http.MethodGet, path: "/api/v1/apikeys", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serveWith(route, tt.method, tt.path, apiKey, ""); rec.Code != tt.want {
+				t.Errorf("code = %d, want %d, body = %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	rec = serveWith(route, http.MethodGet, "/api/v1/apikeys", bearer, "")
+	list := new(ApiKeysResponse)
+	if err = json.NewDecoder(rec.Body).Decode(list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Keys) != 1 || list.Keys[0].Key != "" || list.Keys[0].LastUsedAt == nil {
+		t.Errorf("список ключей: %+v", list.Keys)
+	}
+
+	if rec = serveWith(route, http.MethodDelete, "/api/v1/apikeys/"+issued.Id, bearer, ""); rec.Code != http.StatusOK {
+		t.Fatalf("отзыв ключа: code = %d, body = %s", rec.Code, rec.Body)
+	}
+	if rec = serveWith(route, http.MethodGet, "/api/v1/users/2", apiKey, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("отозванный ключ: code = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
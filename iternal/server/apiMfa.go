@@ -0,0 +1,338 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/mfa"
+	"apiGateway/pkg/token"
+	"apiGateway/pkg/utilities"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// MfaPendingResponse - ответ на вход, для которого требуется второй фактор
+type MfaPendingResponse struct {
+	MfaRequired   bool   `json:"mfaRequired"`
+	MfaToken      string `json:"mfaToken"`                // Токен второго шага входа для /auth/2fa/login
+	SetupRequired bool   `json:"setupRequired,omitempty"` // Для роли пользователя 2FA обязательна, но еще не настроена
+	Secret        string `json:"secret,omitempty"`        // Секрет TOTP при обязательной настройке
+	Uri           string `json:"uri,omitempty"`           // URI otpauth:// при обязательной настройке
+}
+
+// MfaSetupResponse - новый секрет TOTP
+type MfaSetupResponse struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"` // URI otpauth:// для QR-кода
+}
+
+// MfaStatusResponse - состояние двухфакторной аутентификации
+type MfaStatusResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// MfaCodeRequest - код из приложения-аутентификатора
+type MfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// MfaLoginRequest - второй шаг входа
+type MfaLoginRequest struct {
+	MfaToken string `json:"mfaToken"`
+	Code     string `json:"code"`
+}
+
+// mfaRequired - для роли двухфакторная аутентификация обязательна
+func (route *Router) mfaRequired(role string) bool {
+	return slices.Contains(route.cfg.Mfa.RequiredRoles, role)
+}
+
+// completeLogin - завершает вход после проверки первого фактора: выпускает пару токенов или, если у пользователя
+// включена либо обязательна 2FA, токен второго шага входа (ответ 202)
+func (route *Router) completeLogin(w http.ResponseWriter, r *http.Request, user *DatabaseServicev1.CreateUserResponse) {
+	enabled, err := route.authenticator.Enabled(r.Context(), user.GetId())
+	if err != nil {
+		logger.Error("Ошибка при проверке настройки 2FA: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	if !enabled && !route.mfaRequired(user.GetRole()) {
+		response, err := route.openSession(r.Context(), user, r.UserAgent(), false)
+		if err != nil {
+			logger.Error("Ошибка при создании сессии: %v", err)
+			SetGRPCError(w, err)
+			return
+		}
+
+		str := utilities.ToJSON(response)
+		_, err = w.Write([]byte(str))
+		if err != nil {
+			logger.Error("%s", err.Error())
+		}
+		return
+	}
+
+	mfaToken, err := route.tokens.CreateMfaToken(user.GetId())
+	if err != nil {
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	response := MfaPendingResponse{MfaRequired: true, MfaToken: mfaToken}
+
+	// Для ролей с обязательной 2FA настройка начинается при входе и подтверждается первым кодом в /auth/2fa/login
+	if !enabled {
+		response.SetupRequired = true
+		response.Secret, response.Uri, err = route.authenticator.Setup(r.Context(), user.GetId(), user.GetPhone())
+		if err != nil {
+			logger.Error("Ошибка при настройке 2FA: %v", err)
+			SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	str := utilities.ToJSON(response)
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// MfaLogin godoc
+// @Summary      Второй шаг входа
+// @Description  Обменивает токен второго шага входа и код TOTP на пару токенов. Для ролей с обязательной 2FA первый код подтверждает настройку
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body MfaLoginRequest true "Токен второго шага и код TOTP"
+// @Success      200  {object}  LoginResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/2fa/login [post]
+func (route *Router) MfaLogin(w http.ResponseWriter, r *http.Request) {
+	request := new(MfaLoginRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		return
+	}
+
+	if request.MfaToken == "" || request.Code == "" {
+		SetHTTPError(w, "Необходимо указать токен второго шага и код", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := route.tokens.ParseMfaToken(request.MfaToken)
+	if err != nil {
+		SetHTTPError(w, tokenErrorMessage(err), http.StatusUnauthorized)
+		return
+	}
+
+	user, err := route.databaseService.FindUserById(r.Context(),
+		&DatabaseServicev1.FindUserByIdRequest{Id: utilities.StrToUint(claims.Subject)})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	ok := route.checkMfaCode(w, r, user.GetPhone(), func() error {
+		enabled, err := route.authenticator.Enabled(r.Context(), user.GetId())
+		switch {
+		case err != nil:
+			return err
+		case enabled:
+			return route.authenticator.Verify(r.Context(), user.GetId(), request.Code)
+		case route.mfaRequired(user.GetRole()):
+			return route.authenticator.Confirm(r.Context(), user.GetId(), request.Code)
+		default:
+			return mfa.ErrNotEnrolled
+		}
+	})
+	if !ok {
+		return
+	}
+
+	response, err := route.openSession(r.Context(), user, r.UserAgent(), true)
+	if err != nil {
+		logger.Error("Ошибка при создании сессии: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	str := utilities.ToJSON(response)
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// MfaSetup godoc
+// @Summary      Настройка 2FA
+// @Description  Создает секрет TOTP и возвращает URI otpauth:// для приложения-аутентификатора. 2FA включается после подтверждения кодом
+// @Tags         Authentication
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  MfaSetupResponse
+// @Failure      401  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/2fa/setup [post]
+func (route *Router) MfaSetup(w http.ResponseWriter, r *http.Request) {
+	user, err := route.databaseService.FindUserById(r.Context(),
+		&DatabaseServicev1.FindUserByIdRequest{Id: r.Context().Value("user").(token.IUser).GetUserId()})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	secret, uri, err := route.authenticator.Setup(r.Context(), user.GetId(), user.GetPhone())
+	if err != nil {
+		setMfaError(w, err)
+		return
+	}
+
+	str := utilities.ToJSON(MfaSetupResponse{Secret: secret, Uri: uri})
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// MfaConfirm godoc
+// @Summary      Подтверждение настройки 2FA
+// @Description  Включает двухфакторную аутентификацию, если код соответствует секрету из /auth/2fa/setup
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body MfaCodeRequest true "Код из приложения-аутентификатора"
+// @Success      200  {object}  MfaStatusResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Header       429  {integer}  Retry-After  "Через сколько секунд разрешена следующая попытка"
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/2fa/confirm [post]
+func (route *Router) MfaConfirm(w http.ResponseWriter, r *http.Request) {
+	request := new(MfaCodeRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		return
+	}
+
+	user, err := route.databaseService.FindUserById(r.Context(),
+		&DatabaseServicev1.FindUserByIdRequest{Id: r.Context().Value("user").(token.IUser).GetUserId()})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	ok := route.checkMfaCode(w, r, user.GetPhone(), func() error {
+		return route.authenticator.Confirm(r.Context(), user.GetId(), request.Code)
+	})
+	if !ok {
+		return
+	}
+
+	str := utilities.ToJSON(MfaStatusResponse{Enabled: true})
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// MfaDisable godoc
+// @Summary      Отключение 2FA
+// @Description  Отключает двухфакторную аутентификацию по действующему коду. Для ролей с обязательной 2FA недоступно
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body MfaCodeRequest true "Код из приложения-аутентификатора"
+// @Success      200  {object}  MfaStatusResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Header       429  {integer}  Retry-After  "Через сколько секунд разрешена следующая попытка"
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/2fa/disable [post]
+func (route *Router) MfaDisable(w http.ResponseWriter, r *http.Request) {
+	request := new(MfaCodeRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		return
+	}
+
+	if route.mfaRequired(r.Context().Value("user").(token.IUser).GetRole()) {
+		SetHTTPError(w, "Для вашей роли двухфакторная аутентификация обязательна", http.StatusForbidden)
+		return
+	}
+
+	user, err := route.databaseService.FindUserById(r.Context(),
+		&DatabaseServicev1.FindUserByIdRequest{Id: r.Context().Value("user").(token.IUser).GetUserId()})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	ok := route.checkMfaCode(w, r, user.GetPhone(), func() error {
+		return route.authenticator.Disable(r.Context(), user.GetId(), request.Code)
+	})
+	if !ok {
+		return
+	}
+
+	str := utilities.ToJSON(MfaStatusResponse{Enabled: false})
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// checkMfaCode - проверяет код 2FA функцией check с защитой от перебора, как при входе: неверные коды учитываются
+// для телефона пользователя и IP клиента. ok = false, если ответ с ошибкой уже записан
+func (route *Router) checkMfaCode(w http.ResponseWriter, r *http.Request, phone string, check func() error) bool {
+	if !route.allowLoginAttempt(w, r, phone) {
+		return false
+	}
+
+	if err := check(); err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			route.loginFailed(r, phone)
+		}
+		setMfaError(w, err)
+		return false
+	}
+
+	route.loginSucceeded(r, phone)
+
+	return true
+}
+
+// setMfaError - формирует ответ на ошибку двухфакторной аутентификации
+func setMfaError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, mfa.ErrInvalidCode):
+		SetHTTPError(w, "Неверный код", http.StatusBadRequest)
+	case errors.Is(err, mfa.ErrNotEnrolled):
+		SetHTTPError(w, "Двухфакторная аутентификация не настроена", http.StatusBadRequest)
+	case errors.Is(err, mfa.ErrAlreadyEnabled):
+		SetHTTPError(w, "Двухфакторная аутентификация уже включена", http.StatusConflict)
+	default:
+		logger.Error("Ошибка двухфакторной аутентификации: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+	}
+}
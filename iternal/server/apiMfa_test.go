@@ -0,0 +1,118 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"context"
+	"encoding/json"
+	"github.com/pquerna/otp/totp"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMfaRequiredForAdmin(t *testing.T) {
+	db := newFakeDatabase(&DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleAdmin})
+	route, sms := newTestRouter(t, db)
+	route.cfg.Mfa.RequiredRoles = []string{RoleAdmin}
+
+	serve(route, http.MethodPost, "/api/v1/auth/otp/request", `{"phone":"+79991234567"}`)
+	msg, _ := sms.Last("+79991234567")
+	code := regexp.MustCompile(`\d{6}`).FindString(msg.Text)
+
+	// Первый фактор пройден, но вместо токенов выдается токен второго шага и секрет для обязательной настройки
+	rec := serve(route, http.MethodPost, "/api/v1/auth/otp/verify", `{"phone":"+79991234567","code":"`+code+`"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("otp/verify: code = %d, want %d, body = %s", rec.Code, http.StatusAccepted, rec.Body)
+	}
+
+	pending := new(MfaPendingResponse)
+	if err := json.NewDecoder(rec.Body).Decode(pending); err != nil {
+		t.Fatal(err)
+	}
+	if !pending.SetupRequired || pending.Secret == "" || pending.MfaToken == "" {
+		t.Fatalf("ответ без обязательной настройки 2FA: %+v", pending)
+	}
+
+	totpCode, err := totp.GenerateCode(pending.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec = serve(route, http.MethodPost, "/api/v1/auth/2fa/login",
+		`{"mfaToken":"`+pending.MfaToken+`","code":"`+totpCode+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("2fa/login: code = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	tokens := new(LoginResponse)
+	if err = json.NewDecoder(rec.Body).Decode(tokens); err != nil {
+		t.Fatal(err)
+	}
+
+	// Администратор с подтвержденным вторым фактором не может отключить 2FA
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/2fa/disable", strings.NewReader(`{"code":"000000"}`))
+	req.Header.Set("Authorization", "Bearer "+tokens.Token)
+	resp := httptest.NewRecorder()
+	route.r.ServeHTTP(resp, req)
+	if resp.Code != http.StatusForbidden {
+		t.Errorf("2fa/disable: code = %d, want 403", resp.Code)
+	}
+}
+
+func TestMfaTokenWithoutSecondFactor(t *testing.T) {
+	db := newFakeDatabase(&DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleAdmin})
+	route, _ := newTestRouter(t, db)
+	route.cfg.Mfa.RequiredRoles = []string{RoleAdmin}
+
+	// Токен администратора, выпущенный без второго фактора, не принимается
+	user, _ := db.FindUserById(context.Background(), &DatabaseServicev1.FindUserByIdRequest{Id: 1})
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/verify", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.Token)
+	rec := httptest.NewRecorder()
+	route.r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("code = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestMfaConfirmThrottled(t *testing.T) {
+	db := newFakeDatabase(&DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser})
+	route, _ := newTestRouter(t, db)
+
+	user, _ := db.FindUserById(context.Background(), &DatabaseServicev1.FindUserByIdRequest{Id: 1})
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorization := "Bearer " + tokens.Token
+
+	if rec := serveWith(route, http.MethodPost, "/api/v1/auth/2fa/setup", authorization, ""); rec.Code != http.StatusOK {
+		t.Fatalf("2fa/setup: code = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// Перебор кода подтверждения с украденным токеном ограничивается так же, как при входе
+	for i := 0; i < route.cfg.LoginGuard.Phone.FreeAttempts+1; i++ {
+		rec := serveWith(route, http.MethodPost, "/api/v1/auth/2fa/confirm", authorization, `{"code":"invalid"}`)
+		if rec.Code == http.StatusTooManyRequests {
+			t.Fatalf("попытка %d: 429 раньше исчерпания бесплатных попыток", i+1)
+		}
+	}
+
+	rec := serveWith(route, http.MethodPost, "/api/v1/auth/2fa/confirm", authorization, `{"code":"invalid"}`)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("2fa/confirm: code = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+
+	rec = serveWith(route, http.MethodPost, "/api/v1/auth/2fa/disable", authorization, `{"code":"invalid"}`)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("2fa/disable: code = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
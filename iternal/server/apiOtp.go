@@ -0,0 +1,227 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/notifier"
+	"apiGateway/pkg/onetime"
+	"apiGateway/pkg/utilities"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/nyaruka/phonenumbers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// purposeOtpLogin - назначение одноразового кода для входа по SMS
+const purposeOtpLogin = "otp_login"
+
+var errInvalidPhone = errors.New("неверный формат номера телефона")
+
+// OtpRequest - запрос кода для входа по SMS
+type OtpRequest struct {
+	Phone string `json:"phone"`
+}
+
+// OtpVerifyRequest - вход по коду из SMS
+type OtpVerifyRequest struct {
+	Phone string `json:"phone"`
+	Code  string `json:"code"`
+}
+
+// OtpRequestCode godoc
+// @Summary      Запрос кода для входа по SMS
+// @Description  Отправляет одноразовый код на номер телефона. Ответ не зависит от существования аккаунта
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body OtpRequest true "Номер телефона"
+// @Success      200  {object}  CodeSentResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Header       429  {integer}  Retry-After  "Через сколько секунд разрешен следующий запрос"
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/otp/request [post]
+func (route *Router) OtpRequestCode(w http.ResponseWriter, r *http.Request) {
+	request := new(OtpRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		return
+	}
+
+	phone, err := normalizePhone(request.Phone)
+	if err != nil {
+		SetHTTPError(w, "Неверный формат номера телефона", http.StatusBadRequest)
+		return
+	}
+
+	if !route.allowOtpRequest(w, r, phone) {
+		return
+	}
+
+	user, err := route.findUserByPhoneNumber(r.Context(), phone, request.Phone)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		logger.Warn("Запрос кода входа для несуществующего аккаунта: %s", phone)
+	case err != nil:
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	default:
+		_, err = route.sendCode(r.Context(), purposeOtpLogin, phone, notifier.ChannelSMS, user.GetPhone(),
+			"Код для входа: %s. Никому не сообщайте этот код")
+		// Повторный запрос в пределах resend_interval не раскрываем, иначе ответ выдает существование аккаунта
+		if err != nil && !errors.Is(err, onetime.ErrTooSoon) {
+			setCodeError(w, err, 0)
+			return
+		}
+	}
+
+	str := utilities.ToJSON(CodeSentResponse{ExpiresIn: int64(route.codes.TTL().Seconds())})
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// OtpVerify godoc
+// @Summary      Вход по коду из SMS
+// @Description  Обменивает одноразовый код на пару токенов, как при входе по паролю
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body OtpVerifyRequest true "Номер телефона и код"
+// @Success      200  {object}  LoginResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Header       429  {integer}  Retry-After  "Через сколько секунд разрешена следующая попытка"
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/otp/verify [post]
+func (route *Router) OtpVerify(w http.ResponseWriter, r *http.Request) {
+	request := new(OtpVerifyRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		return
+	}
+
+	phone, err := normalizePhone(request.Phone)
+	if err != nil {
+		SetHTTPError(w, "Неверный формат номера телефона", http.StatusBadRequest)
+		return
+	}
+
+	if request.Code == "" {
+		SetHTTPError(w, "Поле \"Code\" не может быть пустым", http.StatusBadRequest)
+		return
+	}
+
+	if !route.allowLoginAttempt(w, r, phone) {
+		return
+	}
+
+	if err = route.codes.Verify(r.Context(), purposeOtpLogin, phone, request.Code); err != nil {
+		if errors.Is(err, onetime.ErrCodeInvalid) || errors.Is(err, onetime.ErrTooManyAttempts) {
+			route.loginFailed(r, phone)
+		}
+		setCodeError(w, err, 0)
+		return
+	}
+
+	user, err := route.findUserByPhoneNumber(r.Context(), phone, request.Phone)
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	route.loginSucceeded(r, phone)
+
+	route.completeLogin(w, r, user)
+}
+
+// allowOtpRequest - учитывает запрос кода для телефона и IP клиента, при превышении лимита формирует ответ 429
+func (route *Router) allowOtpRequest(w http.ResponseWriter, r *http.Request, phone string) bool {
+	phoneWait, err := route.otpPhoneRequests.Check(r.Context(), phone)
+	if err != nil {
+		logger.Error("Ошибка при проверке лимита запросов кода: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return false
+	}
+
+	ipWait, err := route.otpIpRequests.Check(r.Context(), clientIP(r))
+	if err != nil {
+		logger.Error("Ошибка при проверке лимита запросов кода: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return false
+	}
+
+	if wait := max(phoneWait, ipWait); wait > 0 {
+		logger.Warn("Запрос кода входа отклонен: телефон %s, IP %s, повтор через %s", phone, clientIP(r), wait)
+		setRetryAfter(w, wait)
+		SetHTTPError(w, "Слишком много запросов кода, повторите позже", http.StatusTooManyRequests)
+		return false
+	}
+
+	if _, err = route.otpPhoneRequests.Fail(r.Context(), phone); err != nil {
+		logger.Error("Ошибка при учете запроса кода: %v", err)
+	}
+
+	if _, err = route.otpIpRequests.Fail(r.Context(), clientIP(r)); err != nil {
+		logger.Error("Ошибка при учете запроса кода: %v", err)
+	}
+
+	return true
+}
+
+// allowIpCodeRequest - учитывает запрос кода для IP клиента, при превышении лимита формирует ответ 429.
+// Запросы с одного IP ограничиваются независимо от адресата, чтобы нельзя было рассылать коды на чужие номера
+func (route *Router) allowIpCodeRequest(w http.ResponseWriter, r *http.Request) bool {
+	wait, err := route.otpIpRequests.Check(r.Context(), clientIP(r))
+	if err != nil {
+		logger.Error("Ошибка при проверке лимита запросов кода: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return false
+	}
+
+	if wait > 0 {
+		logger.Warn("Запрос кода отклонен: IP %s, повтор через %s", clientIP(r), wait)
+		setRetryAfter(w, wait)
+		SetHTTPError(w, "Слишком много запросов кода, повторите позже", http.StatusTooManyRequests)
+		return false
+	}
+
+	if _, err = route.otpIpRequests.Fail(r.Context(), clientIP(r)); err != nil {
+		logger.Error("Ошибка при учете запроса кода: %v", err)
+	}
+
+	return true
+}
+
+// normalizePhone - приводит номер телефона к формату E.164, номера без кода страны считаются российскими
+func normalizePhone(raw string) (string, error) {
+	phone, err := phonenumbers.Parse(raw, "RU")
+	if err != nil {
+		return "", errInvalidPhone
+	}
+
+	if !phonenumbers.IsValidNumber(phone) {
+		return "", errInvalidPhone
+	}
+
+	return phonenumbers.Format(phone, phonenumbers.E164), nil
+}
+
+// findUserByPhoneNumber - находит пользователя по нормализованному номеру, а если номер в базе сохранен
+// в другом формате - по номеру в том виде, в котором его ввел пользователь
+func (route *Router) findUserByPhoneNumber(ctx context.Context, phone, raw string) (*DatabaseServicev1.CreateUserResponse, error) {
+	user, err := route.databaseService.FindUserByPhone(ctx, &DatabaseServicev1.FindUserByPhoneRequest{Phone: phone})
+	if status.Code(err) == codes.NotFound && raw != phone {
+		return route.databaseService.FindUserByPhone(ctx, &DatabaseServicev1.FindUserByPhoneRequest{Phone: raw})
+	}
+
+	return user, err
+}
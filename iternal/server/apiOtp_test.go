@@ -0,0 +1,87 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// serve - выполняет запрос к маршрутизатору
+func serve(route *Router, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.RemoteAddr = "10.0.0.1:40000"
+
+	rec := httptest.NewRecorder()
+	route.r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestOtpLogin(t *testing.T) {
+	db := newFakeDatabase(&DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser})
+	route, sms := newTestRouter(t, db)
+
+	// Номер в другом формате нормализуется к формату, в котором он сохранен в базе
+	rec := serve(route, http.MethodPost, "/api/v1/auth/otp/request", `{"phone":"8 999 123-45-67"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("otp/request: code = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	msg, ok := sms.Last("+79991234567")
+	if !ok {
+		t.Fatal("код не отправлен")
+	}
+	code := regexp.MustCompile(`\d{6}`).FindString(msg.Text)
+
+	rec = serve(route, http.MethodPost, "/api/v1/auth/otp/verify", `{"phone":"+79991234567","code":"000000x"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("неверный код: code = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = serve(route, http.MethodPost, "/api/v1/auth/otp/verify", `{"phone":"+79991234567","code":"`+code+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("otp/verify: code = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	response := new(LoginResponse)
+	if err := json.NewDecoder(rec.Body).Decode(response); err != nil || response.Token == "" || response.RefreshToken == "" {
+		t.Errorf("otp/verify: ответ без пары токенов: %v", err)
+	}
+
+	// Код одноразовый
+	rec = serve(route, http.MethodPost, "/api/v1/auth/otp/verify", `{"phone":"+79991234567","code":"`+code+`"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("повторный код: code = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestOtpRequestLimits(t *testing.T) {
+	route, sms := newTestRouter(t, newFakeDatabase())
+
+	// Для несуществующего аккаунта ответ такой же, но код не отправляется.
+	// Первый запрос и free_attempts повторных запросов выполняются без задержки
+	for i := 0; i < 4; i++ {
+		rec := serve(route, http.MethodPost, "/api/v1/auth/otp/request", `{"phone":"+79990000000"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("запрос %d: code = %d, want %d", i+1, rec.Code, http.StatusOK)
+		}
+	}
+
+	if len(sms.Messages()) != 0 {
+		t.Errorf("отправлено %d сообщений для несуществующего аккаунта", len(sms.Messages()))
+	}
+
+	rec := serve(route, http.MethodPost, "/api/v1/auth/otp/request", `{"phone":"+79990000000"}`)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("превышение лимита: code = %d, Retry-After = %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	rec = serve(route, http.MethodPost, "/api/v1/auth/otp/request", `{"phone":"12"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("неверный номер: code = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/notifier"
+	"apiGateway/pkg/onetime"
+	"apiGateway/pkg/utilities"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// ForgotPasswordRequest - запрос кода для сброса пароля, указывается телефон или email
+type ForgotPasswordRequest struct {
+	Phone string `json:"phone,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// ResetPasswordRequest - сброс пароля по одноразовому коду
+type ResetPasswordRequest struct {
+	Phone    string `json:"phone,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
+
+// ForgotPassword godoc
+// @Summary      Запрос кода для сброса пароля
+// @Description  Отправляет одноразовый код на телефон или email аккаунта. Ответ не зависит от существования аккаунта
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ForgotPasswordRequest true "Телефон или email аккаунта"
+// @Success      200  {object}  CodeSentResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Header       429  {integer}  Retry-After  "Через сколько секунд разрешен следующий запрос"
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/password/forgot [post]
+func (route *Router) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	request := new(ForgotPasswordRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		return
+	}
+
+	if request.Phone == "" && request.Email == "" {
+		SetHTTPError(w, "Необходимо указать телефон или email", http.StatusBadRequest)
+		return
+	}
+
+	if !route.allowIpCodeRequest(w, r) {
+		return
+	}
+
+	user, err := route.findUserByContact(r.Context(), request.Phone, request.Email)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		logger.Warn("Запрос сброса пароля для несуществующего аккаунта: %s%s", request.Phone, request.Email)
+	case err != nil:
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	default:
+		channel, to := notifier.ChannelSMS, user.GetPhone()
+		if request.Phone == "" {
+			channel, to = notifier.ChannelEmail, user.GetEmail()
+		}
+
+		_, err = route.sendCode(r.Context(), purposePasswordReset, fmt.Sprint(user.GetId()), channel, to,
+			"Код для сброса пароля: %s. Никому не сообщайте этот код")
+		// Повторный запрос в пределах resend_interval не раскрываем, иначе ответ выдает существование аккаунта
+		if err != nil && !errors.Is(err, onetime.ErrTooSoon) {
+			setCodeError(w, err, 0)
+			return
+		}
+	}
+
+	str := utilities.ToJSON(CodeSentResponse{ExpiresIn: int64(route.codes.TTL().Seconds())})
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// ResetPassword godoc
+// @Summary      Сброс пароля
+// @Description  Устанавливает новый пароль по одноразовому коду, все сессии пользователя завершаются
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ResetPasswordRequest true "Телефон или email аккаунта, код и новый пароль"
+// @Success      200  {object}  DatabaseServicev1.HTTPCodes
+// @Failure      400  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Header       429  {integer}  Retry-After  "Через сколько секунд разрешена следующая попытка"
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/password/reset [post]
+func (route *Router) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	request := new(ResetPasswordRequest)
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		return
+	}
+
+	if (request.Phone == "" && request.Email == "") || request.Code == "" {
+		SetHTTPError(w, "Необходимо указать телефон или email и код", http.StatusBadRequest)
+		return
+	}
+
+	if errStr := validatePassword(request.Password); errStr != "" {
+		SetHTTPError(w, errStr, http.StatusBadRequest)
+		return
+	}
+
+	// Неверные коды учитываются по IP, иначе коды можно подбирать, перебирая аккаунты
+	if !route.allowIpAttempt(w, r) {
+		return
+	}
+
+	user, err := route.findUserByContact(r.Context(), request.Phone, request.Email)
+	if status.Code(err) == codes.NotFound {
+		route.ipAttemptFailed(r)
+		setCodeError(w, onetime.ErrCodeInvalid, 0)
+		return
+	}
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	if err = route.codes.Verify(r.Context(), purposePasswordReset, fmt.Sprint(user.GetId()), request.Code); err != nil {
+		route.ipAttemptFailed(r)
+		setCodeError(w, err, 0)
+		return
+	}
+
+	_, err = route.databaseService.UpdateUser(r.Context(), &DatabaseServicev1.UpdateUserRequest{
+		Id:       user.GetId(),
+		Email:    user.GetEmail(),
+		Username: user.GetUsername(),
+		Password: request.Password,
+		Phone:    user.GetPhone(),
+		Card:     user.GetCard(),
+		Role:     user.GetRole(),
+		Company:  user.GetCompany(),
+		Type:     user.GetType(),
+	})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	// После смены пароля все сессии завершаются, блокировка входа по телефону снимается
+	_, err = route.databaseService.DeleteSessionByUserId(r.Context(),
+		&DatabaseServicev1.DeleteSessionByUserIdRequest{UserId: user.GetId()})
+	if err != nil && status.Code(err) != codes.NotFound {
+		logger.Error("Ошибка при завершении сессий: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	route.loginSucceeded(r, user.GetPhone())
+
+	str := utilities.ToJSON(&DatabaseServicev1.HTTPCodes{Code: http.StatusOK})
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// findUserByContact - находит пользователя по телефону, а если он не указан - по email
+func (route *Router) findUserByContact(ctx context.Context, phone, email string) (*DatabaseServicev1.CreateUserResponse, error) {
+	if phone != "" {
+		return route.databaseService.FindUserByPhone(ctx, &DatabaseServicev1.FindUserByPhoneRequest{Phone: phone})
+	}
+
+	return route.databaseService.FindUserByEmail(ctx, &DatabaseServicev1.FindUserByEmailRequest{Email: email})
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/config"
+	"apiGateway/pkg/throttle"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPasswordResetThrottle(t *testing.T) {
+	var users []*DatabaseServicev1.CreateUserResponse
+	for id := uint64(1); id <= 3; id++ {
+		users = append(users, &DatabaseServicev1.CreateUserResponse{Id: id, Phone: fmt.Sprintf("+7999000000%d", id),
+			Role: RoleUser})
+	}
+	route, _ := newTestRouter(t, newFakeDatabase(users...))
+
+	policy := config.Attempts{FreeAttempts: 1, BaseDelay: time.Minute, Window: time.Hour}
+	route.otpIpRequests = throttle.NewGuard("otp:ip:", policy, throttle.NewMemoryStore())
+	route.ipAttempts = throttle.NewGuard("ip:", policy, throttle.NewMemoryStore())
+
+	// Коды на разные номера с одного IP ограничиваются общим лимитом
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		rec := serve(route, http.MethodPost, "/api/v1/auth/password/forgot", `{"phone":"`+users[i].Phone+`"}`)
+		if rec.Code != want {
+			t.Errorf("forgot %s: code = %d, want %d", users[i].Phone, rec.Code, want)
+		}
+	}
+
+	// Подбор кода по разным аккаунтам ограничивается по IP
+	for i, want := range []int{http.StatusBadRequest, http.StatusBadRequest, http.StatusTooManyRequests} {
+		rec := serve(route, http.MethodPost, "/api/v1/auth/password/reset",
+			`{"phone":"`+users[i].Phone+`","code":"invalid","password":"Password1!"}`)
+		if rec.Code != want {
+			t.Errorf("reset %s: code = %d, want %d", users[i].Phone, rec.Code, want)
+		}
+	}
+}
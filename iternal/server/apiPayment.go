@@ -2,37 +2,66 @@ package server
 
 import (
 	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/bankcard"
 	"apiGateway/pkg/logger"
+	"apiGateway/pkg/money"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/saga"
 	"apiGateway/pkg/token"
+	"apiGateway/pkg/utilities"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
 	"net/http"
 )
 
 type PaymentRequest struct {
-	ToWardId    uint64  `json:"toWardId"`
-	Amount      float64 `json:"amount"`
-	Description string  `json:"description"`
+	ToWardId    uint64       `json:"toWardId"`
+	CardId      uint64       `json:"cardId"`                                       // Карта пользователя для оплаты, можно не указывать, если карта одна
+	Cvv         string       `json:"cvv"`                                          // Используется только для авторизации этого платежа и не сохраняется
+	Amount      money.Amount `json:"amount" swaggertype:"string" example:"100.50"` // Не более знаков после точки, чем у валюты платежа, строкой или числом
+	Description string       `json:"description"`
+}
+
+// PaymentResponse - состояние платежа
+type PaymentResponse struct {
+	PaymentId     string `json:"paymentId"`
+	Status        string `json:"status"` // pending, processing, succeeded или failed
+	DonationId    uint64 `json:"donationId,omitempty"`
+	TransactionId string `json:"transactionId,omitempty"` // ID транзакции у платежного провайдера
+	money.Money
+	FailureReason string `json:"failureReason,omitempty"`
 }
 
 // Payment godoc
 // @Summary      Пожертвования
-// @Description  Пожертвования
+// @Description  Списывает сумму с карты пользователя через платежного провайдера и записывает пожертвование подопечному.
+// @Description  Если провайдер подтверждает платеж асинхронно, возвращается 202 с состоянием pending, пожертвование
+// @Description  записывается после уведомления провайдера, состояние платежа можно получить по paymentId
 // @Tags         Payments
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        payment body PaymentRequest true "Данные для оплаты"
-// @Success      200  {object}  HTTPError
+// @Param        Idempotency-Key header string false "Ключ идемпотентности, повтор с тем же ключом возвращает ответ на первый запрос"
+// @Success      200  {object}  PaymentResponse
+// @Success      202  {object}  PaymentResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      402  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
+// @Failure      422  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
+// @Failure      504  {object}  HTTPError
 // @Router       /api/v1/payment [post]
-func (route Router) Payment(w http.ResponseWriter, r *http.Request) {
+func (route *Router) Payment(w http.ResponseWriter, r *http.Request) {
 	request := new(PaymentRequest)
 	userId := r.Context().Value("user").(token.IUser).GetUserId()
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
-		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		setDecodeError(w, err)
 		return
 	}
 
@@ -46,6 +75,11 @@ func (route Router) Payment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := checkCard("", bankcard.Input{Cvv: request.Cvv}, false); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	user, err := route.databaseService.FindUserById(r.Context(), &DatabaseServicev1.FindUserByIdRequest{Id: userId})
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -71,31 +105,177 @@ func (route Router) Payment(w http.ResponseWriter, r *http.Request) {
 		request.Description = ward.Want
 	}
 
-	donation := &DatabaseServicev1.CreateDonationsRequest{
-		Title:  request.Description,
-		Amount: float32(request.Amount),
-		WardId: request.ToWardId,
-		UserId: user.Id,
+	if !(money.FromFloat32(ward.GetCollected()) + request.Amount).Exact32() {
+		SetHTTPError(w, "Собранная сумма подопечного превысит точность хранения, пожертвование не принимается",
+			http.StatusUnprocessableEntity)
+		return
 	}
 
-	ward.Collected += donation.Amount
+	card, ok := selectCard(cards.GetCards(), request.CardId)
+	if !ok {
+		if request.CardId == 0 {
+			SetHTTPError(w, "Необходимо указать карту для оплаты", http.StatusBadRequest)
+			return
+		}
+		SetHTTPError(w, "Карта не найдена", http.StatusNotFound)
+		return
+	}
 
-	_, err = route.databaseService.CreateDonations(r.Context(), donation)
+	paymentId, err := payment.NewId()
 	if err != nil {
-		logger.Error("Ошибка при выполнении запроса: %v", err)
-		SetGRPCError(w, err)
+		logger.Error("Ошибка при создании ID платежа: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
 		return
 	}
 
-	//TODO: потом изменить
-	_ = cards
+	data := &paymentData{
+		PaymentId: paymentId,
+		UserId:    user.Id,
+		WardId:    request.ToWardId,
+		CardId:    card.GetId(),
+		Cvv:       request.Cvv,
+		Title:     request.Description,
+		Amount:    request.Amount,
+	}
 
-	_, err = route.databaseService.UpdateWard(r.Context(), ward)
+	// Сага выполняется до конца и после отмены запроса клиентом, иначе откат прервется на середине
+	p, err := route.pay(context.WithoutCancel(r.Context()), data)
+	if errors.Is(err, errPaymentUnread) {
+		// Деньги уже списаны: повтор с тем же ключом идемпотентности получит этот ответ, а не новый платеж
+		commitIdempotent(w)
+		logger.Error("Платеж %s пользователя %d: %v", paymentId, user.Id, err)
+		SetHTTPError(w, "Ошибка на стороне сервера, проверьте состояние платежа", http.StatusInternalServerError)
+		return
+	}
+	if errors.Is(err, saga.ErrCompensationFailed) {
+		logger.Error("Платеж %s пользователя %d не завершен и будет отменен при восстановлении: %v", paymentId,
+			user.Id, err)
+		SetHTTPError(w, "Ошибка на стороне сервера, платеж будет отменен", http.StatusInternalServerError)
+		return
+	}
 	if err != nil {
-		logger.Error("Ошибка при выполнении запроса: %v", err)
-		SetGRPCError(w, err)
+		logger.Error("Ошибка при выполнении платежа %s: %v", paymentId, err)
+		setPaymentError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	if p.Status == payment.PaymentPending {
+		w.WriteHeader(http.StatusAccepted)
+	}
+
+	str := utilities.ToJSON(newPaymentResponse(p))
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// pay - выполняет сагу платежа и возвращает платеж из реестра. Причина отказа сохраняется в платеже
+func (route *Router) pay(ctx context.Context, data *paymentData) (payment.Payment, error) {
+	err := route.payments.Run(ctx, data)
+	if errors.Is(err, saga.ErrCompensationFailed) {
+		return payment.Payment{}, err
+	}
+	if err != nil {
+		route.setFailureReason(ctx, data.PaymentId, err)
+		return payment.Payment{}, err
+	}
+
+	p, err := route.ledger.Get(ctx, data.PaymentId)
+	if err != nil {
+		return payment.Payment{}, fmt.Errorf("%w: %v", errPaymentUnread, err)
+	}
+
+	return p, nil
+}
+
+// PaymentStatus godoc
+// @Summary      Пожертвования
+// @Description  Возвращает состояние платежа, доступно владельцу платежа и администратору
+// @Tags         Payments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID платежа"
+// @Success      200  {object}  PaymentResponse
+// @Failure      403  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Router       /api/v1/payment/{id} [get]
+func (route *Router) PaymentStatus(w http.ResponseWriter, r *http.Request) {
+	p, err := route.ledger.Get(r.Context(), mux.Vars(r)["id"])
+	if errors.Is(err, payment.ErrPaymentNotFound) {
+		SetHTTPError(w, "Платеж не найден", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Error("Ошибка при чтении платежа: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	str := utilities.ToJSON(newPaymentResponse(p))
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// newPaymentResponse - ответ с состоянием платежа из реестра
+func newPaymentResponse(p payment.Payment) PaymentResponse {
+	return PaymentResponse{
+		PaymentId:     p.Id,
+		Status:        string(p.Status),
+		DonationId:    p.DonationId,
+		TransactionId: p.TransactionId,
+		Money:         p.Money,
+		FailureReason: p.FailureReason,
+	}
+}
+
+// setFailureReason - сохраняет причину отказа в платеже, отмененном сагой
+func (route *Router) setFailureReason(ctx context.Context, paymentId string, reason error) {
+	_, err := route.ledger.Update(ctx, paymentId, func(p *payment.Payment) error {
+		p.FailureReason = reason.Error()
+		return nil
+	})
+	if err != nil && !errors.Is(err, payment.ErrPaymentNotFound) {
+		logger.Error("Ошибка при сохранении причины отказа платежа %s: %v", paymentId, err)
+	}
+}
+
+// selectCard - карта пользователя для оплаты: карта с ID cardId или единственная карта, если cardId не указан
+func selectCard(cards []*DatabaseServicev1.Card, cardId uint64) (*DatabaseServicev1.Card, bool) {
+	if cardId == 0 {
+		if len(cards) == 1 {
+			return cards[0], true
+		}
+		return nil, false
+	}
+
+	for _, card := range cards {
+		if card.GetId() == cardId {
+			return card, true
+		}
+	}
+
+	return nil, false
+}
+
+// setPaymentError - формирует ответ на ошибку платежного провайдера или DatabaseService
+func setPaymentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errCollectedInexact):
+		SetHTTPError(w, "Собранная сумма подопечного превысит точность хранения, пожертвование не принимается",
+			http.StatusUnprocessableEntity)
+	case errors.Is(err, payment.ErrDeclined):
+		SetHTTPError(w, "Платеж отклонен банком", http.StatusPaymentRequired)
+	case errors.Is(err, payment.ErrInsufficientFunds):
+		SetHTTPError(w, "Недостаточно средств на карте", http.StatusPaymentRequired)
+	case errors.Is(err, payment.ErrTimeout):
+		SetHTTPError(w, "Платежная система не ответила, повторите платеж позже", http.StatusGatewayTimeout)
+	case errors.Is(err, payment.ErrInvalidAmount), errors.Is(err, payment.ErrInvalidState),
+		errors.Is(err, payment.ErrNotFound):
+		SetHTTPError(w, "Ошибка платежной системы", http.StatusBadGateway)
+	default:
+		SetGRPCError(w, err)
+	}
 }
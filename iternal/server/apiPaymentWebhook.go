@@ -0,0 +1,178 @@
+package server
+
+import (
+	"apiGateway/pkg/idempotency"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/saga"
+	"apiGateway/pkg/utilities"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxWebhookBytes - максимальный размер уведомления платежного провайдера
+const maxWebhookBytes = 64 << 10
+
+// WebhookResponse - результат обработки уведомления платежного провайдера
+type WebhookResponse struct {
+	PaymentId string `json:"paymentId"`
+	Status    string `json:"status"` // Состояние платежа после обработки уведомления
+}
+
+// PaymentWebhook godoc
+// @Summary      Пожертвования
+// @Description  Принимает уведомление платежного провайдера о результате асинхронного платежа. Уведомление подписывается
+// @Description  HMAC-SHA256 секретом webhook_secret в заголовке X-Payment-Signature: "t=<unix время>,v1=<hex подпись
+// @Description  строки t.тело>". Повторная доставка уведомления с тем же id возвращает ответ на первую доставку
+// @Tags         Payments
+// @Accept       json
+// @Produce      json
+// @Param        provider path string true "Платежный провайдер"
+// @Param        X-Payment-Signature header string true "Подпись уведомления"
+// @Param        event body payment.Event true "Уведомление"
+// @Success      200  {object}  WebhookResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
+// @Failure      422  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Failure      503  {object}  HTTPError
+// @Router       /api/v1/payment/webhook/{provider} [post]
+func (route *Router) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	provider := mux.Vars(r)["provider"]
+	if provider != route.provider.Name() {
+		SetHTTPError(w, "Неизвестный платежный провайдер", http.StatusNotFound)
+		return
+	}
+
+	secret := route.cfg.Payment.WebhookSecret
+	if secret == "" {
+		// Провайдер повторит доставку после настройки секрета
+		logger.Error("Получено уведомление провайдера %s, но webhook_secret не указан", provider)
+		SetHTTPError(w, "Прием уведомлений не настроен", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		SetHTTPError(w, "Слишком длинное тело запроса", http.StatusBadRequest)
+		return
+	}
+
+	err = payment.VerifySignature(secret, r.Header.Get(payment.SignatureHeader), body, time.Now(),
+		route.cfg.Payment.WebhookTolerance)
+	if err != nil {
+		logger.Warn("Отклонено уведомление провайдера %s от %s: %v", provider, r.RemoteAddr, err)
+		SetHTTPError(w, "Неверная подпись уведомления", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := payment.ParseEvent(body)
+	if err != nil {
+		SetHTTPError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key := fmt.Sprintf("%s:%s", provider, event.Id)
+
+	response, replay, err := route.webhookEvents.Begin(r.Context(), key, body)
+	switch {
+	case errors.Is(err, idempotency.ErrInProgress):
+		setRetryAfter(w, route.cfg.APIServer.Timeout)
+		SetHTTPError(w, "Уведомление еще обрабатывается", http.StatusConflict)
+		return
+	case errors.Is(err, idempotency.ErrMismatch):
+		SetHTTPError(w, "Уведомление с этим id уже получено с другим содержимым", http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		logger.Error("Ошибка при проверке повтора уведомления: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	case replay:
+		logger.Info("Повторная доставка уведомления %s провайдера %s", event.Id, provider)
+		writeStoredResponse(w, response)
+		return
+	}
+
+	// Уведомление обрабатывается до конца и после разрыва соединения провайдером
+	ctx := context.WithoutCancel(r.Context())
+
+	rec := &responseRecorder{ResponseWriter: w}
+	route.applyPaymentEvent(ctx, rec, event)
+	completeIdempotent(ctx, route.webhookEvents, key, rec)
+}
+
+// applyPaymentEvent - переводит платеж в состояние из уведомления. Уведомление о платеже, который уже подтвержден
+// или отменен, не меняет платеж, поэтому повторная доставка безопасна и после истечения webhook_dedupe_ttl
+func (route *Router) applyPaymentEvent(ctx context.Context, w http.ResponseWriter, event payment.Event) {
+	p, err := route.ledger.FindByTransaction(ctx, route.provider.Name(), event.TransactionId)
+	if errors.Is(err, payment.ErrPaymentNotFound) {
+		logger.Warn("Уведомление %s для неизвестной транзакции %s", event.Id, event.TransactionId)
+		SetHTTPError(w, "Платеж не найден", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Error("Ошибка при поиске платежа: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	switch event.Type {
+	case payment.EventSucceeded:
+		err = route.settlements.Run(ctx, &paymentData{
+			PaymentId:     p.Id,
+			UserId:        p.UserId,
+			WardId:        p.WardId,
+			CardId:        p.CardId,
+			Title:         p.Title,
+			Amount:        p.Amount,
+			Provider:      p.Provider,
+			TransactionId: p.TransactionId,
+		})
+		switch {
+		case errors.Is(err, saga.ErrCompensationFailed):
+			logger.Error("Подтверждение платежа %s не завершено и будет отменено при восстановлении: %v", p.Id, err)
+			SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+			return
+		case errors.Is(err, errAlreadySettled):
+			logger.Info("Уведомление %s: платеж %s уже в состоянии %s", event.Id, p.Id, p.Status)
+		case err != nil:
+			logger.Error("Платеж %s отменен, не удалось записать пожертвование: %v", p.Id, err)
+			route.setFailureReason(ctx, p.Id, err)
+		}
+	case payment.EventFailed:
+		_, err = route.ledger.Update(ctx, p.Id, func(p *payment.Payment) error {
+			if err := p.Transition(payment.PaymentFailed); err != nil {
+				return err
+			}
+			p.FailureReason = event.Reason
+			return nil
+		})
+		if errors.Is(err, payment.ErrInvalidState) {
+			logger.Warn("Уведомление %s об отказе: платеж %s уже в состоянии %s", event.Id, p.Id, p.Status)
+		} else if err != nil {
+			logger.Error("Ошибка при отмене платежа %s: %v", p.Id, err)
+			SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	p, err = route.ledger.Get(ctx, p.Id)
+	if err != nil {
+		logger.Error("Ошибка при чтении платежа %s: %v", event.TransactionId, err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	str := utilities.ToJSON(WebhookResponse{PaymentId: p.Id, Status: string(p.Status)})
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
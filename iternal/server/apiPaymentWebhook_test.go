@@ -0,0 +1,131 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/payment"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postWebhook - отправляет уведомление провайдера с подписью, вычисленной на момент signedAt
+func postWebhook(route *Router, secret string, signedAt time.Time, event payment.Event) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(event)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payment/webhook/fake", strings.NewReader(string(body)))
+	req.Header.Set(payment.SignatureHeader, payment.Sign(secret, signedAt, body))
+
+	rec := httptest.NewRecorder()
+	route.r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000, UpdatedAt: "0"})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: payment.CardPending, UserId: 1})
+	route, _ := newTestRouter(t, db)
+	fake := route.provider.(*payment.Fake)
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pay := func() PaymentResponse {
+		t.Helper()
+
+		rec := serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token,
+			`{"toWardId":5,"amount":100}`)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("code = %d, want %d, body = %s", rec.Code, http.StatusAccepted, rec.Body)
+		}
+
+		response := PaymentResponse{}
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Status != string(payment.PaymentPending) {
+			t.Fatalf("статус = %s, want %s", response.Status, payment.PaymentPending)
+		}
+
+		return response
+	}
+
+	status := func(paymentId string) string {
+		t.Helper()
+
+		rec := serveWith(route, http.MethodGet, "/api/v1/payment/"+paymentId, "Bearer "+tokens.Token, "")
+		response := PaymentResponse{}
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+
+		return response.Status
+	}
+
+	succeeded := pay()
+	if db.donationCount() != 0 || db.ward(5).GetCollected() != 0 {
+		t.Fatal("пожертвование записано до подтверждения платежа")
+	}
+
+	event, err := fake.Settle(succeeded.TransactionId, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := postWebhook(route, "other", time.Now(), event); rec.Code != http.StatusUnauthorized {
+		t.Errorf("неверный секрет: code = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := postWebhook(route, "whsec", time.Now().Add(-time.Hour), event); rec.Code != http.StatusUnauthorized {
+		t.Errorf("устаревшая подпись: code = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec := postWebhook(route, "whsec", time.Now(), event)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d, want %d, body = %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	// Повторная доставка того же уведомления и то же списание под другим id не записывают пожертвование дважды
+	replay := postWebhook(route, "whsec", time.Now(), event)
+	if replay.Code != http.StatusOK || replay.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("повтор: code = %d, body = %s", replay.Code, replay.Body)
+	}
+	event.Id = "evt_redelivered"
+	if rec := postWebhook(route, "whsec", time.Now(), event); rec.Code != http.StatusOK {
+		t.Errorf("повтор с другим id: code = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	if got := status(succeeded.PaymentId); got != string(payment.PaymentSucceeded) {
+		t.Errorf("статус = %s, want %s", got, payment.PaymentSucceeded)
+	}
+	if got := db.donationCount(); got != 1 {
+		t.Errorf("создано пожертвований: %d, want 1", got)
+	}
+	if got := db.ward(5).GetCollected(); got != 100 {
+		t.Errorf("собрано: %v, want 100", got)
+	}
+
+	failed := pay()
+
+	event, err = fake.Settle(failed.TransactionId, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := postWebhook(route, "whsec", time.Now(), event); rec.Code != http.StatusOK {
+		t.Errorf("отказ: code = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	if got := status(failed.PaymentId); got != string(payment.PaymentFailed) {
+		t.Errorf("статус = %s, want %s", got, payment.PaymentFailed)
+	}
+	if got := db.donationCount(); got != 1 {
+		t.Errorf("создано пожертвований: %d, want 1", got)
+	}
+}
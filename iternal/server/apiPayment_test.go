@@ -0,0 +1,126 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/payment"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestPaymentCards(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000})
+	db.addCard(&DatabaseServicev1.Card{Id: 1, Number: "4111111111111111", UserId: 1})
+	db.addCard(&DatabaseServicev1.Card{Id: 2, Number: payment.CardDeclined, UserId: 1})
+	db.addCard(&DatabaseServicev1.Card{Id: 3, Number: payment.CardInsufficientFunds, UserId: 1})
+	db.addCard(&DatabaseServicev1.Card{Id: 4, Number: payment.CardTimeout, UserId: 1})
+	db.addCard(&DatabaseServicev1.Card{Id: 5, Number: "4111111111111111", UserId: 2})
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "Карта не указана, карт несколько", body: `{"toWardId":5,"amount":100}`, want: http.StatusBadRequest},
+		{name: "Карта другого пользователя", body: `{"toWardId":5,"cardId":5,"amount":100}`, want: http.StatusNotFound},
+		{name: "Отказ банка", body: `{"toWardId":5,"cardId":2,"amount":100}`, want: http.StatusPaymentRequired},
+		{name: "Недостаточно средств", body: `{"toWardId":5,"cardId":3,"amount":100}`,
+			want: http.StatusPaymentRequired},
+		{name: "Провайдер не ответил", body: `{"toWardId":5,"cardId":4,"amount":100}`,
+			want: http.StatusGatewayTimeout},
+		{name: "Три знака после точки", body: `{"toWardId":5,"cardId":1,"amount":100.505}`,
+			want: http.StatusBadRequest},
+		{name: "Успешный платеж", body: `{"toWardId":5,"cardId":1,"amount":100.5}`, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("code = %d, want %d, body = %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	// Отклоненные платежи не создают пожертвований
+	if got := db.donationCount(); got != 1 {
+		t.Errorf("создано пожертвований: %d, want 1", got)
+	}
+
+	rec := serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token,
+		`{"toWardId":5,"cardId":1,"amount":20}`)
+	response := new(PaymentResponse)
+	if err = json.NewDecoder(rec.Body).Decode(response); err != nil {
+		t.Fatal(err)
+	}
+
+	transaction, err := route.provider.Status(context.Background(), response.TransactionId)
+	if err != nil || transaction.Status != payment.StatusCaptured || transaction.Captured != 2000 {
+		t.Errorf("транзакция %q: %+v, %v", response.TransactionId, transaction, err)
+	}
+}
+
+func TestPaymentExactAmounts(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000, UpdatedAt: "0"})
+	db.addCard(&DatabaseServicev1.Card{Id: 1, Number: "4111111111111111", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, body := range []string{`{"toWardId":5,"amount":0.1}`, `{"toWardId":5,"amount":"0.2"}`} {
+		rec := serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token, body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: code = %d, body = %s", body, rec.Code, rec.Body)
+		}
+	}
+
+	rec := serveWith(route, http.MethodGet, "/api/v1/wards/5", "", "")
+	ward := map[string]any{}
+	if err := json.NewDecoder(rec.Body).Decode(&ward); err != nil {
+		t.Fatal(err)
+	}
+	if ward["collected"] != "0.30" || ward["necessary"] != "1000.00" || ward["currency"] != "RUB" {
+		t.Errorf("подопечный: %v", ward)
+	}
+}
+
+func TestPaymentInexactCollected(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 500000, Collected: 131000})
+	db.addCard(&DatabaseServicev1.Card{Id: 1, Number: "4111111111111111", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 131100.01 не представимо во float32 без потери копеек
+	rec := serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token, `{"toWardId":5,"amount":"100.01"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("code = %d, body = %s", rec.Code, rec.Body)
+	}
+	if db.donationCount() != 0 || db.ward(5).GetCollected() != 131000 {
+		t.Errorf("пожертвование записано: %d, собрано %v", db.donationCount(), db.ward(5).GetCollected())
+	}
+
+	// Сумма, которая остается точной, принимается
+	rec = serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token, `{"toWardId":5,"amount":"100"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d, body = %s", rec.Code, rec.Body)
+	}
+}
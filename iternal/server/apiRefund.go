@@ -0,0 +1,203 @@
+package server
+
+import (
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/money"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/saga"
+	"apiGateway/pkg/token"
+	"apiGateway/pkg/utilities"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RefundRequest - возврат пожертвования
+type RefundRequest struct {
+	Amount money.Amount `json:"amount" swaggertype:"string" example:"100.50"` // Сумма возврата, 0 - весь остаток платежа
+	Reason string       `json:"reason"`
+}
+
+// RefundResponse - возврат платежа
+type RefundResponse struct {
+	Id            string       `json:"id"`
+	PaymentId     string       `json:"paymentId,omitempty"`
+	DonationId    uint64       `json:"donationId,omitempty"`
+	Amount        money.Amount `json:"amount" swaggertype:"string" example:"100.50"`
+	Status        string       `json:"status"` // pending, succeeded или failed
+	Reason        string       `json:"reason,omitempty"`
+	FailureReason string       `json:"failureReason,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt"`
+}
+
+// RefundDonation godoc
+// @Summary      Пожертвования
+// @Description  Возвращает на карту всю сумму пожертвования или ее часть и уменьшает собранную сумму подопечного.
+// @Description  Владелец может вернуть платеж в течение refund_window, администратор - без ограничения срока
+// @Tags         Payments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        donationId path int true "ID пожертвования"
+// @Param        refund body RefundRequest false "Сумма и причина возврата"
+// @Param        Idempotency-Key header string false "Ключ идемпотентности, повтор с тем же ключом возвращает ответ на первый запрос"
+// @Success      200  {object}  RefundResponse
+// @Success      202  {object}  RefundResponse  "Деньги возвращены, возврат будет завершен при восстановлении саги"
+// @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
+// @Failure      422  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Failure      502  {object}  HTTPError
+// @Router       /api/v1/payment/{donationId}/refund [post]
+func (route *Router) RefundDonation(w http.ResponseWriter, r *http.Request) {
+	request := new(RefundRequest)
+	user := r.Context().Value("user").(token.IUser)
+	donationId := utilities.StrToUint(mux.Vars(r)["donationId"])
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil && !errors.Is(err, io.EOF) {
+		setDecodeError(w, err)
+		return
+	}
+
+	if request.Amount < 0 {
+		SetHTTPError(w, "Сумма возврата не может быть меньше 0", http.StatusBadRequest)
+		return
+	}
+
+	p, err := route.ledger.FindByDonation(r.Context(), donationId)
+	if errors.Is(err, payment.ErrPaymentNotFound) {
+		SetHTTPError(w, "Платеж по пожертвованию не найден", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Error("Ошибка при поиске платежа: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	if !route.allowOwner(w, r, p.UserId) {
+		return
+	}
+
+	if !privileged(user) && time.Since(p.CreatedAt) > route.cfg.Payment.RefundWindow {
+		SetHTTPError(w, "Срок возврата платежа истек, обратитесь к администратору", http.StatusForbidden)
+		return
+	}
+
+	amount := request.Amount
+	if amount == 0 {
+		amount = refundable(&p)
+	}
+	if amount <= 0 {
+		SetHTTPError(w, "Платеж уже возвращен", http.StatusConflict)
+		return
+	}
+
+	refundId, err := payment.NewId()
+	if err != nil {
+		logger.Error("Ошибка при создании ID возврата: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	// Сага выполняется до конца и после отмены запроса клиентом, иначе откат прервется на середине
+	ctx := context.WithoutCancel(r.Context())
+
+	err = route.refunds.Run(ctx, &refundData{
+		PaymentId:     p.Id,
+		RefundId:      refundId,
+		WardId:        p.WardId,
+		TransactionId: p.TransactionId,
+		Amount:        amount,
+		Reason:        request.Reason,
+		CreatedBy:     user.GetUserId(),
+	})
+	if err == nil || errors.Is(err, saga.ErrRecoveryPending) {
+		// Деньги уже возвращены: повтор с тем же ключом идемпотентности получит этот ответ, а не новый возврат
+		commitIdempotent(w)
+	}
+
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, saga.ErrRecoveryPending):
+		// Деньги уже возвращены провайдером, возврат будет отмечен выполненным при восстановлении
+		logger.Error("Возврат %s платежа %s выполнен у провайдера, но не завершен: %v", refundId, p.Id, err)
+		status = http.StatusAccepted
+	case errors.Is(err, saga.ErrCompensationFailed):
+		logger.Error("Возврат %s платежа %s не завершен и будет отменен при восстановлении: %v", refundId, p.Id, err)
+		SetHTTPError(w, "Ошибка на стороне сервера, возврат будет отменен", http.StatusInternalServerError)
+		return
+	case errors.Is(err, errNotRefundable):
+		SetHTTPError(w, "Возврат доступен только для успешного платежа", http.StatusConflict)
+		return
+	case errors.Is(err, errRefundExceeded):
+		SetHTTPError(w, "Сумма возврата превышает остаток платежа", http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		logger.Error("Ошибка при возврате платежа %s: %v", p.Id, err)
+		route.setRefundFailureReason(ctx, p.Id, refundId, err)
+		setPaymentError(w, err)
+		return
+	}
+
+	p, err = route.ledger.Get(ctx, p.Id)
+	if err != nil {
+		logger.Error("Ошибка при чтении платежа: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	refund, err := p.Refund(refundId)
+	if err != nil {
+		logger.Error("Возврат %s не найден в платеже %s", refundId, p.Id)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	response := newRefundResponse(*refund)
+	response.PaymentId = p.Id
+	response.DonationId = p.DonationId
+
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+	}
+
+	str := utilities.ToJSON(response)
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// newRefundResponse - ответ с возвратом из реестра платежей
+func newRefundResponse(refund payment.Refund) RefundResponse {
+	return RefundResponse{
+		Id:            refund.Id,
+		Amount:        refund.Amount,
+		Status:        string(refund.Status),
+		Reason:        refund.Reason,
+		FailureReason: refund.FailureReason,
+		CreatedAt:     refund.CreatedAt,
+	}
+}
+
+// setRefundFailureReason - сохраняет причину отказа в возврате, отмененном сагой
+func (route *Router) setRefundFailureReason(ctx context.Context, paymentId, refundId string, reason error) {
+	_, err := route.ledger.Update(ctx, paymentId, func(p *payment.Payment) error {
+		refund, err := p.Refund(refundId)
+		if err != nil {
+			return err
+		}
+		refund.FailureReason = reason.Error()
+		return nil
+	})
+	if err != nil && !errors.Is(err, payment.ErrRefundNotFound) {
+		logger.Error("Ошибка при сохранении причины отказа возврата %s: %v", refundId, err)
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/saga"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRefundDonation(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79990000001", Role: RoleUser}
+	other := &DatabaseServicev1.CreateUserResponse{Id: 2, Phone: "+79990000002", Role: RoleUser}
+	admin := &DatabaseServicev1.CreateUserResponse{Id: 3, Phone: "+79990000003", Role: RoleAdmin}
+	db := newFakeDatabase(user, other, admin)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000, UpdatedAt: "0"})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: "4111111111111111", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	bearer := func(u *DatabaseServicev1.CreateUserResponse) string {
+		tokens, err := route.openSession(context.Background(), u, "", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + tokens.Token
+	}
+	userAuth, otherAuth, adminAuth := bearer(user), bearer(other), bearer(admin)
+
+	rec := serveWith(route, http.MethodPost, "/api/v1/payment", userAuth, `{"toWardId":5,"amount":100}`)
+	paid := PaymentResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&paid); err != nil {
+		t.Fatal(err)
+	}
+	refundPath := fmt.Sprintf("/api/v1/payment/%d/refund", paid.DonationId)
+
+	tests := []struct {
+		name          string
+		authorization string
+		body          string
+		want          int
+	}{
+		{name: "Чужое пожертвование", authorization: otherAuth, body: `{"amount":10}`, want: http.StatusForbidden},
+		{name: "Сумма больше платежа", authorization: userAuth, body: `{"amount":150}`,
+			want: http.StatusUnprocessableEntity},
+		{name: "Частичный возврат", authorization: userAuth, body: `{"amount":30.5,"reason":"ошибка"}`,
+			want: http.StatusOK},
+		{name: "Возврат остатка администратором", authorization: adminAuth, body: ``, want: http.StatusOK},
+		{name: "Повторный возврат", authorization: adminAuth, body: `{"amount":1}`, want: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveWith(route, http.MethodPost, refundPath, tt.authorization, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("code = %d, want %d, body = %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	if got := db.ward(5).GetCollected(); got != 0 {
+		t.Errorf("собрано после полного возврата: %v, want 0", got)
+	}
+
+	transaction, err := route.provider.Status(context.Background(), paid.TransactionId)
+	if err != nil || transaction.Status != payment.StatusRefunded || transaction.Refunded != 10000 {
+		t.Errorf("транзакция: %+v, %v", transaction, err)
+	}
+
+	rec = serveWith(route, http.MethodGet, fmt.Sprintf("/api/v1/donations/%d", paid.DonationId), userAuth, "")
+	donation := DonationResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&donation); err != nil {
+		t.Fatal(err)
+	}
+	if donation.Refund == nil || donation.Refund.Status != "refunded" || donation.Refund.Refunded != 10000 ||
+		len(donation.Refund.Refunds) != 2 {
+		t.Errorf("возврат пожертвования: %+v", donation.Refund)
+	}
+
+	// По истечении refund_window владелец не может вернуть платеж
+	route.cfg.Payment.RefundWindow = time.Nanosecond
+	rec = serveWith(route, http.MethodPost, "/api/v1/payment", userAuth, `{"toWardId":5,"amount":50}`)
+	if err := json.NewDecoder(rec.Body).Decode(&paid); err != nil {
+		t.Fatal(err)
+	}
+	rec = serveWith(route, http.MethodPost, fmt.Sprintf("/api/v1/payment/%d/refund", paid.DonationId), userAuth, ``)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("возврат после срока: code = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestRefundCompletedOnRecover(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79990000001", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000, UpdatedAt: "0"})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: "4111111111111111", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer := "Bearer " + tokens.Token
+
+	rec := serveWith(route, http.MethodPost, "/api/v1/payment", bearer, `{"toWardId":5,"amount":100}`)
+	paid := PaymentResponse{}
+	if err = json.NewDecoder(rec.Body).Decode(&paid); err != nil {
+		t.Fatal(err)
+	}
+
+	// Возврат выполнен провайдером, но реестр не удалось обновить
+	ledger := route.ledger
+	route.ledger = failingLedger{Ledger: ledger, fail: func(p *payment.Payment) bool {
+		return p.Refunded > 0
+	}}
+
+	rec = serveWith(route, http.MethodPost, fmt.Sprintf("/api/v1/payment/%d/refund", paid.DonationId), bearer, ``)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("code = %d, want %d, body = %s", rec.Code, http.StatusAccepted, rec.Body)
+	}
+
+	// Необратимый возврат не откатывается: собранная сумма остается уменьшенной
+	if got := db.ward(5).GetCollected(); got != 0 {
+		t.Errorf("собрано: %v, want 0", got)
+	}
+	transaction, err := route.provider.Status(context.Background(), paid.TransactionId)
+	if err != nil || transaction.Status != payment.StatusRefunded {
+		t.Errorf("транзакция: %+v, %v", transaction, err)
+	}
+
+	// Восстановление завершает возврат
+	route.ledger = ledger
+	if err = route.refunds.Recover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ledger.Get(context.Background(), paid.PaymentId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != payment.PaymentRefunded || len(p.Refunds) != 1 || p.Refunds[0].Status != payment.RefundSucceeded {
+		t.Errorf("платеж после восстановления: %+v", p)
+	}
+	if got := db.ward(5).GetCollected(); got != 0 {
+		t.Errorf("собрано после восстановления: %v, want 0", got)
+	}
+}
+
+func TestRefundRecoverInDoubtProviderRefund(t *testing.T) {
+	ctx := context.Background()
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79990000001", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000, UpdatedAt: "0"})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: "4111111111111111", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	journal := saga.NewMemoryJournal()
+	route.refunds = route.newRefundSaga(journal)
+
+	tokens, err := route.openSession(ctx, user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token,
+		`{"toWardId":5,"amount":100}`)
+	paid := PaymentResponse{}
+	if err = json.NewDecoder(rec.Body).Decode(&paid); err != nil {
+		t.Fatal(err)
+	}
+
+	// Шлюз остановился во время возврата у провайдера: деньги могли уже вернуться на карту
+	data := refundData{PaymentId: paid.PaymentId, RefundId: "refund1", WardId: 5,
+		TransactionId: paid.TransactionId, Amount: 10000, CreatedBy: 1}
+	if err = route.reserveRefundStep(ctx, &data); err != nil {
+		t.Fatal(err)
+	}
+	if err = route.decreaseWardStep(ctx, &data); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(&data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = journal.Save(ctx, saga.Record{Id: "saga1", Name: refundSagaName, State: saga.StateRunning, Step: 2,
+		InDoubt: true, Data: raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = route.refunds.Recover(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Сага не откатывается: сумма подопечного не возвращается, возврат не отмечается неуспешным
+	if got := db.ward(5).GetCollected(); got != 0 {
+		t.Errorf("собрано: %v, want 0", got)
+	}
+	p, err := route.ledger.Get(ctx, paid.PaymentId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refund, err := p.Refund("refund1")
+	if err != nil || refund.Status != payment.RefundPending {
+		t.Errorf("возврат: %+v, %v", refund, err)
+	}
+	records, _ := journal.List(ctx)
+	if len(records) != 1 || records[0].State != saga.StateBlocked {
+		t.Errorf("журнал после восстановления: %+v", records)
+	}
+}
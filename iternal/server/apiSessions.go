@@ -0,0 +1,129 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/token"
+	"apiGateway/pkg/utilities"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+type SessionResponse struct {
+	Id        uint64 `json:"id"`
+	UserAgent string `json:"userAgent"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+	Current   bool   `json:"current"` // Сессия, в рамках которой выполнен запрос
+}
+
+type SessionsResponse struct {
+	Sessions []*SessionResponse `json:"sessions"`
+}
+
+// Sessions godoc
+// @Summary      Список сессий пользователя
+// @Description  Устройства, на которых выполнен вход в аккаунт текущего пользователя. DatabaseService ищет по пользователю
+// @Description  только одну сессию, поэтому список содержит ее и текущую сессию, остальные устройства не показываются
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  SessionsResponse
+// @Failure      401  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/sessions [get]
+func (route *Router) Sessions(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(token.IUser)
+
+	current, err := route.databaseService.FindSessionsById(r.Context(),
+		&DatabaseServicev1.FindSessionsByIdRequest{Id: user.GetSessionId()})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	response := &SessionsResponse{Sessions: []*SessionResponse{{
+		Id:        current.GetId(),
+		UserAgent: decodeSessionRecord(current.GetRefreshToken()).UserAgent,
+		CreatedAt: current.GetCreatedAt(),
+		UpdatedAt: current.GetUpdatedAt(),
+		Current:   true,
+	}}}
+
+	session, err := route.databaseService.FindSessionsByUserId(r.Context(),
+		&DatabaseServicev1.FindSessionsByUserIdRequest{UserId: user.GetUserId()})
+	if err != nil && status.Code(err) != codes.NotFound {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	if err == nil && session.GetUserId() == user.GetUserId() && session.GetId() != current.GetId() {
+		response.Sessions = append(response.Sessions, &SessionResponse{
+			Id:        session.GetId(),
+			UserAgent: decodeSessionRecord(session.GetRefreshToken()).UserAgent,
+			CreatedAt: session.GetCreatedAt(),
+			UpdatedAt: session.GetUpdatedAt(),
+		})
+	}
+
+	str := utilities.ToJSON(response)
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// DeleteSession godoc
+// @Summary      Завершение сессии
+// @Description  Удаленное завершение сессии текущего пользователя по ID (например, с утерянного устройства)
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "ID сессии"
+// @Success      200  {object}  DatabaseServicev1.HTTPCodes
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/sessions/{id} [delete]
+func (route *Router) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(token.IUser)
+	id := utilities.StrToUint(mux.Vars(r)["id"])
+
+	if id <= 0 {
+		SetHTTPError(w, "Поле \"ID\" не может быть меньше или равно 0", http.StatusBadRequest)
+		return
+	}
+
+	session, err := route.databaseService.FindSessionsById(r.Context(), &DatabaseServicev1.FindSessionsByIdRequest{Id: id})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	// Чужие сессии не раскрываем, отвечаем так же, как на отсутствующую
+	if session.GetUserId() != user.GetUserId() {
+		SetHTTPError(w, "Сессия не найдена", http.StatusNotFound)
+		return
+	}
+
+	response, err := route.databaseService.DeleteSessionById(r.Context(), &DatabaseServicev1.DeleteSessionByIdRequest{Id: id})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	str := utilities.ToJSON(response)
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
@@ -0,0 +1,380 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/money"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/subscription"
+	"apiGateway/pkg/token"
+	"apiGateway/pkg/utilities"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"time"
+)
+
+// SubscriptionRequest - создание или изменение ежемесячного пожертвования
+type SubscriptionRequest struct {
+	WardId     uint64       `json:"wardId"` // Только при создании
+	CardId     uint64       `json:"cardId"` // Карта пользователя, можно не указывать, если карта одна
+	Amount     money.Amount `json:"amount" swaggertype:"string" example:"100.50"`
+	DayOfMonth int          `json:"dayOfMonth"` // 1-31, в коротких месяцах платеж выполняется в последний день
+	Status     string       `json:"status"`     // Только при изменении: active - возобновить, paused - приостановить
+}
+
+// SubscriptionResponse - ежемесячное пожертвование
+type SubscriptionResponse struct {
+	Id     string `json:"id"`
+	UserId uint64 `json:"userId"`
+	WardId uint64 `json:"wardId"`
+	CardId uint64 `json:"cardId"`
+	money.Money
+	DayOfMonth    int        `json:"dayOfMonth"`
+	Status        string     `json:"status"`
+	PauseReason   string     `json:"pauseReason,omitempty"`
+	NextRun       time.Time  `json:"nextRun"`           // Плановое время следующего платежа
+	RetryAt       *time.Time `json:"retryAt,omitempty"` // Время повтора после отказа
+	LastRunAt     *time.Time `json:"lastRunAt,omitempty"`
+	LastPaymentId string     `json:"lastPaymentId,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// SubscriptionsResponse - список ежемесячных пожертвований
+type SubscriptionsResponse struct {
+	Subscriptions []*SubscriptionResponse `json:"subscriptions"`
+}
+
+// Subscriptions godoc
+// @Summary      Ежемесячные пожертвования
+// @Description  Список ежемесячных пожертвований пользователя, администратору - всех пользователей
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  SubscriptionsResponse
+// @Failure      401  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/subscriptions [get]
+func (route *Router) Subscriptions(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(token.IUser)
+
+	list, err := route.subscriptions.List(r.Context())
+	if err != nil {
+		logger.Error("Ошибка при чтении подписок: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	response := &SubscriptionsResponse{Subscriptions: make([]*SubscriptionResponse, 0, len(list))}
+	for _, s := range list {
+		if isOwner(user, s.UserId) {
+			response.Subscriptions = append(response.Subscriptions, route.newSubscriptionResponse(s))
+		}
+	}
+
+	str := utilities.ToJSON(response)
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// CreateSubscription godoc
+// @Summary      Ежемесячные пожертвования
+// @Description  Создает ежемесячное пожертвование подопечному. Первый платеж выполняется в ближайший день dayOfMonth
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        subscription body SubscriptionRequest true "Подопечный, сумма, день месяца и карта"
+// @Success      200  {object}  SubscriptionResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/subscriptions [post]
+func (route *Router) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	request := new(SubscriptionRequest)
+	userId := r.Context().Value("user").(token.IUser).GetUserId()
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		setDecodeError(w, err)
+		return
+	}
+
+	if request.WardId <= 0 {
+		SetHTTPError(w, "Поле \"wardId\" не может быть меньше или равно 0", http.StatusBadRequest)
+		return
+	}
+
+	if !validSubscription(w, request) {
+		return
+	}
+
+	_, err := route.databaseService.FindWardById(r.Context(), &DatabaseServicev1.FindWardByIdRequest{Id: request.WardId})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	cardId, ok := route.subscriptionCard(w, r, userId, request.CardId)
+	if !ok {
+		return
+	}
+
+	id, err := payment.NewId()
+	if err != nil {
+		logger.Error("Ошибка при создании ID подписки: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	s := subscription.Subscription{
+		Id:      id,
+		UserId:  userId,
+		WardId:  request.WardId,
+		CardId:  cardId,
+		Amount:  request.Amount,
+		Day:     request.DayOfMonth,
+		Status:  subscription.StatusActive,
+		NextRun: subscription.NextRun(time.Now(), request.DayOfMonth),
+	}
+
+	if err = route.subscriptions.Create(r.Context(), s); err != nil {
+		logger.Error("Ошибка при сохранении подписки: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	s, err = route.subscriptions.Get(r.Context(), id)
+	if err != nil {
+		logger.Error("Ошибка при чтении подписки: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	str := utilities.ToJSON(route.newSubscriptionResponse(s))
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// Subscription godoc
+// @Summary      Ежемесячные пожертвования
+// @Description  Ежемесячное пожертвование по ID, доступно владельцу и администратору
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID подписки"
+// @Success      200  {object}  SubscriptionResponse
+// @Failure      403  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Router       /api/v1/subscriptions/{id} [get]
+func (route *Router) Subscription(w http.ResponseWriter, r *http.Request) {
+	s, err := route.subscriptions.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		setSubscriptionError(w, err)
+		return
+	}
+
+	str := utilities.ToJSON(route.newSubscriptionResponse(s))
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// UpdateSubscription godoc
+// @Summary      Ежемесячные пожертвования
+// @Description  Изменяет сумму, день месяца или карту ежемесячного пожертвования, приостанавливает или возобновляет его.
+// @Description  После изменения дня или возобновления следующий платеж выполняется в ближайший день dayOfMonth,
+// @Description  платежи за время паузы не выполняются
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID подписки"
+// @Param        subscription body SubscriptionRequest true "Изменяемые поля, незаполненные поля не меняются"
+// @Success      200  {object}  SubscriptionResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/subscriptions/{id} [put]
+func (route *Router) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	request := new(SubscriptionRequest)
+	id := mux.Vars(r)["id"]
+
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		setDecodeError(w, err)
+		return
+	}
+
+	current, err := route.subscriptions.Get(r.Context(), id)
+	if err != nil {
+		setSubscriptionError(w, err)
+		return
+	}
+
+	if request.Amount == 0 {
+		request.Amount = current.Amount
+	}
+	if request.DayOfMonth == 0 {
+		request.DayOfMonth = current.Day
+	}
+	if !validSubscription(w, request) {
+		return
+	}
+
+	switch subscription.Status(request.Status) {
+	case "", subscription.StatusActive, subscription.StatusPaused:
+	default:
+		SetHTTPError(w, "Поле \"status\" может принимать значения active или paused", http.StatusBadRequest)
+		return
+	}
+
+	cardId := current.CardId
+	if request.CardId != 0 {
+		var ok bool
+		if cardId, ok = route.subscriptionCard(w, r, current.UserId, request.CardId); !ok {
+			return
+		}
+	}
+
+	s, err := route.subscriptions.Update(r.Context(), id, func(s *subscription.Subscription) error {
+		reschedule := request.DayOfMonth != s.Day
+
+		s.Amount = request.Amount
+		s.Day = request.DayOfMonth
+		s.CardId = cardId
+
+		switch subscription.Status(request.Status) {
+		case subscription.StatusPaused:
+			if s.Status != subscription.StatusPaused {
+				s.Status = subscription.StatusPaused
+				s.PauseReason = "приостановлена пользователем"
+			}
+		case subscription.StatusActive:
+			if s.Status == subscription.StatusPaused {
+				s.Status = subscription.StatusActive
+				s.PauseReason = ""
+				reschedule = true
+			}
+		}
+
+		if reschedule {
+			s.NextRun = subscription.NextRun(time.Now(), s.Day)
+			s.Attempts = 0
+			s.RetryAt = time.Time{}
+		}
+
+		return nil
+	})
+	if err != nil {
+		setSubscriptionError(w, err)
+		return
+	}
+
+	str := utilities.ToJSON(route.newSubscriptionResponse(s))
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// DeleteSubscription godoc
+// @Summary      Ежемесячные пожертвования
+// @Description  Удаляет ежемесячное пожертвование, выполненные платежи не возвращаются
+// @Tags         Subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID подписки"
+// @Success      200  {object}  DatabaseServicev1.HTTPCodes
+// @Failure      403  {object}  HTTPError
+// @Failure      404  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/subscriptions/{id} [delete]
+func (route *Router) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	if err := route.subscriptions.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+		setSubscriptionError(w, err)
+		return
+	}
+
+	str := utilities.ToJSON(&DatabaseServicev1.HTTPCodes{Code: http.StatusOK})
+	_, err := w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
+
+// validSubscription - проверяет сумму и день месяца, при ошибке формирует ответ 400
+func validSubscription(w http.ResponseWriter, request *SubscriptionRequest) bool {
+	if request.Amount <= 0 {
+		SetHTTPError(w, "Сумма пожертвования не может быть меньше или равно 0", http.StatusBadRequest)
+		return false
+	}
+
+	if request.DayOfMonth < 1 || request.DayOfMonth > 31 {
+		SetHTTPError(w, "Поле \"dayOfMonth\" должно быть от 1 до 31", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// subscriptionCard - карта пользователя userId для подписки, при ошибке формирует ответ
+func (route *Router) subscriptionCard(w http.ResponseWriter, r *http.Request, userId, cardId uint64) (uint64, bool) {
+	cards, err := route.databaseService.FindUserCard(r.Context(), &DatabaseServicev1.FindUserCardRequest{Id: userId})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return 0, false
+	}
+
+	card, ok := selectCard(cards.GetCards(), cardId)
+	if !ok {
+		if cardId == 0 {
+			SetHTTPError(w, "Необходимо указать карту для оплаты", http.StatusBadRequest)
+			return 0, false
+		}
+		SetHTTPError(w, "Карта не найдена", http.StatusNotFound)
+		return 0, false
+	}
+
+	return card.GetId(), true
+}
+
+// newSubscriptionResponse - ответ с подпиской, сумма в валюте платежей шлюза
+func (route *Router) newSubscriptionResponse(s subscription.Subscription) *SubscriptionResponse {
+	return &SubscriptionResponse{
+		Id:            s.Id,
+		UserId:        s.UserId,
+		WardId:        s.WardId,
+		CardId:        s.CardId,
+		Money:         money.New(s.Amount, route.cfg.Payment.Currency),
+		DayOfMonth:    s.Day,
+		Status:        string(s.Status),
+		PauseReason:   s.PauseReason,
+		NextRun:       s.NextRun,
+		RetryAt:       optionalTime(s.RetryAt),
+		LastRunAt:     optionalTime(s.LastRunAt),
+		LastPaymentId: s.LastPaymentId,
+		LastError:     s.LastError,
+		CreatedAt:     s.CreatedAt,
+	}
+}
+
+// setSubscriptionError - формирует ответ на ошибку хранилища подписок
+func setSubscriptionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, subscription.ErrNotFound) {
+		SetHTTPError(w, "Подписка не найдена", http.StatusNotFound)
+		return
+	}
+
+	logger.Error("Ошибка при работе с подписками: %v", err)
+	SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+}
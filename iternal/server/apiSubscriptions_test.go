@@ -0,0 +1,101 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/subscription"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79990000001", Role: RoleUser}
+	other := &DatabaseServicev1.CreateUserResponse{Id: 2, Phone: "+79990000002", Role: RoleUser}
+	db := newFakeDatabase(user, other)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 100, UpdatedAt: "0"})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: "4111111111111111", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	bearer := func(u *DatabaseServicev1.CreateUserResponse) string {
+		tokens, err := route.openSession(ctx, u, "", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + tokens.Token
+	}
+	userAuth, otherAuth := bearer(user), bearer(other)
+
+	for body, want := range map[string]int{
+		`{"wardId":5,"amount":100,"dayOfMonth":32}`: http.StatusBadRequest,
+		`{"wardId":5,"amount":0,"dayOfMonth":10}`:   http.StatusBadRequest,
+		`{"wardId":9,"amount":100,"dayOfMonth":10}`: http.StatusNotFound,
+	} {
+		if rec := serveWith(route, http.MethodPost, "/api/v1/subscriptions", userAuth, body); rec.Code != want {
+			t.Errorf("%s: code = %d, want %d, body = %s", body, rec.Code, want, rec.Body)
+		}
+	}
+
+	rec := serveWith(route, http.MethodPost, "/api/v1/subscriptions", userAuth,
+		`{"wardId":5,"amount":100,"dayOfMonth":31}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d, body = %s", rec.Code, rec.Body)
+	}
+	created := SubscriptionResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.CardId != 7 || created.Status != string(subscription.StatusActive) {
+		t.Errorf("подписка: %+v", created)
+	}
+
+	if rec := serveWith(route, http.MethodGet, "/api/v1/subscriptions/"+created.Id, otherAuth, ""); rec.Code !=
+		http.StatusForbidden {
+		t.Errorf("чужая подписка: code = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	// Первый платеж пополняет сбор, второй приостанавливает подписку: подопечный собрал нужную сумму
+	now := created.NextRun
+	route.scheduler.SetClock(func() time.Time { return now })
+	for i := 0; i < 2; i++ {
+		if err := route.scheduler.Tick(ctx); err != nil {
+			t.Fatal(err)
+		}
+		now = now.AddDate(0, 1, 0)
+	}
+
+	if got := db.ward(5).GetCollected(); got != 100 {
+		t.Errorf("собрано: %v, want 100", got)
+	}
+	if got := db.donationCount(); got != 1 {
+		t.Errorf("пожертвований: %d, want 1", got)
+	}
+
+	rec = serveWith(route, http.MethodGet, "/api/v1/subscriptions/"+created.Id, userAuth, "")
+	paused := SubscriptionResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&paused); err != nil {
+		t.Fatal(err)
+	}
+	if paused.Status != string(subscription.StatusPaused) || paused.LastPaymentId == "" {
+		t.Errorf("подписка после сбора: %+v", paused)
+	}
+
+	rec = serveWith(route, http.MethodPut, "/api/v1/subscriptions/"+created.Id, userAuth, `{"status":"active"}`)
+	resumed := SubscriptionResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&resumed); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Status != string(subscription.StatusActive) || resumed.PauseReason != "" {
+		t.Errorf("возобновленная подписка: %+v", resumed)
+	}
+
+	if rec := serveWith(route, http.MethodDelete, "/api/v1/subscriptions/"+created.Id, userAuth, ""); rec.Code !=
+		http.StatusOK {
+		t.Errorf("удаление: code = %d, body = %s", rec.Code, rec.Body)
+	}
+	if list, _ := route.subscriptions.List(ctx); len(list) != 0 {
+		t.Errorf("подписок после удаления: %d, want 0", len(list))
+	}
+}
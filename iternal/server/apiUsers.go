@@ -2,11 +2,16 @@ package server
 
 import (
 	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/bankcard"
 	"apiGateway/pkg/logger"
+	"apiGateway/pkg/token"
 	"apiGateway/pkg/utilities"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"io"
 	"net/http"
 	"path/filepath"
@@ -20,22 +25,30 @@ import (
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {object}  DatabaseServicev1.UsersResponse
+// @Param        limit   query     int     false  "Размер страницы"
+// @Param        cursor  query     string  false  "Курсор следующей страницы из заголовка Link"
+// @Param        sort    query     string  false  "Сортировка: id, email, username, role, type, createdAt, updatedAt, \"-\" перед полем - по убыванию"  default(id)
+// @Param        filter  query     string  false  "Фильтр поле:значение через запятую, поля: email, username, phone, role, type"
+// @Success      200  {object}  UsersResponse
+// @Header       200  {string}   Link           "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+// @Header       200  {integer}  X-Total-Count  "Количество элементов с учетом фильтра"
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users [get]
-func (route Router) GetUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := route.databaseService.Users(r.Context(), nil)
-	if err != nil {
-		logger.Error("Ошибка при выполнении запроса: %v", err)
-		SetGRPCError(w, err)
+func (route *Router) GetUsers(w http.ResponseWriter, r *http.Request) {
+	users, ok := listPage(route, w, r, usersList, func(ctx context.Context) ([]*DatabaseServicev1.CreateUserResponse, error) {
+		response, err := route.databaseService.Users(ctx, nil)
+		return response.GetUsers(), err
+	})
+	if !ok {
 		return
 	}
 
-	str := utilities.ToJSON(users)
+	str := utilities.ToJSON(route.newUsersResponse(r.Context(), users))
 
-	_, err = w.Write([]byte(str))
+	_, err := w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
@@ -47,13 +60,16 @@ func (route Router) GetUsers(w http.ResponseWriter, r *http.Request) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id   path      int  true  "User ID"
-// @Success      200  {object}  DatabaseServicev1.CreateUserResponse
+// @Success      200  {object}  UserResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id} [get]
-func (route Router) GetUser(w http.ResponseWriter, r *http.Request) {
+func (route *Router) GetUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 	user, err := route.databaseService.FindUserById(r.Context(), &DatabaseServicev1.FindUserByIdRequest{Id: id})
@@ -63,7 +79,7 @@ func (route Router) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(user)
+	str := utilities.ToJSON(route.newUserResponse(r.Context(), user, user.GetDonations()))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -77,14 +93,18 @@ func (route Router) GetUser(w http.ResponseWriter, r *http.Request) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id path int true "ID пользователя"
 // @Param        user body DatabaseServicev1.UpdateUserRequest true "Модель для обновления"
-// @Success      200  {object}  DatabaseServicev1.CreateUserResponse
+// @Success      200  {object}  UserResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id} [put]
-func (route Router) UpdateUser(w http.ResponseWriter, r *http.Request) {
+func (route *Router) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id := utilities.StrToUint(mux.Vars(r)["id"])
 
 	updateUser := &DatabaseServicev1.UpdateUserRequest{}
@@ -97,6 +117,23 @@ func (route Router) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	updateUser.Id = id
 
+	// Роль пользователя может изменить только администратор
+	if r.Context().Value("user").(token.IUser).GetRole() != RoleAdmin {
+		current, err := route.databaseService.FindUserById(r.Context(), &DatabaseServicev1.FindUserByIdRequest{Id: id})
+		if err != nil {
+			logger.Error("Ошибка при выполнении запроса: %v", err)
+			SetGRPCError(w, err)
+			return
+		}
+
+		updateUser.Role = current.GetRole()
+	}
+
+	if err := route.sealUser(r.Context(), updateUser); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	user, err := route.databaseService.UpdateUser(r.Context(), updateUser)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -104,7 +141,7 @@ func (route Router) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(user)
+	str := utilities.ToJSON(route.newUserResponse(r.Context(), user, user.GetDonations()))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -120,12 +157,14 @@ func (route Router) UpdateUser(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        user body DatabaseServicev1.CreateUserRequest false "Сущность пользователя"
-// @Success      200  {object}  DatabaseServicev1.CreateUserResponse
+// @Success      200  {object}  UserResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users [post]
-func (route Router) CreateUser(w http.ResponseWriter, r *http.Request) {
+func (route *Router) CreateUser(w http.ResponseWriter, r *http.Request) {
 	newUser := new(DatabaseServicev1.CreateUserRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(newUser); err != nil {
@@ -133,6 +172,11 @@ func (route Router) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := route.sealNewUser(r.Context(), newUser); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	createdUser, err := route.databaseService.CreateUser(r.Context(), newUser)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -140,7 +184,7 @@ func (route Router) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(createdUser)
+	str := utilities.ToJSON(route.newUserResponse(r.Context(), createdUser, createdUser.GetDonations()))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -153,13 +197,16 @@ func (route Router) CreateUser(w http.ResponseWriter, r *http.Request) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id   path      int  true  "ID пользователя"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id} [delete]
-func (route Router) DeleteUserByID(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteUserByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -188,7 +235,7 @@ func (route Router) DeleteUserByID(w http.ResponseWriter, r *http.Request) {
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/isExists [post]
-func (route Router) UserIsExists(w http.ResponseWriter, r *http.Request) {
+func (route *Router) UserIsExists(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.UserIsExistsRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -224,13 +271,16 @@ func (route Router) UserIsExists(w http.ResponseWriter, r *http.Request) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        request body DatabaseServicev1.IsRoleRequest true "Request"
 // @Success      200  {object}  DatabaseServicev1.IsRoleResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/isRole [post]
-func (route Router) UserIsRole(w http.ResponseWriter, r *http.Request) {
+func (route *Router) UserIsRole(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.IsRoleRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -240,6 +290,10 @@ func (route Router) UserIsRole(w http.ResponseWriter, r *http.Request) {
 
 	logger.Info("request: %+v", request)
 
+	if !route.allowOwner(w, r, request.GetId()) {
+		return
+	}
+
 	response, err := route.databaseService.IsRole(r.Context(), request)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -266,13 +320,16 @@ func (route Router) UserIsRole(w http.ResponseWriter, r *http.Request) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        email query string true "Email" Format(email)
-// @Success      200  {object}  DatabaseServicev1.CreateUserResponse
+// @Success      200  {object}  UserResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/ [get]
-func (route Router) FindUserByEmail(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindUserByEmail(w http.ResponseWriter, r *http.Request) {
 	request := &DatabaseServicev1.FindUserByEmailRequest{Email: r.URL.Query().Get("email")}
 
 	if request.Email == "" || len(request.Email) == 0 {
@@ -288,7 +345,7 @@ func (route Router) FindUserByEmail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newUserResponse(r.Context(), response, response.GetDonations()))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -301,13 +358,17 @@ func (route Router) FindUserByEmail(w http.ResponseWriter, r *http.Request) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        request body DatabaseServicev1.ComparePasswordRequest true "Данные пользователя"
 // @Success      200  {object}  DatabaseServicev1.ComparePasswordResponse
 // @Failure      400  {object}  HTTPError
-// @Failure      404  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Header       429  {integer}  Retry-After  "Через сколько секунд разрешена следующая попытка"
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/comparePassword [post]
-func (route Router) ComparePassword(w http.ResponseWriter, r *http.Request) {
+func (route *Router) ComparePassword(w http.ResponseWriter, r *http.Request) {
 	request := &DatabaseServicev1.ComparePasswordRequest{}
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -315,6 +376,26 @@ func (route Router) ComparePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Ограничение проверяется до поиска пользователя, иначе перебором номеров можно узнать зарегистрированные
+	if !route.allowLoginAttempt(w, r, request.GetPhone()) {
+		return
+	}
+
+	user, err := route.databaseService.FindUserByPhone(r.Context(),
+		&DatabaseServicev1.FindUserByPhoneRequest{Phone: request.GetPhone()})
+	if err != nil && status.Code(err) != codes.NotFound {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	// Проверять можно только собственный пароль. Чужой и незарегистрированный номер не различаются,
+	// попытка учитывается для IP клиента
+	if !route.allowOwner(w, r, user.GetId()) {
+		route.ipAttemptFailed(r)
+		return
+	}
+
 	response, err := route.databaseService.ComparePassword(r.Context(), request)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -322,6 +403,12 @@ func (route Router) ComparePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if response.Accessory {
+		route.loginSucceeded(r, request.GetPhone())
+	} else {
+		route.loginFailed(r, request.GetPhone())
+	}
+
 	resp := struct {
 		Accessory bool `json:"accessory"`
 	}{
@@ -346,10 +433,11 @@ func (route Router) ComparePassword(w http.ResponseWriter, r *http.Request) {
 // @Param        type formData  uint64  true  "Type поле пользователя, 0 - юридическое лицо, 1 - физическое лицо"
 // @Success      200  {object}  DatabaseServicev1.ChangeUserTypeResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id} [patch]
-func (route Router) ChangeUserType(w http.ResponseWriter, r *http.Request) {
+func (route *Router) ChangeUserType(w http.ResponseWriter, r *http.Request) {
 	id := utilities.StrToUint(mux.Vars(r)["id"])
 
 	if err := r.ParseMultipartForm(1); err != nil {
@@ -402,13 +490,16 @@ func (route Router) ChangeUserType(w http.ResponseWriter, r *http.Request) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        phone query string true "Phone"
-// @Success      200  {object}  DatabaseServicev1.CreateUserResponse
+// @Success      200  {object}  UserResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/ [get]
-func (route Router) FindUserByPhone(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindUserByPhone(w http.ResponseWriter, r *http.Request) {
 	request := &DatabaseServicev1.FindUserByPhoneRequest{Phone: r.URL.Query().Get("phone")}
 
 	if request.Phone == "" || len(request.Phone) == 0 {
@@ -426,7 +517,7 @@ func (route Router) FindUserByPhone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newUserResponse(r.Context(), response, response.GetDonations()))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -441,12 +532,13 @@ func (route Router) FindUserByPhone(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "User ID"
-// @Success      200  {object}  DatabaseServicev1.Company
+// @Success      200  {object}  CompanyResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id}/company [get]
-func (route Router) FindUserCompany(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindUserCompany(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -464,7 +556,7 @@ func (route Router) FindUserCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCompanyResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -480,12 +572,13 @@ func (route Router) FindUserCompany(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "User ID"
-// @Success      200  {object}  DatabaseServicev1.FindUserDonationsResponse
+// @Success      200  {object}  DonationsResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id}/donation [get]
-func (route Router) FindUserDonations(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindUserDonations(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -503,7 +596,7 @@ func (route Router) FindUserDonations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newDonationsResponse(r.Context(), response.GetDonations()))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -519,12 +612,13 @@ func (route Router) FindUserDonations(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "User ID"
-// @Success      200  {object}  DatabaseServicev1.FindUserCardResponse
+// @Success      200  {object}  CardsResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id}/card [get]
-func (route Router) FindUserCard(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindUserCard(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -542,7 +636,7 @@ func (route Router) FindUserCard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newCardsResponse(r.Context(), response.GetCards()))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -558,12 +652,14 @@ func (route Router) FindUserCard(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        card body DatabaseServicev1.AddCardToUserRequest false "Сущность банковской карты"
-// @Success      200  {object}  DatabaseServicev1.CreateUserResponse
+// @Success      200  {object}  UserResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/addCard [post]
-func (route Router) AddCardToUser(w http.ResponseWriter, r *http.Request) {
+func (route *Router) AddCardToUser(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.AddCardToUserRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -571,6 +667,37 @@ func (route Router) AddCardToUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if request.GetCard() == nil {
+		SetHTTPError(w, "Поле \"Card\" не может быть пустым", http.StatusBadRequest)
+		return
+	}
+
+	if !route.allowOwner(w, r, request.GetCard().GetUserId()) {
+		return
+	}
+
+	input := bankcard.Input{Number: request.GetCard().GetNumber(), Date: request.GetCard().GetDate()}
+	if err := checkCard("card", input, true); err != nil {
+		setCardError(w, err)
+		return
+	}
+
+	duplicate, err := route.userHasCard(r.Context(), request.GetCard().GetUserId(), request.GetCard().GetNumber(), 0)
+	if err != nil {
+		logger.Error("Ошибка при проверке карт владельца: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+	if duplicate {
+		SetHTTPError(w, "Карта уже добавлена", http.StatusConflict)
+		return
+	}
+
+	if err := route.sealCard(r.Context(), &request.Card.Number, &request.Card.Cvv); err != nil {
+		setCardError(w, err)
+		return
+	}
+
 	response, err := route.databaseService.AddCardToUser(r.Context(), request)
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
@@ -578,7 +705,12 @@ func (route Router) AddCardToUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	var donations []*DatabaseServicev1.Donations
+	if response.GetDonations() != nil {
+		donations = append(donations, response.GetDonations())
+	}
+
+	str := utilities.ToJSON(route.newUserResponse(r.Context(), response, donations))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -595,10 +727,11 @@ func (route Router) AddCardToUser(w http.ResponseWriter, r *http.Request) {
 // @Param        user body DatabaseServicev1.DeleteUserByModelRequest false "Модель пользователя"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/deleteModel [post]
-func (route Router) DeleteUserByModel(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteUserByModel(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.DeleteUserByModelRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -632,7 +765,7 @@ func (route Router) DeleteUserByModel(w http.ResponseWriter, r *http.Request) {
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id}/photo [get]
-func (route Router) GetUserPhoto(w http.ResponseWriter, r *http.Request) {
+func (route *Router) GetUserPhoto(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -710,10 +843,11 @@ func (route Router) GetUserPhoto(w http.ResponseWriter, r *http.Request) {
 // @Param        id   path      int  true  "ID пользователя"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id}/photo [delete]
-func (route Router) DeleteUserPhoto(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteUserPhoto(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -750,10 +884,11 @@ func (route Router) DeleteUserPhoto(w http.ResponseWriter, r *http.Request) {
 // @Param 		 photo formData file true "Фото пользователя"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/users/{id}/photo [post]
-func (route Router) SetUserPhoto(w http.ResponseWriter, r *http.Request) {
+func (route *Router) SetUserPhoto(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 	const chunkSize = 1024 // Размер части в байтах
@@ -880,3 +1015,72 @@ func (route Router) SetUserPhoto(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(int(response.Code))
 }
+
+// UserResponse - пользователь без пароля с маскированными банковскими картами
+type UserResponse struct {
+	Id        uint64             `json:"id"`
+	Email     string             `json:"email,omitempty"`
+	Username  string             `json:"username,omitempty"`
+	Phone     string             `json:"phone,omitempty"`
+	Card      []CardResponse     `json:"card,omitempty"`
+	Role      string             `json:"role,omitempty"`
+	Company   *CompanyResponse   `json:"company,omitempty"`
+	Type      uint64             `json:"type"` // 0 - физическое лицо, 1 - юридическое лицо
+	Donations []DonationResponse `json:"donations,omitempty"`
+	CreatedAt string             `json:"createdAt,omitempty"`
+	UpdatedAt string             `json:"updatedAt,omitempty"`
+}
+
+// UsersResponse - список пользователей
+type UsersResponse struct {
+	Users []*UserResponse `json:"users"`
+}
+
+// userEntity - пользователь в ответах DatabaseService
+type userEntity interface {
+	GetId() uint64
+	GetEmail() string
+	GetUsername() string
+	GetPhone() string
+	GetCard() []*DatabaseServicev1.Card
+	GetRole() string
+	GetCompany() *DatabaseServicev1.Company
+	GetType() uint64
+	GetCreatedAt() string
+	GetUpdatedAt() string
+}
+
+// newUserResponse - пользователь из DatabaseService без пароля, номеров карт и CVV
+func (route *Router) newUserResponse(ctx context.Context, u userEntity, donations []*DatabaseServicev1.Donations) *UserResponse {
+	response := &UserResponse{
+		Id:        u.GetId(),
+		Email:     u.GetEmail(),
+		Username:  u.GetUsername(),
+		Phone:     u.GetPhone(),
+		Role:      u.GetRole(),
+		Type:      u.GetType(),
+		CreatedAt: u.GetCreatedAt(),
+		UpdatedAt: u.GetUpdatedAt(),
+	}
+	if len(u.GetCard()) > 0 {
+		response.Card = route.newCardsResponse(ctx, u.GetCard()).Cards
+	}
+	if u.GetCompany() != nil {
+		response.Company = route.newCompanyResponse(ctx, u.GetCompany())
+	}
+	if len(donations) > 0 {
+		response.Donations = route.newDonationsResponse(ctx, donations).Donations
+	}
+
+	return response
+}
+
+// newUsersResponse - список пользователей из DatabaseService
+func (route *Router) newUsersResponse(ctx context.Context, users []*DatabaseServicev1.CreateUserResponse) UsersResponse {
+	response := UsersResponse{Users: make([]*UserResponse, 0, len(users))}
+	for _, u := range users {
+		response.Users = append(response.Users, route.newUserResponse(ctx, u, u.GetDonations()))
+	}
+
+	return response
+}
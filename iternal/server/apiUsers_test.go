@@ -0,0 +1,45 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/config"
+	"apiGateway/pkg/throttle"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestComparePasswordForeignPhone(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79990000001", Role: RoleUser}
+	other := &DatabaseServicev1.CreateUserResponse{Id: 2, Phone: "+79990000002", Role: RoleUser}
+	db := newFakeDatabase(user, other)
+	route, _ := newTestRouter(t, db)
+	route.ipAttempts = throttle.NewGuard("ip:", config.Attempts{FreeAttempts: 1, BaseDelay: time.Minute,
+		Window: time.Hour}, throttle.NewMemoryStore())
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Чужой и незарегистрированный номер не различаются, перебор номеров ограничивается по IP
+	tests := []struct {
+		name  string
+		phone string
+		want  int
+	}{
+		{name: "Чужой номер", phone: other.Phone, want: http.StatusForbidden},
+		{name: "Незарегистрированный номер", phone: "+79990000003", want: http.StatusForbidden},
+		{name: "Перебор номеров", phone: "+79990000004", want: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveWith(route, http.MethodPost, "/api/v1/users/comparePassword", "Bearer "+tokens.Token,
+				`{"phone":"`+tt.phone+`","password":"Password1!"}`)
+			if rec.Code != tt.want {
+				t.Errorf("code = %d, want %d, body = %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/utilities"
+	"net/http"
+)
+
+// VaultReencryptResponse - результат перешифрования номеров карт и секретов TOTP
+type VaultReencryptResponse struct {
+	Reencrypted int `json:"reencrypted"` // Количество номеров, перешифрованных активным ключом или с новым отпечатком
+	MfaSecrets  int `json:"mfaSecrets"`  // Количество секретов TOTP, перешифрованных активным ключом
+}
+
+// VaultReencrypt godoc
+// @Summary      Перешифрование номеров карт
+// @Description  Перешифровывает активным ключом (vault.active_key) номера карт и секреты TOTP, зашифрованные
+// @Description  предыдущими ключами, и пересчитывает отпечатки карт после замены vault.fingerprint_key.
+// @Description  После успешного выполнения предыдущие ключи можно удалить из конфигурации
+// @Tags         Vault
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  VaultReencryptResponse
+// @Failure      401  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/vault/reencrypt [post]
+func (route *Router) VaultReencrypt(w http.ResponseWriter, r *http.Request) {
+	count, err := route.vault.Reencrypt(r.Context())
+	if err != nil {
+		logger.Error("Ошибка при перешифровании номеров карт, перешифровано %d: %v", count, err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("Перешифровано номеров карт: %d", count)
+
+	secrets, err := route.authenticator.Reencrypt(r.Context())
+	if err != nil {
+		logger.Error("Ошибка при перешифровании секретов TOTP, перешифровано %d: %v", secrets, err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("Перешифровано секретов TOTP: %d", secrets)
+
+	str := utilities.ToJSON(&VaultReencryptResponse{Reencrypted: count, MfaSecrets: secrets})
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/notifier"
+	"apiGateway/pkg/token"
+	"apiGateway/pkg/utilities"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// VerifyRequest - подтверждение контакта, без кода отправляется новый код
+type VerifyRequest struct {
+	Code string `json:"code,omitempty"`
+}
+
+// VerificationResponse - состояние подтверждения контактов пользователя
+type VerificationResponse struct {
+	Phone bool `json:"phone"` // Текущий телефон подтвержден
+	Email bool `json:"email"` // Текущий email подтвержден
+}
+
+// VerifyPhone godoc
+// @Summary      Подтверждение телефона
+// @Description  Без кода отправляет одноразовый код на телефон пользователя, с кодом - подтверждает телефон
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body VerifyRequest false "Код из SMS"
+// @Success      200  {object}  VerificationResponse
+// @Success      202  {object}  CodeSentResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/verify/phone [post]
+func (route *Router) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	route.verifyContact(w, r, notifier.ChannelSMS)
+}
+
+// VerifyEmail godoc
+// @Summary      Подтверждение email
+// @Description  Без кода отправляет одноразовый код на email пользователя, с кодом - подтверждает email
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body VerifyRequest false "Код из письма"
+// @Success      200  {object}  VerificationResponse
+// @Success      202  {object}  CodeSentResponse
+// @Failure      400  {object}  HTTPError
+// @Failure      401  {object}  HTTPError
+// @Failure      429  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/verify/email [post]
+func (route *Router) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	route.verifyContact(w, r, notifier.ChannelEmail)
+}
+
+// Verification godoc
+// @Summary      Состояние подтверждения контактов
+// @Description  Показывает, подтверждены ли текущие телефон и email пользователя
+// @Tags         Authentication
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  VerificationResponse
+// @Failure      401  {object}  HTTPError
+// @Failure      500  {object}  HTTPError
+// @Router       /api/v1/auth/verify [get]
+func (route *Router) Verification(w http.ResponseWriter, r *http.Request) {
+	user, err := route.databaseService.FindUserById(r.Context(),
+		&DatabaseServicev1.FindUserByIdRequest{Id: r.Context().Value("user").(token.IUser).GetUserId()})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	route.writeVerification(w, r, user)
+}
+
+// verifyContact - отправляет код на контакт пользователя или подтверждает контакт кодом
+func (route *Router) verifyContact(w http.ResponseWriter, r *http.Request, channel notifier.Channel) {
+	request := new(VerifyRequest)
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, err := route.databaseService.FindUserById(r.Context(),
+		&DatabaseServicev1.FindUserByIdRequest{Id: r.Context().Value("user").(token.IUser).GetUserId()})
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return
+	}
+
+	purpose, value := purposeVerifyPhone, user.GetPhone()
+	if channel == notifier.ChannelEmail {
+		purpose, value = purposeVerifyEmail, user.GetEmail()
+	}
+
+	if value == "" {
+		SetHTTPError(w, "Контакт для подтверждения не указан в профиле", http.StatusBadRequest)
+		return
+	}
+
+	// Код привязан к значению контакта, после смены телефона или email старый код не подойдет
+	subject := fmt.Sprintf("%d:%s", user.GetId(), value)
+
+	if request.Code == "" {
+		wait, err := route.sendCode(r.Context(), purpose, subject, channel, value, "Код подтверждения: %s")
+		if err != nil {
+			setCodeError(w, err, wait)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		str := utilities.ToJSON(CodeSentResponse{ExpiresIn: int64(route.codes.TTL().Seconds())})
+		_, err = w.Write([]byte(str))
+		if err != nil {
+			logger.Error("%s", err.Error())
+		}
+		return
+	}
+
+	if err = route.codes.Verify(r.Context(), purpose, subject, request.Code); err != nil {
+		setCodeError(w, err, 0)
+		return
+	}
+
+	if err = route.verifications.MarkVerified(r.Context(), user.GetId(), channel, value); err != nil {
+		logger.Error("Ошибка при сохранении подтверждения: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	route.writeVerification(w, r, user)
+}
+
+// writeVerification - записывает в ответ состояние подтверждения контактов пользователя
+func (route *Router) writeVerification(w http.ResponseWriter, r *http.Request, user *DatabaseServicev1.CreateUserResponse) {
+	phone, err := route.verifications.IsVerified(r.Context(), user.GetId(), notifier.ChannelSMS, user.GetPhone())
+	if err != nil {
+		logger.Error("Ошибка при проверке подтверждения: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	email, err := route.verifications.IsVerified(r.Context(), user.GetId(), notifier.ChannelEmail, user.GetEmail())
+	if err != nil {
+		logger.Error("Ошибка при проверке подтверждения: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return
+	}
+
+	str := utilities.ToJSON(VerificationResponse{Phone: phone, Email: email})
+	_, err = w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
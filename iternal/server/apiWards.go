@@ -3,7 +3,9 @@ package server
 import (
 	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
 	"apiGateway/pkg/logger"
+	"apiGateway/pkg/money"
 	"apiGateway/pkg/utilities"
+	"context"
 	"encoding/json"
 	"github.com/gorilla/mux"
 	"net/http"
@@ -15,22 +17,29 @@ import (
 // @Tags         Wards
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  DatabaseServicev1.WardsResponse
+// @Param        limit   query     int     false  "Размер страницы"
+// @Param        cursor  query     string  false  "Курсор следующей страницы из заголовка Link"
+// @Param        sort    query     string  false  "Сортировка: id, fullName, want, collected, necessary, createdAt, \"-\" перед полем - по убыванию"  default(id)
+// @Param        filter  query     string  false  "Фильтр поле:значение через запятую, поля: want"
+// @Success      200  {object}  WardsResponse
+// @Header       200  {string}   Link           "Ссылки на первую (rel=first) и следующую (rel=next) страницы"
+// @Header       200  {integer}  X-Total-Count  "Количество элементов с учетом фильтра"
 // @Failure      400  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/wards [get]
-func (route Router) Wards(w http.ResponseWriter, r *http.Request) {
-	response, err := route.databaseService.Wards(r.Context(), nil)
-	if err != nil {
-		logger.Error("Ошибка при выполнении запроса: %v", err)
-		SetGRPCError(w, err)
+func (route *Router) Wards(w http.ResponseWriter, r *http.Request) {
+	wards, ok := listPage(route, w, r, wardsList, func(ctx context.Context) ([]*DatabaseServicev1.Ward, error) {
+		response, err := route.databaseService.Wards(ctx, nil)
+		return response.GetWards(), err
+	})
+	if !ok {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newWardsResponse(r.Context(), wards))
 
-	_, err = w.Write([]byte(str))
+	_, err := w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
@@ -43,28 +52,40 @@ func (route Router) Wards(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        ward body DatabaseServicev1.CreateWardRequest false "Сущность подопечного"
-// @Success      200  {object}  DatabaseServicev1.Ward
+// @Param        ward body WardRequest false "Сущность подопечного"
+// @Success      200  {object}  WardResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/wards [post]
-func (route Router) CreateWard(w http.ResponseWriter, r *http.Request) {
-	request := new(DatabaseServicev1.CreateWardRequest)
+func (route *Router) CreateWard(w http.ResponseWriter, r *http.Request) {
+	request := new(WardRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
-		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		setDecodeError(w, err)
 		return
 	}
 
-	response, err := route.databaseService.CreateWard(r.Context(), request)
+	if request.Necessary < 0 {
+		SetHTTPError(w, "Поле \"necessary\" не может быть меньше 0", http.StatusBadRequest)
+		return
+	}
+
+	response, err := route.databaseService.CreateWard(r.Context(), &DatabaseServicev1.CreateWardRequest{
+		Address:   request.Address,
+		Title:     request.Title,
+		FullName:  request.FullName,
+		Want:      request.Want,
+		Necessary: request.Necessary.Float32(),
+	})
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
 		SetGRPCError(w, err)
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newWardResponse(r.Context(), response))
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
@@ -78,12 +99,12 @@ func (route Router) CreateWard(w http.ResponseWriter, r *http.Request) {
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "ID подопечного"
-// @Success      200  {object}  DatabaseServicev1.Ward
+// @Success      200  {object}  WardResponse
 // @Failure      400  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/wards/{id} [get]
-func (route Router) Ward(w http.ResponseWriter, r *http.Request) {
+func (route *Router) Ward(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -101,7 +122,7 @@ func (route Router) Ward(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newWardResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -119,10 +140,11 @@ func (route Router) Ward(w http.ResponseWriter, r *http.Request) {
 // @Param        ward body DatabaseServicev1.Ward false "Сущность подопечного"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/wards/deleteModel [post]
-func (route Router) DeleteWardByModel(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteWardByModel(w http.ResponseWriter, r *http.Request) {
 	request := new(DatabaseServicev1.Ward)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
@@ -154,10 +176,11 @@ func (route Router) DeleteWardByModel(w http.ResponseWriter, r *http.Request) {
 // @Param        id   path      int  true  "ID подопечного"
 // @Success      200  {object}  DatabaseServicev1.HTTPCodes
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/wards/{id} [delete]
-func (route Router) DeleteWardById(w http.ResponseWriter, r *http.Request) {
+func (route *Router) DeleteWardById(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -184,33 +207,53 @@ func (route Router) DeleteWardById(w http.ResponseWriter, r *http.Request) {
 
 // UpdateWard godoc
 // @Summary      Обновление подопечного
-// @Description  Обновление подопечного
+// @Description  Обновление подопечного. Собранная сумма (collected) изменяется только платежами и запросом не перезаписывается. Если указан updatedAt и подопечный с тех пор изменен, возвращается 409
 // @Tags         Wards
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        ward body DatabaseServicev1.Ward false "Модель для обновления"
-// @Success      200  {object}  DatabaseServicev1.Ward
+// @Param        ward body WardRequest false "Модель для обновления"
+// @Success      200  {object}  WardResponse
 // @Failure      400  {object}  HTTPError
+// @Failure      403  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
+// @Failure      409  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/wards [put]
-func (route Router) UpdateWard(w http.ResponseWriter, r *http.Request) {
-	request := new(DatabaseServicev1.Ward)
+func (route *Router) UpdateWard(w http.ResponseWriter, r *http.Request) {
+	request := new(WardRequest)
 
 	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
-		SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+		setDecodeError(w, err)
+		return
+	}
+
+	if request.Id <= 0 {
+		SetHTTPError(w, "Поле \"ID\" не может быть меньше или равно 0", http.StatusBadRequest)
 		return
 	}
 
-	response, err := route.databaseService.UpdateWard(r.Context(), request)
+	response, err := route.changeWard(r.Context(), request.Id, func(ward *DatabaseServicev1.Ward) error {
+		// Клиент изменял устаревшую копию подопечного
+		if request.UpdatedAt != "" && request.UpdatedAt != ward.GetUpdatedAt() {
+			return errWardConflict
+		}
+
+		ward.Title = request.Title
+		ward.FullName = request.FullName
+		ward.Address = request.Address
+		ward.Want = request.Want
+		ward.Necessary = request.Necessary.Float32()
+
+		return nil
+	})
 	if err != nil {
 		logger.Error("Ошибка при выполнении запроса: %v", err)
 		SetGRPCError(w, err)
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newWardResponse(r.Context(), response))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
@@ -226,12 +269,12 @@ func (route Router) UpdateWard(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      int  true  "Ward ID"
-// @Success      200  {object}  DatabaseServicev1.DonationsResponse
+// @Success      200  {object}  DonationsResponse
 // @Failure      400  {object}  HTTPError
 // @Failure      404  {object}  HTTPError
 // @Failure      500  {object}  HTTPError
 // @Router       /api/v1/wards/{id}/donations [get]
-func (route Router) FindWardDonations(w http.ResponseWriter, r *http.Request) {
+func (route *Router) FindWardDonations(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)["id"]
 	id := utilities.StrToUint(vars)
 
@@ -249,10 +292,81 @@ func (route Router) FindWardDonations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	str := utilities.ToJSON(response)
+	str := utilities.ToJSON(route.newDonationsResponse(r.Context(), response.GetDonations()))
 
 	_, err = w.Write([]byte(str))
 	if err != nil {
 		logger.Error("%s", err.Error())
 	}
 }
+
+// WardRequest - создание или обновление подопечного
+type WardRequest struct {
+	Id        uint64       `json:"id,omitempty"` // Только при обновлении
+	Title     string       `json:"title"`
+	FullName  string       `json:"fullName"`
+	Address   string       `json:"address"`
+	Want      string       `json:"want"`
+	Necessary money.Amount `json:"necessary" swaggertype:"string" example:"10000.00"`
+	UpdatedAt string       `json:"updatedAt,omitempty"` // Версия подопечного при обновлении, см. PUT /api/v1/wards
+}
+
+// WardResponse - подопечный с собранной и необходимой суммой
+type WardResponse struct {
+	Id        uint64             `json:"id"`
+	Title     string             `json:"title,omitempty"`
+	FullName  string             `json:"fullName,omitempty"`
+	Address   string             `json:"address,omitempty"`
+	Want      string             `json:"want,omitempty"`
+	Collected money.Amount       `json:"collected" swaggertype:"string" example:"2500.50"`
+	Necessary money.Amount       `json:"necessary" swaggertype:"string" example:"10000.00"`
+	Currency  string             `json:"currency" example:"RUB"`
+	Donations []DonationResponse `json:"donations,omitempty"`
+	CreatedAt string             `json:"createdAt,omitempty"`
+	UpdatedAt string             `json:"updatedAt,omitempty"`
+}
+
+// WardsResponse - список подопечных
+type WardsResponse struct {
+	Wards []*WardResponse `json:"wards"`
+}
+
+// DonationWardResponse - подопечный пожертвования
+type DonationWardResponse struct {
+	Wards *WardResponse `json:"wards,omitempty"`
+}
+
+// newWardResponse - подопечный из DatabaseService, суммы переводятся из float32 в копейки
+func (route *Router) newWardResponse(ctx context.Context, ward *DatabaseServicev1.Ward) *WardResponse {
+	if ward == nil {
+		return nil
+	}
+
+	response := &WardResponse{
+		Id:        ward.GetId(),
+		Title:     ward.GetTitle(),
+		FullName:  ward.GetFullName(),
+		Address:   ward.GetAddress(),
+		Want:      ward.GetWant(),
+		Collected: money.FromFloat32(ward.GetCollected()),
+		Necessary: money.FromFloat32(ward.GetNecessary()),
+		Currency:  route.cfg.Payment.Currency,
+		CreatedAt: ward.GetCreatedAt(),
+		UpdatedAt: ward.GetUpdatedAt(),
+	}
+	if len(ward.GetDonations()) > 0 {
+		response.Donations = route.newDonationsResponse(ctx, ward.GetDonations()).Donations
+	}
+
+	return response
+}
+
+// newWardsResponse - список подопечных из DatabaseService
+func (route *Router) newWardsResponse(ctx context.Context, wards []*DatabaseServicev1.Ward) WardsResponse {
+	response := WardsResponse{Wards: make([]*WardResponse, 0, len(wards))}
+	for _, ward := range wards {
+		response.Wards = append(response.Wards, route.newWardResponse(ctx, ward))
+	}
+
+	return response
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"apiGateway/pkg/logger"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"time"
+)
+
+// clientIP - IP адрес клиента без порта
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// attemptsKey - ключ счетчика попыток для телефона, разные форматы записи одного номера учитываются вместе
+func attemptsKey(phone string) string {
+	if normalized, err := normalizePhone(phone); err == nil {
+		return normalized
+	}
+
+	return phone
+}
+
+// allowLoginAttempt - проверяет, что для телефона и IP клиента не действует задержка или блокировка после
+// неудачных попыток входа, иначе формирует ответ 429 с заголовком Retry-After
+func (route *Router) allowLoginAttempt(w http.ResponseWriter, r *http.Request, phone string) bool {
+	phone = attemptsKey(phone)
+
+	phoneWait, err := route.phoneAttempts.Check(r.Context(), phone)
+	if err != nil {
+		logger.Error("Ошибка при проверке попыток входа: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return false
+	}
+
+	ipWait, err := route.ipAttempts.Check(r.Context(), clientIP(r))
+	if err != nil {
+		logger.Error("Ошибка при проверке попыток входа: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return false
+	}
+
+	if wait := max(phoneWait, ipWait); wait > 0 {
+		logger.Warn("Попытка входа отклонена: телефон %s, IP %s, повтор через %s", phone, clientIP(r), wait)
+		setTooManyAttempts(w, wait)
+		return false
+	}
+
+	return true
+}
+
+// allowIpAttempt - проверяет, что для IP клиента не действует задержка или блокировка после неудачных попыток,
+// иначе формирует ответ 429 с заголовком Retry-After. Используется, когда попытка не относится к одному телефону
+func (route *Router) allowIpAttempt(w http.ResponseWriter, r *http.Request) bool {
+	wait, err := route.ipAttempts.Check(r.Context(), clientIP(r))
+	if err != nil {
+		logger.Error("Ошибка при проверке попыток входа: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+		return false
+	}
+
+	if wait > 0 {
+		logger.Warn("Попытка отклонена: IP %s, повтор через %s", clientIP(r), wait)
+		setTooManyAttempts(w, wait)
+		return false
+	}
+
+	return true
+}
+
+// loginFailed - учитывает неудачную попытку входа для телефона и IP клиента
+func (route *Router) loginFailed(r *http.Request, phone string) {
+	phone = attemptsKey(phone)
+
+	if _, err := route.phoneAttempts.Fail(r.Context(), phone); err != nil {
+		logger.Error("Ошибка при учете неудачной попытки входа: %v", err)
+	}
+
+	if _, err := route.ipAttempts.Fail(r.Context(), clientIP(r)); err != nil {
+		logger.Error("Ошибка при учете неудачной попытки входа: %v", err)
+	}
+}
+
+// ipAttemptFailed - учитывает неудачную попытку только для IP клиента: телефон из запроса не принадлежит
+// пользователю, и счетчик этого телефона не должен блокировать его владельца
+func (route *Router) ipAttemptFailed(r *http.Request) {
+	if _, err := route.ipAttempts.Fail(r.Context(), clientIP(r)); err != nil {
+		logger.Error("Ошибка при учете неудачной попытки входа: %v", err)
+	}
+}
+
+// loginSucceeded - сбрасывает счетчик неудачных попыток для телефона. Счетчик IP не сбрасывается,
+// чтобы успешный вход в собственный аккаунт не позволял продолжать перебор чужих паролей
+func (route *Router) loginSucceeded(r *http.Request, phone string) {
+	phone = attemptsKey(phone)
+
+	if err := route.phoneAttempts.Reset(r.Context(), phone); err != nil {
+		logger.Error("Ошибка при сбросе попыток входа: %v", err)
+	}
+}
+
+// setTooManyAttempts - формирует ответ 429 с заголовком Retry-After
+func setTooManyAttempts(w http.ResponseWriter, wait time.Duration) {
+	setRetryAfter(w, wait)
+	SetHTTPError(w, "Слишком много неудачных попыток входа, повторите позже", http.StatusTooManyRequests)
+}
+
+// setRetryAfter - устанавливает заголовок Retry-After, время округляется вверх до секунд
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", fmt.Sprint(int64(math.Ceil(wait.Seconds()))))
+}
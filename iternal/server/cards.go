@@ -0,0 +1,289 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/bankcard"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/vault"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maskedPrefix - замена скрытых цифр номера карты, хранилище не знает длину номера по токену
+const maskedPrefix = "************"
+
+// errDuplicateCard - владелец уже добавил карту с этим номером
+var errDuplicateCard = errors.New("карта уже добавлена")
+
+// sealCard - заменяет номер карты токеном хранилища и удаляет CVV перед отправкой в DatabaseService.
+// Пустой номер и токен не изменяются
+func (route *Router) sealCard(ctx context.Context, number *string, cvv *uint64) error {
+	*cvv = 0
+
+	if *number == "" || vault.IsToken(*number) {
+		return nil
+	}
+
+	entry, err := route.vault.Tokenize(ctx, *number)
+	if err != nil {
+		return err
+	}
+	*number = entry.Token
+
+	return nil
+}
+
+// checkCard - проверяет номер, срок действия и CVV карты. required - новая карта, номер и срок действия
+// обязательны, иначе проверяются только указанные поля. field - префикс полей в ошибках для вложенной карты
+func checkCard(field string, in bankcard.Input, required bool) error {
+	if vault.IsToken(in.Number) {
+		in.Number = ""
+	}
+
+	check := bankcard.ValidateUpdate
+	if required {
+		check = bankcard.Validate
+	}
+
+	err := check(in, time.Now())
+
+	var validation *bankcard.ValidationError
+	if errors.As(err, &validation) && field != "" {
+		for i := range validation.Fields {
+			validation.Fields[i].Field = field + "." + validation.Fields[i].Field
+		}
+	}
+
+	return err
+}
+
+// maskedCard - карта в ответе шлюза
+type maskedCard struct {
+	Number string // Маскированный номер
+	Last4  string
+	Brand  string
+}
+
+// maskCard - маскированный номер, последние 4 цифры и платежная система карты для ответа. Данные токена берутся
+// из хранилища, номер, сохраненный в DatabaseService до появления хранилища, маскируется
+func (route *Router) maskCard(ctx context.Context, number string) maskedCard {
+	if !vault.IsToken(number) {
+		last4 := cardLast4(number)
+		return maskedCard{
+			Number: strings.Repeat("*", len(vault.Digits(number))-len(last4)) + last4,
+			Last4:  last4,
+			Brand:  string(bankcard.Detect(vault.Digits(number))),
+		}
+	}
+
+	entry, err := route.vault.Lookup(ctx, number)
+	if err != nil {
+		logger.Warn("Карта %s не найдена в хранилище карт: %v", number, err)
+		return maskedCard{}
+	}
+
+	return maskedCard{Number: maskedPrefix + entry.Last4, Last4: entry.Last4, Brand: entry.Brand}
+}
+
+// cardPan - номер карты для платежного провайдера: токен расшифровывается, номер, сохраненный до появления
+// хранилища, передается как есть
+func (route *Router) cardPan(ctx context.Context, number string) (string, error) {
+	if !vault.IsToken(number) {
+		return number, nil
+	}
+
+	return route.vault.Detokenize(ctx, number)
+}
+
+// cardFingerprint - отпечаток номера карты: для токена берется из хранилища, номер, сохраненный до появления
+// хранилища, хешируется. Для пустого номера возвращается пустая строка
+func (route *Router) cardFingerprint(ctx context.Context, number string) (string, error) {
+	if !vault.IsToken(number) {
+		if vault.Digits(number) == "" {
+			return "", nil
+		}
+		return route.vault.Fingerprint(number), nil
+	}
+
+	entry, err := route.vault.Lookup(ctx, number)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.Fingerprint, nil
+}
+
+// hasCard - среди карт владельца existing есть карта с номером number. Токены, отсутствующие в хранилище,
+// пропускаются
+func (route *Router) hasCard(ctx context.Context, number string, existing []string) (bool, error) {
+	fingerprint, err := route.cardFingerprint(ctx, number)
+	if err != nil || fingerprint == "" {
+		return false, err
+	}
+
+	for _, other := range existing {
+		otherFingerprint, err := route.cardFingerprint(ctx, other)
+		if errors.Is(err, vault.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if otherFingerprint == fingerprint {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// userHasCard - пользователь уже добавил карту с номером number. Карта exceptId (обновляемая карта) не учитывается,
+// 0 - учитываются все карты
+func (route *Router) userHasCard(ctx context.Context, userId uint64, number string, exceptId uint64) (bool, error) {
+	cards, err := route.databaseService.FindUserCard(ctx, &DatabaseServicev1.FindUserCardRequest{Id: userId})
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	existing := make([]string, 0, len(cards.GetCards()))
+	for _, card := range cards.GetCards() {
+		if exceptId != 0 && card.GetId() == exceptId {
+			continue
+		}
+		existing = append(existing, card.GetNumber())
+	}
+
+	return route.hasCard(ctx, number, existing)
+}
+
+// companyHasCard - у компании уже есть карта с номером number
+func (route *Router) companyHasCard(ctx context.Context, companyId uint64, number string) (bool, error) {
+	card, err := route.databaseService.FindCompanyCard(ctx, &DatabaseServicev1.FindCompanyCardRequest{Id: companyId})
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return route.hasCard(ctx, number, []string{card.GetNumber()})
+}
+
+// cardLast4 - последние 4 цифры номера карты, для номера короче 8 цифр не раскрываются
+func cardLast4(number string) string {
+	digits := vault.Digits(number)
+	if len(digits) < 8 {
+		return ""
+	}
+
+	return digits[len(digits)-4:]
+}
+
+// setCardError - ответ на ошибку проверки карты или хранилища карт
+func setCardError(w http.ResponseWriter, err error) {
+	var validation *bankcard.ValidationError
+	if errors.As(err, &validation) {
+		details := make([]FieldError, 0, len(validation.Fields))
+		for _, field := range validation.Fields {
+			details = append(details, FieldError{Field: field.Field, Message: field.Message})
+		}
+		SetFieldErrors(w, "Неверные данные карты", details)
+		return
+	}
+
+	if errors.Is(err, errDuplicateCard) {
+		SetHTTPError(w, "Карта уже добавлена", http.StatusConflict)
+		return
+	}
+
+	if errors.Is(err, vault.ErrInvalidCard) {
+		SetHTTPError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Error("Ошибка хранилища карт: %v", err)
+	SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+}
+
+// sealCompanyCard - проверяет карту компании и заменяет ее номер токеном, карты может не быть
+func (route *Router) sealCompanyCard(ctx context.Context, field string, card *DatabaseServicev1.CardCompany) error {
+	if card == nil {
+		return nil
+	}
+
+	if err := checkCard(field, bankcard.Input{Number: card.GetNumber(), Date: card.GetDate()}, false); err != nil {
+		return err
+	}
+
+	return route.sealCard(ctx, &card.Number, &card.Cvv)
+}
+
+// userCard - карта в запросе создания или обновления пользователя
+type userCard interface {
+	*DatabaseServicev1.CreateCardRequest | *DatabaseServicev1.Card
+	GetNumber() string
+	GetDate() string
+}
+
+// sealCards - проверяет карты пользователя и заменяет их номера токенами, fields возвращает номер и CVV карты.
+// Одна карта не может быть указана дважды
+func sealCards[C userCard](ctx context.Context, route *Router, cards []C,
+	fields func(card C) (number *string, cvv *uint64)) error {
+	numbers := make([]string, 0, len(cards))
+	for i, card := range cards {
+		if card == nil {
+			continue
+		}
+		input := bankcard.Input{Number: card.GetNumber(), Date: card.GetDate()}
+		if err := checkCard(fmt.Sprintf("card[%d]", i), input, false); err != nil {
+			return err
+		}
+		duplicate, err := route.hasCard(ctx, card.GetNumber(), numbers)
+		if err != nil {
+			return err
+		}
+		if duplicate {
+			return errDuplicateCard
+		}
+		numbers = append(numbers, card.GetNumber())
+		number, cvv := fields(card)
+		if err := route.sealCard(ctx, number, cvv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sealNewUser - проверяет карты нового пользователя и карту его компании, заменяет их номера токенами
+func (route *Router) sealNewUser(ctx context.Context, request *DatabaseServicev1.CreateUserRequest) error {
+	err := sealCards(ctx, route, request.GetCard(), func(card *DatabaseServicev1.CreateCardRequest) (*string, *uint64) {
+		return &card.Number, &card.Cvv
+	})
+	if err != nil {
+		return err
+	}
+
+	return route.sealCompanyCard(ctx, "company.card", request.GetCompany().GetCard())
+}
+
+// sealUser - проверяет карты пользователя и карту его компании при обновлении, заменяет их номера токенами
+func (route *Router) sealUser(ctx context.Context, request *DatabaseServicev1.UpdateUserRequest) error {
+	err := sealCards(ctx, route, request.GetCard(), func(card *DatabaseServicev1.Card) (*string, *uint64) {
+		return &card.Number, &card.Cvv
+	})
+	if err != nil {
+		return err
+	}
+
+	return route.sealCompanyCard(ctx, "company.card", request.GetCompany().GetCard())
+}
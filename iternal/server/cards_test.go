@@ -0,0 +1,139 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/vault"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCardVault(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000})
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ошибки возвращаются по каждому неверному полю
+	rec := serveWith(route, http.MethodPost, "/api/v1/cards", "Bearer "+tokens.Token,
+		`{"fullName":"IVAN IVANOV","number":"4111111111111112","date":"01/20","cvv":"12","userId":1}`)
+	httpError := new(HTTPError)
+	if err = json.NewDecoder(rec.Body).Decode(httpError); err != nil {
+		t.Fatal(err)
+	}
+	fields := make([]string, 0, len(httpError.Details))
+	for _, detail := range httpError.Details {
+		fields = append(fields, detail.Field)
+	}
+	if rec.Code != http.StatusBadRequest || strings.Join(fields, ",") != "number,date,cvv" {
+		t.Errorf("неверная карта: code = %d, ошибки = %+v", rec.Code, httpError.Details)
+	}
+	if len(db.cards) != 0 {
+		t.Errorf("неверная карта передана в DatabaseService")
+	}
+
+	rec = serveWith(route, http.MethodPost, "/api/v1/cards", "Bearer "+tokens.Token,
+		`{"fullName":"IVAN IVANOV","number":"`+payment.CardDeclined+`","date":"12/49","cvv":"012","userId":1}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d, body = %s", rec.Code, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), payment.CardDeclined) {
+		t.Errorf("номер карты в ответе: %s", rec.Body)
+	}
+
+	response := new(CardResponse)
+	if err = json.NewDecoder(rec.Body).Decode(response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Last4 != payment.CardDeclined[len(payment.CardDeclined)-4:] || response.Brand != "visa" {
+		t.Errorf("карта в ответе: %+v", response)
+	}
+
+	// В DatabaseService передаются токен вместо номера и пустой CVV
+	stored := db.cards[0]
+	if !vault.IsToken(stored.GetNumber()) || stored.GetCvv() != 0 {
+		t.Errorf("сохраненная карта: %v", stored)
+	}
+
+	// Провайдер получает расшифрованный номер: тестовая карта отклоняется банком
+	rec = serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token,
+		`{"toWardId":5,"cardId":1,"amount":100,"cvv":"123"}`)
+	if rec.Code != http.StatusPaymentRequired {
+		t.Errorf("платеж: code = %d, body = %s", rec.Code, rec.Body)
+	}
+}
+
+func TestDuplicateCards(t *testing.T) {
+	first := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	second := &DatabaseServicev1.CreateUserResponse{Id: 2, Phone: "+79997654321", Role: RoleUser}
+	admin := &DatabaseServicev1.CreateUserResponse{Id: 3, Phone: "+79990000000", Role: RoleAdmin}
+	db := newFakeDatabase(first, second, admin)
+	db.addCard(&DatabaseServicev1.Card{Id: 100, Number: "5500000000000004", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	token := func(user *DatabaseServicev1.CreateUserResponse) string {
+		tokens, err := route.openSession(context.Background(), user, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + tokens.Token
+	}
+	firstToken, secondToken, adminToken := token(first), token(second), token(admin)
+
+	tests := []struct {
+		name   string
+		bearer string
+		body   string
+		want   int
+	}{
+		{name: "Новая карта", bearer: firstToken, body: `{"number":"4111111111111111","date":"12/49","userId":1}`,
+			want: http.StatusOK},
+		{name: "Повтор", bearer: firstToken, body: `{"number":"4111 1111 1111 1111","date":"12/49","userId":1}`,
+			want: http.StatusConflict},
+		{name: "Карта до появления хранилища", bearer: firstToken,
+			body: `{"number":"5500000000000004","date":"12/49","userId":1}`, want: http.StatusConflict},
+		{name: "Другой пользователь", bearer: secondToken,
+			body: `{"number":"4111111111111111","date":"12/49","userId":2}`, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveWith(route, http.MethodPost, "/api/v1/cards", tt.bearer, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("code = %d, want %d, body = %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	rec := serveWith(route, http.MethodGet, "/api/v1/cards/shared", secondToken, "")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("отчет для пользователя: code = %d", rec.Code)
+	}
+
+	rec = serveWith(route, http.MethodGet, "/api/v1/cards/shared", adminToken, "")
+	report := new(SharedCardsResponse)
+	if err := json.NewDecoder(rec.Body).Decode(report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Cards) != 1 || report.Cards[0].Last4 != "1111" || len(report.Cards[0].Owners) != 2 ||
+		report.Cards[0].Owners[0].Id != 1 || report.Cards[0].Owners[1].Id != 2 {
+		t.Errorf("отчет: %+v", report)
+	}
+
+	// Номер обновляемой карты сравнивается с другими картами владельца, но не с ней самой
+	rec = serveWith(route, http.MethodPut, "/api/v1/cards/2", firstToken, `{"number":"5500000000000004"}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("номер другой карты владельца: code = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	rec = serveWith(route, http.MethodPut, "/api/v1/cards/2", firstToken, `{"number":"4111111111111111"}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("тот же номер: code = %d, want %d, body = %s", rec.Code, http.StatusOK, rec.Body)
+	}
+}
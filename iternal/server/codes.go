@@ -0,0 +1,62 @@
+package server
+
+import (
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/notifier"
+	"apiGateway/pkg/onetime"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Назначения одноразовых кодов, код одного назначения нельзя использовать для другого
+const (
+	purposePasswordReset = "password_reset"
+	purposeVerifyPhone   = "verify_phone"
+	purposeVerifyEmail   = "verify_email"
+)
+
+// CodeSentResponse - ответ на запрос одноразового кода
+type CodeSentResponse struct {
+	ExpiresIn int64 `json:"expiresIn"` // Срок действия кода в секундах
+}
+
+// sendCode - выпускает одноразовый код и отправляет его получателю to, text должен содержать %s для кода.
+// Если предыдущий код отправлен недавно, возвращается onetime.ErrTooSoon и время до повторной отправки
+func (route *Router) sendCode(ctx context.Context, purpose, subject string, channel notifier.Channel, to,
+	text string) (time.Duration, error) {
+	code, wait, err := route.codes.Issue(ctx, purpose, subject)
+	if err != nil {
+		return wait, err
+	}
+
+	err = route.notifier.Send(ctx, notifier.Message{
+		Channel: channel,
+		To:      to,
+		Subject: "Код подтверждения",
+		Text:    fmt.Sprintf(text, code),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при отправке кода: %w", err)
+	}
+
+	return 0, nil
+}
+
+// setCodeError - формирует ответ на ошибку выпуска или проверки одноразового кода
+func setCodeError(w http.ResponseWriter, err error, wait time.Duration) {
+	switch {
+	case errors.Is(err, onetime.ErrTooSoon):
+		setRetryAfter(w, wait)
+		SetHTTPError(w, "Код уже отправлен, повторите запрос позже", http.StatusTooManyRequests)
+	case errors.Is(err, onetime.ErrCodeInvalid):
+		SetHTTPError(w, "Неверный или истекший код", http.StatusBadRequest)
+	case errors.Is(err, onetime.ErrTooManyAttempts):
+		SetHTTPError(w, "Превышено количество попыток ввода кода, запросите новый код", http.StatusTooManyRequests)
+	default:
+		logger.Error("Ошибка при обработке одноразового кода: %v", err)
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+	}
+}
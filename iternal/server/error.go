@@ -2,13 +2,22 @@ package server
 
 import (
 	"apiGateway/pkg/logger"
+	"apiGateway/pkg/money"
 	"apiGateway/pkg/utilities"
+	"errors"
 	"net/http"
 )
 
 type HTTPError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"` // Ошибки в отдельных полях запроса
+}
+
+// FieldError - ошибка в поле запроса
+type FieldError struct {
+	Field   string `json:"field" example:"number"`
+	Message string `json:"message" example:"неверный номер карты"`
 }
 
 func SetGRPCError(w http.ResponseWriter, err error) {
@@ -28,6 +37,16 @@ func SetGRPCError(w http.ResponseWriter, err error) {
 	}
 }
 
+// setDecodeError - ответ 400 на ошибку разбора тела запроса, неверный формат суммы объясняется клиенту
+func setDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, money.ErrFormat) || errors.Is(err, money.ErrOverflow) {
+		SetHTTPError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	SetHTTPError(w, "Неверные аргументы", http.StatusBadRequest)
+}
+
 func SetHTTPError(w http.ResponseWriter, errStr string, code int) {
 	w.WriteHeader(code)
 
@@ -42,3 +61,20 @@ func SetHTTPError(w http.ResponseWriter, errStr string, code int) {
 		logger.Error("%v", err)
 	}
 }
+
+// SetFieldErrors - ответ 400 с ошибками в отдельных полях запроса
+func SetFieldErrors(w http.ResponseWriter, errStr string, details []FieldError) {
+	w.WriteHeader(http.StatusBadRequest)
+
+	h := HTTPError{
+		Code:    http.StatusBadRequest,
+		Message: errStr,
+		Details: details,
+	}
+
+	str := utilities.ToJSON(h)
+	_, err := w.Write([]byte(str))
+	if err != nil {
+		logger.Error("%v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"apiGateway/pkg/idempotency"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/token"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	idempotencyHeader         = "Idempotency-Key"     // Заголовок запроса с ключом идемпотентности
+	idempotentReplayedHeader  = "Idempotent-Replayed" // Заголовок ответа, возвращенного из сохраненного результата
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// responseRecorder - ResponseWriter, который запоминает код и тело ответа для сохранения по ключу идемпотентности
+type responseRecorder struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	committed bool // Запрос выполнил необратимые действия, ответ сохраняется и с кодом 5xx
+}
+
+// WriteHeader - запоминает код ответа
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write - запоминает тело ответа
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(data)
+	return rec.ResponseWriter.Write(data)
+}
+
+// commitIdempotent - отмечает, что запрос выполнил необратимые действия (сага прошла необратимый шаг): ответ
+// сохраняется по ключу идемпотентности и с кодом 5xx, повтор получит его, а не выполнит запрос заново
+func commitIdempotent(w http.ResponseWriter) {
+	if rec, ok := w.(*responseRecorder); ok {
+		rec.committed = true
+	}
+}
+
+// idempotent - оборачивает обработчик поддержкой заголовка Idempotency-Key: первый запрос пользователя с ключом
+// выполняется, его ответ сохраняется и возвращается на повторы с тем же ключом, повтор во время выполнения первого
+// запроса получает 409, ключ блокируется до завершения обработчика. Ответы с кодом 5xx не сохраняются, такой запрос
+// можно повторить с тем же ключом, если обработчик не вызвал commitIdempotent.
+// Запросы без заголовка выполняются как обычно. Должен вызываться после authMiddleware
+func (route *Router) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			SetHTTPError(w, fmt.Sprintf("Длина заголовка %s не может превышать %d символов", idempotencyHeader,
+				maxIdempotencyKeyLength), http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+		if err != nil {
+			SetHTTPError(w, "Слишком длинное тело запроса", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		user := r.Context().Value("user").(token.IUser)
+		storeKey := fmt.Sprintf("%d:%s %s:%s", user.GetUserId(), r.Method, r.URL.Path, key)
+
+		response, replay, err := route.idempotency.Begin(r.Context(), storeKey, body)
+		switch {
+		case errors.Is(err, idempotency.ErrInProgress):
+			setRetryAfter(w, route.cfg.APIServer.Timeout)
+			SetHTTPError(w, "Запрос с этим ключом идемпотентности еще выполняется", http.StatusConflict)
+			return
+		case errors.Is(err, idempotency.ErrMismatch):
+			SetHTTPError(w, "Ключ идемпотентности уже использован для запроса с другими параметрами",
+				http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			logger.Error("Ошибка при проверке ключа идемпотентности: %v", err)
+			SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+			return
+		case replay:
+			logger.Info("Повтор запроса %s [%s] с ключом идемпотентности пользователя %d", r.URL.String(),
+				r.Method, user.GetUserId())
+			writeStoredResponse(w, response)
+			return
+		}
+
+		stop := route.idempotency.Hold(context.WithoutCancel(r.Context()), storeKey)
+		rec := &responseRecorder{ResponseWriter: w}
+		next(rec, r)
+		stop()
+
+		// Ответ сохраняется и после отмены запроса клиентом, иначе ключ останется заблокированным до pending_ttl
+		completeIdempotent(context.WithoutCancel(r.Context()), route.idempotency, storeKey, rec)
+	}
+}
+
+// completeIdempotent - сохраняет ответ rec по ключу key, ответ с кодом 5xx не сохраняется, а ключ освобождается,
+// если запрос не выполнил необратимых действий
+func completeIdempotent(ctx context.Context, keeper *idempotency.Keeper, key string, rec *responseRecorder) {
+	if rec.status >= http.StatusInternalServerError && !rec.committed {
+		if err := keeper.Release(ctx, key); err != nil {
+			logger.Error("Ошибка при освобождении ключа идемпотентности: %v", err)
+		}
+		return
+	}
+
+	err := keeper.Complete(ctx, key, idempotency.Response{
+		Status:      rec.status,
+		ContentType: rec.Header().Get("Content-Type"),
+		Body:        rec.body.Bytes(),
+	})
+	if err != nil {
+		logger.Error("Ошибка при сохранении ответа по ключу идемпотентности: %v", err)
+	}
+}
+
+// writeStoredResponse - возвращает сохраненный ответ на первый запрос с ключом идемпотентности
+func writeStoredResponse(w http.ResponseWriter, response idempotency.Response) {
+	if response.ContentType != "" {
+		w.Header().Set("Content-Type", response.ContentType)
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+
+	if _, err := w.Write(response.Body); err != nil {
+		logger.Error("%s", err.Error())
+	}
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/config"
+	"apiGateway/pkg/idempotency"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPaymentIdempotency(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: "4111111111111111", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pay := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/payment", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+tokens.Token)
+		if key != "" {
+			req.Header.Set(idempotencyHeader, key)
+		}
+
+		rec := httptest.NewRecorder()
+		route.r.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	body := `{"toWardId":5,"amount":100}`
+
+	if rec := pay("retry-1", body); rec.Code != http.StatusOK {
+		t.Fatalf("первый запрос: code = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	rec := pay("retry-1", body)
+	if rec.Code != http.StatusOK || rec.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("повтор: code = %d, %s = %q", rec.Code, idempotentReplayedHeader,
+			rec.Header().Get(idempotentReplayedHeader))
+	}
+
+	if got := db.donationCount(); got != 1 {
+		t.Errorf("создано пожертвований: %d, want 1", got)
+	}
+	if got := db.ward(5).GetCollected(); got != 100 {
+		t.Errorf("собрано: %v, want 100", got)
+	}
+
+	if rec = pay("retry-1", `{"toWardId":5,"amount":500}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("тот же ключ с другой суммой: code = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+
+	// Другой ключ и запрос без ключа - новые платежи
+	pay("retry-2", body)
+	pay("", body)
+	if got := db.donationCount(); got != 3 {
+		t.Errorf("создано пожертвований: %d, want 3", got)
+	}
+}
+
+func TestIdempotentInProgress(t *testing.T) {
+	route, _ := newTestRouter(t, newFakeDatabase())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	calls := 0
+
+	handler := route.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/payment", strings.NewReader(`{}`))
+		req.Header.Set(idempotencyHeader, "key")
+		req = req.WithContext(context.WithValue(req.Context(), "user", &apiKeyPrincipal{}))
+
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		return rec
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if rec := request(); rec.Code != http.StatusCreated {
+			t.Errorf("первый запрос: code = %d", rec.Code)
+		}
+	}()
+
+	<-started
+	if rec := request(); rec.Code != http.StatusConflict {
+		t.Errorf("параллельный запрос: code = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if rec := request(); rec.Code != http.StatusCreated || calls != 1 {
+		t.Errorf("повтор после завершения: code = %d, вызовов обработчика %d", rec.Code, calls)
+	}
+}
+
+func TestIdempotentHeldLongerThanPendingTTL(t *testing.T) {
+	route, _ := newTestRouter(t, newFakeDatabase())
+	route.idempotency = idempotency.NewKeeper(config.Idempotency{TTL: time.Hour, PendingTTL: 20 * time.Millisecond},
+		idempotency.NewMemoryStore())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	calls := 0
+
+	handler := route.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/payment", strings.NewReader(`{}`))
+		req.Header.Set(idempotencyHeader, "key")
+		req = req.WithContext(context.WithValue(req.Context(), "user", &apiKeyPrincipal{}))
+
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		return rec
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		request()
+	}()
+
+	// Первый запрос выполняется дольше pending_ttl, ключ остается заблокированным
+	<-started
+	time.Sleep(100 * time.Millisecond)
+	if rec := request(); rec.Code != http.StatusConflict {
+		t.Errorf("параллельный запрос: code = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("вызовов обработчика: %d, want 1", calls)
+	}
+}
+
+func TestIdempotentCommittedServerError(t *testing.T) {
+	route, _ := newTestRouter(t, newFakeDatabase())
+
+	calls := 0
+	handler := route.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/committed" {
+			commitIdempotent(w)
+		}
+		SetHTTPError(w, "Ошибка на стороне сервера", http.StatusInternalServerError)
+	})
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{}`))
+		req.Header.Set(idempotencyHeader, "key")
+		req = req.WithContext(context.WithValue(req.Context(), "user", &apiKeyPrincipal{}))
+
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		return rec
+	}
+
+	// Ответ 5xx без необратимых действий не сохраняется, запрос выполняется повторно
+	request("/released")
+	request("/released")
+	if calls != 2 {
+		t.Errorf("вызовов обработчика: %d, want 2", calls)
+	}
+
+	// После необратимых действий повтор получает сохраненный ответ
+	request("/committed")
+	rec := request("/committed")
+	if calls != 3 || rec.Code != http.StatusInternalServerError || rec.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("повтор: code = %d, %s = %q, вызовов обработчика %d", rec.Code, idempotentReplayedHeader,
+			rec.Header().Get(idempotentReplayedHeader), calls)
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/listing"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/money"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Списки, которые DatabaseService отдает целиком, шлюз выдает постранично. Имена полей совпадают с полями ответов,
+// даты сравниваются как строки в формате DatabaseService
+
+var usersList = listing.Resource[*DatabaseServicev1.CreateUserResponse]{
+	Name: "users",
+	Fields: map[string]listing.Field[*DatabaseServicev1.CreateUserResponse]{
+		"id":        {Sort: true, Value: value((*DatabaseServicev1.CreateUserResponse).GetId)},
+		"email":     {Sort: true, Filter: true, Value: value((*DatabaseServicev1.CreateUserResponse).GetEmail)},
+		"username":  {Sort: true, Filter: true, Value: value((*DatabaseServicev1.CreateUserResponse).GetUsername)},
+		"phone":     {Filter: true, Value: value((*DatabaseServicev1.CreateUserResponse).GetPhone)},
+		"role":      {Sort: true, Filter: true, Value: value((*DatabaseServicev1.CreateUserResponse).GetRole)},
+		"type":      {Sort: true, Filter: true, Value: value((*DatabaseServicev1.CreateUserResponse).GetType)},
+		"createdAt": {Sort: true, Value: value((*DatabaseServicev1.CreateUserResponse).GetCreatedAt)},
+		"updatedAt": {Sort: true, Value: value((*DatabaseServicev1.CreateUserResponse).GetUpdatedAt)},
+	},
+	DefaultSort: "id",
+	Id:          (*DatabaseServicev1.CreateUserResponse).GetId,
+}
+
+var companiesList = listing.Resource[*DatabaseServicev1.Company]{
+	Name: "companies",
+	Fields: map[string]listing.Field[*DatabaseServicev1.Company]{
+		"id":        {Sort: true, Value: value((*DatabaseServicev1.Company).GetId)},
+		"title":     {Sort: true, Filter: true, Value: value((*DatabaseServicev1.Company).GetTitle)},
+		"phone":     {Filter: true, Value: value((*DatabaseServicev1.Company).GetPhone)},
+		"inn":       {Filter: true, Value: value((*DatabaseServicev1.Company).GetInn)},
+		"userId":    {Sort: true, Filter: true, Value: value((*DatabaseServicev1.Company).GetUserId)},
+		"createdAt": {Sort: true, Value: value((*DatabaseServicev1.Company).GetCreatedAt)},
+		"updatedAt": {Sort: true, Value: value((*DatabaseServicev1.Company).GetUpdatedAt)},
+	},
+	DefaultSort: "id",
+	Id:          (*DatabaseServicev1.Company).GetId,
+}
+
+var cardsList = listing.Resource[*DatabaseServicev1.Card]{
+	Name: "cards",
+	Fields: map[string]listing.Field[*DatabaseServicev1.Card]{
+		"id":        {Sort: true, Value: value((*DatabaseServicev1.Card).GetId)},
+		"userId":    {Sort: true, Filter: true, Value: value((*DatabaseServicev1.Card).GetUserId)},
+		"createdAt": {Sort: true, Value: value((*DatabaseServicev1.Card).GetCreatedAt)},
+		"updatedAt": {Sort: true, Value: value((*DatabaseServicev1.Card).GetUpdatedAt)},
+	},
+	DefaultSort: "id",
+	Id:          (*DatabaseServicev1.Card).GetId,
+}
+
+var cardCompaniesList = listing.Resource[*DatabaseServicev1.CardCompany]{
+	Name: "cardCompanies",
+	Fields: map[string]listing.Field[*DatabaseServicev1.CardCompany]{
+		"id":        {Sort: true, Value: value((*DatabaseServicev1.CardCompany).GetId)},
+		"companyId": {Sort: true, Filter: true, Value: value((*DatabaseServicev1.CardCompany).GetCompanyId)},
+		"createdAt": {Sort: true, Value: value((*DatabaseServicev1.CardCompany).GetCreatedAt)},
+		"updatedAt": {Sort: true, Value: value((*DatabaseServicev1.CardCompany).GetUpdatedAt)},
+	},
+	DefaultSort: "id",
+	Id:          (*DatabaseServicev1.CardCompany).GetId,
+}
+
+var donationsList = listing.Resource[*DatabaseServicev1.Donations]{
+	Name: "donations",
+	Fields: map[string]listing.Field[*DatabaseServicev1.Donations]{
+		"id":        {Sort: true, Value: value((*DatabaseServicev1.Donations).GetId)},
+		"amount":    {Sort: true, Value: amount((*DatabaseServicev1.Donations).GetAmount)},
+		"wardId":    {Sort: true, Filter: true, Value: value((*DatabaseServicev1.Donations).GetWardId)},
+		"userId":    {Sort: true, Filter: true, Value: value((*DatabaseServicev1.Donations).GetUserId)},
+		"createdAt": {Sort: true, Value: value((*DatabaseServicev1.Donations).GetCreatedAt)},
+	},
+	DefaultSort: "-id",
+	Id:          (*DatabaseServicev1.Donations).GetId,
+}
+
+var wardsList = listing.Resource[*DatabaseServicev1.Ward]{
+	Name: "wards",
+	Fields: map[string]listing.Field[*DatabaseServicev1.Ward]{
+		"id":        {Sort: true, Value: value((*DatabaseServicev1.Ward).GetId)},
+		"fullName":  {Sort: true, Value: value((*DatabaseServicev1.Ward).GetFullName)},
+		"want":      {Sort: true, Filter: true, Value: value((*DatabaseServicev1.Ward).GetWant)},
+		"collected": {Sort: true, Value: amount((*DatabaseServicev1.Ward).GetCollected)},
+		"necessary": {Sort: true, Value: amount((*DatabaseServicev1.Ward).GetNecessary)},
+		"createdAt": {Sort: true, Value: value((*DatabaseServicev1.Ward).GetCreatedAt)},
+	},
+	DefaultSort: "id",
+	Id:          (*DatabaseServicev1.Ward).GetId,
+}
+
+// value - значение поля списка из геттера сообщения DatabaseService
+func value[T, V any](get func(T) V) func(T) any {
+	return func(item T) any { return get(item) }
+}
+
+// amount - сумма в копейках из геттера суммы DatabaseService, сортируется без погрешности float32
+func amount[T any](get func(T) float32) func(T) any {
+	return func(item T) any { return money.FromFloat32(get(item)) }
+}
+
+// listPage - страница списка по параметрам limit, cursor, sort и filter, в ответ записываются заголовки
+// X-Total-Count и Link. ok = false, если ответ с ошибкой уже записан
+func listPage[T any](route *Router, w http.ResponseWriter, r *http.Request, res listing.Resource[T],
+	fetch func(ctx context.Context) ([]T, error)) ([]T, bool) {
+	limits := listing.Limits{Default: route.cfg.Pagination.DefaultLimit, Max: route.cfg.Pagination.MaxLimit}
+
+	query, err := res.Parse(r.URL.Query(), limits)
+	if err != nil {
+		var listErr *listing.Error
+		if !errors.As(err, &listErr) {
+			SetHTTPError(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+		SetFieldErrors(w, "Неверные параметры списка", []FieldError{{Field: listErr.Param, Message: listErr.Message}})
+		return nil, false
+	}
+
+	sorted, err := listing.Load(route.lists, res, query, func() ([]T, error) { return fetch(r.Context()) })
+	if err != nil {
+		logger.Error("Ошибка при выполнении запроса: %v", err)
+		SetGRPCError(w, err)
+		return nil, false
+	}
+
+	page, next := res.Page(query, sorted)
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(sorted)))
+	w.Header().Set("Link", pageLinks(r.URL, next))
+
+	return page, true
+}
+
+// pageLinks - заголовок Link со ссылками на первую и следующую страницы
+func pageLinks(current *url.URL, next string) string {
+	link := func(cursor, rel string) string {
+		values := current.Query()
+		values.Del("cursor")
+		if cursor != "" {
+			values.Set("cursor", cursor)
+		}
+		return fmt.Sprintf("<%s>; rel=\"%s\"", (&url.URL{Path: current.Path, RawQuery: values.Encode()}).String(), rel)
+	}
+
+	links := []string{link("", "first")}
+	if next != "" {
+		links = append(links, link(next, "next"))
+	}
+
+	return strings.Join(links, ", ")
+}
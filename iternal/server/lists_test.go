@@ -0,0 +1,75 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestListPages(t *testing.T) {
+	admin := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79990000001", Role: RoleAdmin}
+	db := newFakeDatabase(admin)
+	for id := uint64(1); id <= 5; id++ {
+		db.addCard(&DatabaseServicev1.Card{Id: id, Number: "4111111111111111", UserId: 1 + id%2})
+	}
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), admin, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := regexp.MustCompile(`<([^>]+)>; rel="next"`)
+	page := func(path, total string) ([]uint64, string) {
+		t.Helper()
+		rec := serveWith(route, http.MethodGet, path, "Bearer "+tokens.Token, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: code = %d, body = %s", path, rec.Code, rec.Body)
+		}
+		if count := rec.Header().Get("X-Total-Count"); count != total {
+			t.Errorf("%s: X-Total-Count = %q, ожидалось %s", path, count, total)
+		}
+
+		response := new(CardsResponse)
+		if err := json.NewDecoder(rec.Body).Decode(response); err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]uint64, 0, len(response.Cards))
+		for _, card := range response.Cards {
+			ids = append(ids, card.Id)
+		}
+
+		link := next.FindStringSubmatch(rec.Header().Get("Link"))
+		if link == nil {
+			return ids, ""
+		}
+		return ids, link[1]
+	}
+
+	// Карты пользователя 2 по убыванию ID, вторая страница по ссылке из заголовка Link
+	ids, link := page("/api/v1/cards?limit=2&sort=-id&filter=userId:2", "3")
+	if len(ids) != 2 || ids[0] != 5 || ids[1] != 3 || link == "" {
+		t.Fatalf("первая страница: %v, next = %q", ids, link)
+	}
+
+	// Карта, добавленная после первой страницы, не сдвигает следующую страницу
+	db.addCard(&DatabaseServicev1.Card{Id: 6, Number: "4111111111111111", UserId: 2})
+
+	ids, link = page(link, "4")
+	if len(ids) != 1 || ids[0] != 1 || link != "" {
+		t.Errorf("вторая страница: %v, next = %q", ids, link)
+	}
+
+	// Неизвестное поле сортировки
+	rec := serveWith(route, http.MethodGet, "/api/v1/cards?sort=number", "Bearer "+tokens.Token, "")
+	httpError := new(HTTPError)
+	if err = json.NewDecoder(rec.Body).Decode(httpError); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest || len(httpError.Details) != 1 || httpError.Details[0].Field != "sort" {
+		t.Errorf("неверная сортировка: code = %d, ошибка = %+v", rec.Code, httpError)
+	}
+}
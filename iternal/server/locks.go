@@ -0,0 +1,52 @@
+package server
+
+import "sync"
+
+// keyLock - блокировка одного ключа с количеством ожидающих ее запросов
+type keyLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// keyLocks - блокировки по ID внутри экземпляра шлюза. Блокировки создаются по требованию и удаляются,
+// когда их никто не ждет, нулевое значение готово к использованию
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[uint64]*keyLock
+}
+
+// lock - блокирует id, возвращает функцию снятия блокировки
+func (l *keyLocks) lock(id uint64) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[uint64]*keyLock)
+	}
+	lock, ok := l.locks[id]
+	if !ok {
+		lock = &keyLock{}
+		l.locks[id] = lock
+	}
+	lock.waiters++
+	l.mu.Unlock()
+
+	lock.Lock()
+
+	return func() {
+		lock.Unlock()
+
+		l.mu.Lock()
+		lock.waiters--
+		if lock.waiters == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// len - количество заблокированных и ожидающих блокировки ID
+func (l *keyLocks) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.locks)
+}
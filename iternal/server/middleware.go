@@ -4,12 +4,13 @@ import (
 	"apiGateway/pkg/logger"
 	"apiGateway/pkg/token"
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 )
 
 // authMiddleware - промежуточное ПО для приватных запросов
-func (route Router) authMiddleware(next http.Handler) http.Handler {
+func (route *Router) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Content-Type", "application/json, Authorization")
@@ -22,11 +23,34 @@ func (route Router) authMiddleware(next http.Handler) http.Handler {
 
 		logger.Info("Приватный запрос: %s [%s]", r.URL.String(), r.Method)
 
+		if scheme, key, found := strings.Cut(tokenString, " "); found && strings.EqualFold(scheme, apiKeyScheme) {
+			route.apiKeyAuth(w, r, next, strings.TrimSpace(key))
+			return
+		}
+
 		tokenString = strings.Replace(tokenString, "Bearer ", "", 1)
 
-		jwtToken, err := token.ParseToken(tokenString, route.cfg)
+		jwtToken, err := route.tokens.ParseToken(tokenString)
 		if err != nil {
-			SetHTTPError(w, "Ошибка доступа, неверный токен", http.StatusUnauthorized)
+			logger.Warn("Отказано в доступе: %s [%s], %v", r.URL.String(), r.Method, err)
+			SetHTTPError(w, tokenErrorMessage(err), http.StatusUnauthorized)
+			return
+		}
+
+		if route.mfaRequired(jwtToken.GetRole()) && !jwtToken.GetMfa() {
+			logger.Warn("Отказано в доступе: %s [%s], вход пользователя %d без второго фактора",
+				r.URL.String(), r.Method, jwtToken.GetUserId())
+			SetHTTPError(w, "Ошибка доступа, требуется вход с двухфакторной аутентификацией", http.StatusUnauthorized)
+			return
+		}
+
+		if err = route.checkSession(r.Context(), jwtToken); err != nil {
+			if errors.Is(err, errSessionNotFound) {
+				SetHTTPError(w, "Ошибка доступа, сессия завершена", http.StatusUnauthorized)
+				return
+			}
+			logger.Error("Ошибка при проверке сессии: %v", err)
+			SetGRPCError(w, err)
 			return
 		}
 
@@ -35,8 +59,22 @@ func (route Router) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// tokenErrorMessage - текст ошибки доступа для ошибки проверки токена
+func tokenErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, token.ErrTokenExpired):
+		return "Ошибка доступа, срок действия токена истек"
+	case errors.Is(err, token.ErrTokenSignature):
+		return "Ошибка доступа, неверная подпись токена"
+	case errors.Is(err, token.ErrTokenAudience):
+		return "Ошибка доступа, токен выпущен для другого сервиса"
+	default:
+		return "Ошибка доступа, неверный токен"
+	}
+}
+
 // publicMiddleware - промежуточное ПО для публичных запросов
-func (route Router) publicMiddleware(next http.Handler) http.Handler {
+func (route *Router) publicMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,153 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/subscription"
+	"apiGateway/pkg/token"
+	"apiGateway/pkg/utilities"
+	"errors"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// ownerResolver - определяет ID пользователя, которому принадлежит ресурс из запроса
+type ownerResolver func(r *http.Request) (uint64, error)
+
+// ownedBy - оборачивает обработчик проверкой владельца ресурса: доступ разрешен самому владельцу, администратору
+// или ключу API с областью группы маршрута. Должен вызываться после authMiddleware
+func (route *Router) ownedBy(resolve ownerResolver, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := r.Context().Value("user").(token.IUser)
+		if !ok {
+			SetHTTPError(w, "В доступе отказано", http.StatusForbidden)
+			return
+		}
+
+		if privileged(user) {
+			next(w, r)
+			return
+		}
+
+		ownerId, err := resolve(r)
+		if err != nil {
+			logger.Error("Ошибка при определении владельца ресурса: %v", err)
+			SetGRPCError(w, err)
+			return
+		}
+
+		if !isOwner(user, ownerId) {
+			logger.Warn("Отказано в доступе: %s [%s], пользователь %d не является владельцем ресурса",
+				r.URL.String(), r.Method, user.GetUserId())
+			SetHTTPError(w, "Нет доступа к ресурсу другого пользователя", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// isOwner - проверяет, что пользователь является владельцем ресурса, администратором или ключом API
+func isOwner(user token.IUser, ownerId uint64) bool {
+	return privileged(user) || (ownerId != 0 && user.GetUserId() == ownerId)
+}
+
+// privileged - доступ к ресурсам любого владельца: администратор или ключ API, область которого
+// проверена в authMiddleware
+func privileged(user token.IUser) bool {
+	return user.GetRole() == RoleAdmin || user.GetRole() == RoleService
+}
+
+// allowOwner - проверяет владельца ресурса, переданного в теле запроса, при отказе формирует ответ 403
+func (route *Router) allowOwner(w http.ResponseWriter, r *http.Request, ownerId uint64) bool {
+	user, ok := r.Context().Value("user").(token.IUser)
+	if !ok || !isOwner(user, ownerId) {
+		SetHTTPError(w, "Нет доступа к ресурсу другого пользователя", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// userFromPath - владелец ресурса, ID пользователя указан в пути запроса
+func (route *Router) userFromPath(r *http.Request) (uint64, error) {
+	return utilities.StrToUint(mux.Vars(r)["id"]), nil
+}
+
+// cardOwner - владелец банковской карты пользователя с ID из пути запроса
+func (route *Router) cardOwner(r *http.Request) (uint64, error) {
+	card, err := route.databaseService.FindCardById(r.Context(),
+		&DatabaseServicev1.FindCardByIdRequest{Id: utilities.StrToUint(mux.Vars(r)["id"])})
+	if err != nil {
+		return 0, err
+	}
+
+	return card.GetUserId(), nil
+}
+
+// companyOwner - владелец компании с ID из пути запроса
+func (route *Router) companyOwner(r *http.Request) (uint64, error) {
+	return route.companyOwnerById(r, utilities.StrToUint(mux.Vars(r)["id"]))
+}
+
+// cardCompanyOwner - владелец компании, которой принадлежит банковская карта с ID из пути запроса
+func (route *Router) cardCompanyOwner(r *http.Request) (uint64, error) {
+	card, err := route.databaseService.FindCardCompanyByID(r.Context(),
+		&DatabaseServicev1.FindCardCompanyByIDRequest{Id: utilities.StrToUint(mux.Vars(r)["id"])})
+	if err != nil {
+		return 0, err
+	}
+
+	return route.companyOwnerById(r, card.GetCompanyId())
+}
+
+// companyOwnerById - владелец компании по ее ID
+func (route *Router) companyOwnerById(r *http.Request, companyId uint64) (uint64, error) {
+	company, err := route.databaseService.FindCompanyById(r.Context(),
+		&DatabaseServicev1.FindCompanyByIdRequest{Id: companyId})
+	if err != nil {
+		return 0, err
+	}
+
+	return company.GetUserId(), nil
+}
+
+// donationOwner - пользователь, сделавший пожертвование с ID из пути запроса
+func (route *Router) donationOwner(r *http.Request) (uint64, error) {
+	donation, err := route.databaseService.FindDonationById(r.Context(),
+		&DatabaseServicev1.FindDonationByIdRequest{Id: utilities.StrToUint(mux.Vars(r)["id"])})
+	if err != nil {
+		return 0, err
+	}
+
+	return donation.GetUserId(), nil
+}
+
+// paymentOwner - пользователь, выполнивший платеж с ID из пути запроса
+func (route *Router) paymentOwner(r *http.Request) (uint64, error) {
+	p, err := route.ledger.Get(r.Context(), mux.Vars(r)["id"])
+	if errors.Is(err, payment.ErrPaymentNotFound) {
+		return 0, status.Error(codes.NotFound, "Платеж не найден")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return p.UserId, nil
+}
+
+// subscriptionOwner - владелец подписки с ID из пути запроса
+func (route *Router) subscriptionOwner(r *http.Request) (uint64, error) {
+	s, err := route.subscriptions.Get(r.Context(), mux.Vars(r)["id"])
+	if errors.Is(err, subscription.ErrNotFound) {
+		return 0, status.Error(codes.NotFound, "Подписка не найдена")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return s.UserId, nil
+}
@@ -0,0 +1,362 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/money"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/saga"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	paymentSagaName = "payment"        // Сага платежа в журнале
+	settleSagaName  = "payment.settle" // Сага подтверждения платежа уведомлением провайдера
+)
+
+var (
+	errAlreadySettled   = errors.New("платеж уже подтвержден или отменен")
+	errPaymentUnread    = errors.New("платеж выполнен, но не прочитан из реестра")
+	errCollectedInexact = errors.New("собранная сумма подопечного превысит точность хранения DatabaseService")
+)
+
+// paymentData - данные саги платежа, сохраняются в журнал после каждого шага
+type paymentData struct {
+	PaymentId     string       `json:"paymentId"` // ID платежа в реестре
+	UserId        uint64       `json:"userId"`
+	WardId        uint64       `json:"wardId"`
+	CardId        uint64       `json:"cardId"` // Данные карты в журнал не попадают, они загружаются на шаге authorize
+	Cvv           string       `json:"-"`      // CVV нужен только для authorize и не записывается в журнал
+	Title         string       `json:"title"`
+	Amount        money.Amount `json:"amount"`
+	Provider      string       `json:"provider,omitempty"`      // Платежный провайдер транзакции
+	TransactionId string       `json:"transactionId,omitempty"` // ID транзакции у провайдера, нужен для компенсации
+	Pending       bool         `json:"pending,omitempty"`       // Провайдер подтвердит платеж уведомлением
+	DonationId    uint64       `json:"donationId,omitempty"`    // ID созданного пожертвования, нужен для компенсации
+}
+
+// newPaymentSaga - сага платежа: запись платежа в реестр (компенсация - платеж отмечается неуспешным),
+// блокировка суммы на карте (компенсация - возврат или снятие блокировки), списание, создание пожертвования
+// (компенсация - удаление пожертвования), увеличение собранной суммы подопечного (компенсация - уменьшение)
+// и завершение платежа. Если провайдер подтверждает платеж асинхронно, шаги после authorize пропускаются,
+// платеж остается в состоянии pending до уведомления провайдера (см. newSettleSaga). Прерванные authorize,
+// createDonation и updateWard нельзя безопасно повторить или отменить (их результат не записан в журнал),
+// такая сага блокируется до ручной сверки
+func (route *Router) newPaymentSaga(journal saga.Journal) *saga.Saga[paymentData] {
+	return saga.New(paymentSagaName, journal,
+		saga.Step[paymentData]{
+			// failPaymentStep пропускает платеж, которого нет в реестре
+			Name:              "open",
+			Action:            route.openPaymentStep,
+			Compensate:        route.failPaymentStep,
+			CompensateInDoubt: true,
+		},
+		saga.Step[paymentData]{
+			// CVV не записывается в журнал, поэтому после перезапуска блокировку суммы повторить нельзя
+			Name:        "authorize",
+			Action:      route.authorizeStep,
+			Compensate:  route.refundStep,
+			Interactive: true,
+		},
+		saga.Step[paymentData]{
+			// Отдельная компенсация не нужна: refundStep возвращает и списанную сумму
+			Name:              "capture",
+			Action:            unlessPending(route.captureStep),
+			CompensateInDoubt: true,
+		},
+		saga.Step[paymentData]{
+			Name:       "createDonation",
+			Action:     unlessPending(route.createDonationStep),
+			Compensate: route.deleteDonationStep,
+		},
+		saga.Step[paymentData]{
+			Name:       "updateWard",
+			Action:     unlessPending(route.updateWardStep),
+			Compensate: unlessPending(route.revertWardStep),
+		},
+		saga.Step[paymentData]{
+			Name:   "complete",
+			Action: unlessPending(route.completePaymentStep),
+			Retry:  true,
+		},
+	)
+}
+
+// newSettleSaga - сага подтверждения платежа, который провайдер списал асинхронно: перевод платежа в состояние
+// processing (компенсация - платеж отмечается неуспешным), проверка транзакции у провайдера (компенсация -
+// возврат), создание пожертвования, увеличение собранной суммы подопечного (компенсация - уменьшение)
+// и завершение платежа
+func (route *Router) newSettleSaga(journal saga.Journal) *saga.Saga[paymentData] {
+	return saga.New(settleSagaName, journal,
+		saga.Step[paymentData]{
+			Name:       "claim",
+			Action:     route.claimPaymentStep,
+			Compensate: route.failPaymentStep,
+		},
+		saga.Step[paymentData]{
+			Name:       "confirm",
+			Action:     route.confirmStep,
+			Compensate: route.refundStep,
+			Retry:      true,
+		},
+		saga.Step[paymentData]{
+			Name:       "createDonation",
+			Action:     route.createDonationStep,
+			Compensate: route.deleteDonationStep,
+		},
+		saga.Step[paymentData]{
+			Name:       "updateWard",
+			Action:     route.updateWardStep,
+			Compensate: route.revertWardStep,
+		},
+		saga.Step[paymentData]{
+			Name:   "complete",
+			Action: route.completePaymentStep,
+			Retry:  true,
+		},
+	)
+}
+
+// unlessPending - пропускает шаг, пока провайдер не подтвердил платеж
+func unlessPending(action func(ctx context.Context, data *paymentData) error) func(context.Context, *paymentData) error {
+	return func(ctx context.Context, data *paymentData) error {
+		if data.Pending {
+			return nil
+		}
+		return action(ctx, data)
+	}
+}
+
+// openPaymentStep - записывает платеж в реестр в состоянии pending
+func (route *Router) openPaymentStep(ctx context.Context, data *paymentData) error {
+	return route.ledger.Create(ctx, payment.Payment{
+		Id:       data.PaymentId,
+		Status:   payment.PaymentPending,
+		Provider: route.provider.Name(),
+		UserId:   data.UserId,
+		WardId:   data.WardId,
+		CardId:   data.CardId,
+		Title:    data.Title,
+		Money:    money.New(data.Amount, route.cfg.Payment.Currency),
+	})
+}
+
+// failPaymentStep - отмечает платеж неуспешным, повторный вызов не меняет платеж
+func (route *Router) failPaymentStep(ctx context.Context, data *paymentData) error {
+	_, err := route.ledger.Update(ctx, data.PaymentId, func(p *payment.Payment) error {
+		if p.Status == payment.PaymentFailed {
+			return nil
+		}
+		return p.Transition(payment.PaymentFailed)
+	})
+	if errors.Is(err, payment.ErrPaymentNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// claimPaymentStep - переводит платеж из pending в processing. Если платеж уже подтвержден или отменен,
+// возвращает errAlreadySettled, поэтому одно и то же списание не записывается дважды
+func (route *Router) claimPaymentStep(ctx context.Context, data *paymentData) error {
+	_, err := route.ledger.Update(ctx, data.PaymentId, func(p *payment.Payment) error {
+		if p.Status != payment.PaymentPending {
+			return errAlreadySettled
+		}
+		return p.Transition(payment.PaymentProcessing)
+	})
+
+	return err
+}
+
+// confirmStep - проверяет у провайдера, что средства по транзакции действительно списаны
+func (route *Router) confirmStep(ctx context.Context, data *paymentData) error {
+	transaction, err := route.provider.Status(ctx, data.TransactionId)
+	if err != nil {
+		return err
+	}
+
+	if transaction.Status != payment.StatusCaptured {
+		return fmt.Errorf("%w: транзакция %s в состоянии %s", payment.ErrInvalidState, transaction.Id,
+			transaction.Status)
+	}
+
+	return nil
+}
+
+// completePaymentStep - отмечает платеж успешным и сохраняет ID пожертвования
+func (route *Router) completePaymentStep(ctx context.Context, data *paymentData) error {
+	_, err := route.ledger.Update(ctx, data.PaymentId, func(p *payment.Payment) error {
+		if p.Status == payment.PaymentSucceeded {
+			return nil
+		}
+		p.DonationId = data.DonationId
+		return p.Transition(payment.PaymentSucceeded)
+	})
+
+	return err
+}
+
+// authorizeStep - блокирует сумму платежа на карте пользователя
+func (route *Router) authorizeStep(ctx context.Context, data *paymentData) error {
+	card, err := route.databaseService.FindCardById(ctx, &DatabaseServicev1.FindCardByIdRequest{Id: data.CardId})
+	if err != nil {
+		return err
+	}
+
+	number, err := route.cardPan(ctx, card.GetNumber())
+	if err != nil {
+		return err
+	}
+
+	transaction, err := route.provider.Authorize(ctx, payment.AuthorizeRequest{
+		Card: payment.Card{
+			Number: number,
+			Holder: card.GetFullName(),
+			Expiry: card.GetDate(),
+			Cvv:    data.Cvv,
+		},
+		Amount:      int64(data.Amount),
+		Currency:    route.cfg.Payment.Currency,
+		Description: data.Title,
+		Reference:   data.PaymentId,
+	})
+	if err != nil {
+		return err
+	}
+
+	data.Provider = route.provider.Name()
+	data.TransactionId = transaction.Id
+	data.Pending = transaction.Status == payment.StatusPending
+
+	_, err = route.ledger.Update(ctx, data.PaymentId, func(p *payment.Payment) error {
+		p.TransactionId = transaction.Id
+		return nil
+	})
+
+	return err
+}
+
+// captureStep - списывает заблокированную сумму
+func (route *Router) captureStep(ctx context.Context, data *paymentData) error {
+	_, err := route.provider.Capture(ctx, data.TransactionId, 0)
+	return err
+}
+
+// refundStep - возвращает списанную сумму или снимает блокировку, созданную authorizeStep
+func (route *Router) refundStep(ctx context.Context, data *paymentData) error {
+	if data.TransactionId == "" {
+		return nil
+	}
+
+	_, err := route.provider.Refund(ctx, data.TransactionId, 0)
+	if !errors.Is(err, payment.ErrInvalidState) {
+		return err
+	}
+
+	// Повтор компенсации или транзакция, отклоненная провайдером: возвращать нечего
+	transaction, serr := route.provider.Status(ctx, data.TransactionId)
+	if serr == nil && (transaction.Status == payment.StatusRefunded || transaction.Status == payment.StatusFailed) {
+		return nil
+	}
+
+	return err
+}
+
+// createDonationStep - создает пожертвование
+func (route *Router) createDonationStep(ctx context.Context, data *paymentData) error {
+	donation, err := route.databaseService.CreateDonations(ctx, &DatabaseServicev1.CreateDonationsRequest{
+		Title:  data.Title,
+		Amount: data.Amount.Float32(),
+		WardId: data.WardId,
+		UserId: data.UserId,
+	})
+	if err != nil {
+		return err
+	}
+
+	data.DonationId = donation.GetId()
+
+	return nil
+}
+
+// deleteDonationStep - удаляет пожертвование, созданное createDonationStep
+func (route *Router) deleteDonationStep(ctx context.Context, data *paymentData) error {
+	if data.DonationId == 0 {
+		return nil
+	}
+
+	_, err := route.databaseService.DeleteDonationById(ctx,
+		&DatabaseServicev1.DeleteDonationByIdRequest{Id: data.DonationId})
+
+	return err
+}
+
+// updateWardStep - увеличивает собранную сумму подопечного на сумму пожертвования. Пожертвование, после которого
+// DatabaseService округлил бы собранную сумму, отклоняется: округление потеряло бы часть пожертвований
+func (route *Router) updateWardStep(ctx context.Context, data *paymentData) error {
+	_, err := route.changeWard(ctx, data.WardId, func(ward *DatabaseServicev1.Ward) error {
+		if collected := money.FromFloat32(ward.GetCollected()) + data.Amount; !collected.Exact32() {
+			return fmt.Errorf("%w: подопечный %d, сумма %s", errCollectedInexact, ward.GetId(), collected)
+		}
+		addCollected(ward, data.Amount)
+		return nil
+	})
+
+	return err
+}
+
+// revertWardStep - уменьшает собранную сумму подопечного на сумму пожертвования, отменяет updateWardStep
+func (route *Router) revertWardStep(ctx context.Context, data *paymentData) error {
+	_, err := route.changeWard(ctx, data.WardId, func(ward *DatabaseServicev1.Ward) error {
+		addCollected(ward, -data.Amount)
+		return nil
+	})
+
+	return err
+}
+
+// addCollected - изменяет собранную сумму подопечного на delta. Сумма считается в минимальных единицах валюты,
+// а DatabaseService хранит ее во float32: увеличение сверх точности float32 отклоняет updateWardStep, а об
+// уменьшении и откатах, которые нельзя отклонить, пишется в лог
+func addCollected(ward *DatabaseServicev1.Ward, delta money.Amount) {
+	collected := money.FromFloat32(ward.GetCollected()) + delta
+	if !collected.Exact32() {
+		logger.Warn("Подопечный %d: собранная сумма %s будет округлена DatabaseService", ward.GetId(), collected)
+	}
+
+	ward.Collected = collected.Float32()
+}
+
+// recoverPayments - при запуске и затем каждые recovery_interval продолжает или откатывает саги платежей и возвратов,
+// прерванные перезапуском шлюза или ошибкой отката. Завершается при отмене ctx
+func (route *Router) recoverPayments(ctx context.Context) {
+	interval := route.cfg.PaymentSaga.RecoveryInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := route.payments.Recover(ctx); err != nil {
+			logger.Error("Ошибка при восстановлении саг платежей: %v", err)
+		}
+		if err := route.settlements.Recover(ctx); err != nil {
+			logger.Error("Ошибка при восстановлении саг подтверждения платежей: %v", err)
+		}
+		if err := route.refunds.Recover(ctx); err != nil {
+			logger.Error("Ошибка при восстановлении саг возврата платежей: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
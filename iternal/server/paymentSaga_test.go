@@ -0,0 +1,183 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/saga"
+	"context"
+	"encoding/json"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"testing"
+)
+
+func TestPaymentCompensation(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: "4111111111111111", UserId: 1})
+	db.updateWardErr = status.Error(codes.Unavailable, "database unavailable")
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token, `{"toWardId":5,"amount":100}`)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("code = %d, want %d, body = %s", rec.Code, http.StatusServiceUnavailable, rec.Body)
+	}
+
+	// Пожертвование, созданное первым шагом, удалено компенсацией
+	if got := db.donationCount(); got != 0 {
+		t.Errorf("осталось пожертвований: %d, want 0", got)
+	}
+	if got := db.ward(5).GetCollected(); got != 0 {
+		t.Errorf("собрано: %v, want 0", got)
+	}
+
+	// Списанная сумма возвращена на карту
+	transaction, err := route.provider.Status(context.Background(), "fake_1")
+	if err != nil || transaction.Status != payment.StatusRefunded {
+		t.Errorf("транзакция: %+v, %v, want статус %s", transaction, err, payment.StatusRefunded)
+	}
+}
+
+// failingLedger - реестр, в котором нельзя сохранить платеж, для которого fail возвращает true
+type failingLedger struct {
+	payment.Ledger
+	fail func(p *payment.Payment) bool
+}
+
+func (l failingLedger) Update(ctx context.Context, id string,
+	change func(payment *payment.Payment) error) (payment.Payment, error) {
+	return l.Ledger.Update(ctx, id, func(p *payment.Payment) error {
+		if err := change(p); err != nil {
+			return err
+		}
+		if l.fail(p) {
+			return errors.New("ledger unavailable")
+		}
+		return nil
+	})
+}
+
+func TestPaymentCompensationAfterWardUpdate(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000, Collected: 300})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: "4111111111111111", UserId: 1})
+	route, _ := newTestRouter(t, db)
+	route.ledger = failingLedger{Ledger: route.ledger, fail: func(p *payment.Payment) bool {
+		return p.Status == payment.PaymentSucceeded
+	}}
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serveWith(route, http.MethodPost, "/api/v1/payment", "Bearer "+tokens.Token, `{"toWardId":5,"amount":100}`)
+	if rec.Code == http.StatusOK {
+		t.Fatalf("code = %d, платеж не должен завершиться", rec.Code)
+	}
+
+	// Сумма, добавленная подопечному до ошибки завершения платежа, вычтена компенсацией
+	if got := db.ward(5).GetCollected(); got != 300 {
+		t.Errorf("собрано: %v, want 300", got)
+	}
+	if got := db.donationCount(); got != 0 {
+		t.Errorf("осталось пожертвований: %d, want 0", got)
+	}
+
+	transaction, err := route.provider.Status(context.Background(), "fake_1")
+	if err != nil || transaction.Status != payment.StatusRefunded {
+		t.Errorf("транзакция: %+v, %v, want статус %s", transaction, err, payment.StatusRefunded)
+	}
+}
+
+func TestPaymentRecoverBeforeAuthorize(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDatabase()
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: "4111111111111111", UserId: 1})
+	route, _ := newTestRouter(t, db)
+
+	journal := saga.NewMemoryJournal()
+	route.payments = route.newPaymentSaga(journal)
+
+	// Шлюз остановился после записи платежа в реестр, до блокировки суммы: CVV в журнале нет
+	data := &paymentData{PaymentId: "payment1", UserId: 1, WardId: 5, CardId: 7, Amount: 10000}
+	if err := route.openPaymentStep(ctx, data); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = journal.Save(ctx, saga.Record{Id: "saga1", Name: paymentSagaName, State: saga.StateRunning, Step: 1,
+		Data: raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = route.payments.Recover(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Платеж не продолжается без CVV, а отмечается неуспешным
+	p, err := route.ledger.Get(ctx, "payment1")
+	if err != nil || p.Status != payment.PaymentFailed || p.TransactionId != "" {
+		t.Errorf("платеж после восстановления: %+v, %v", p, err)
+	}
+	if records, _ := journal.List(ctx); len(records) != 0 {
+		t.Errorf("после восстановления в журнале %d записей", len(records))
+	}
+}
+
+func TestPaymentRecoverInDoubtWardUpdate(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDatabase()
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 1000, Collected: 100})
+	route, _ := newTestRouter(t, db)
+
+	journal := saga.NewMemoryJournal()
+	route.payments = route.newPaymentSaga(journal)
+
+	donation, err := db.CreateDonations(ctx, &DatabaseServicev1.CreateDonationsRequest{Amount: 100, WardId: 5,
+		UserId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Шлюз остановился во время увеличения собранной суммы: неизвестно, применено ли изменение
+	raw, err := json.Marshal(&paymentData{PaymentId: "payment1", UserId: 1, WardId: 5, CardId: 7, Amount: 10000,
+		TransactionId: "fake_1", DonationId: donation.GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = journal.Save(ctx, saga.Record{Id: "saga1", Name: paymentSagaName, State: saga.StateRunning, Step: 4,
+		InDoubt: true, Data: raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = route.payments.Recover(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Сага не откатывается частично: пожертвование и сумма подопечного остаются до ручной сверки
+	if got := db.donationCount(); got != 1 {
+		t.Errorf("пожертвований: %d, want 1", got)
+	}
+	if got := db.ward(5).GetCollected(); got != 100 {
+		t.Errorf("собрано: %v, want 100", got)
+	}
+	records, _ := journal.List(ctx)
+	if len(records) != 1 || records[0].State != saga.StateBlocked {
+		t.Errorf("журнал после восстановления: %+v", records)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/token"
+	"net/http"
+)
+
+// Роли пользователей, хранящиеся в поле role токена JWT
+const (
+	RoleAdmin     = "admin"     // Администратор, полный доступ
+	RoleModerator = "moderator" // Модератор, управление подопечными и просмотр справочников
+	RoleUser      = "user"      // Пользователь, роль по умолчанию при регистрации
+	RoleService   = "service"   // Ключ API, доступные группы маршрутов определяются областями ключа
+)
+
+// policy - набор ролей, которым разрешен доступ к маршруту
+type policy []string
+
+// Политики доступа, назначаемые маршрутам в loadEndpoints. Ключам API административные маршруты недоступны
+// независимо от областей ключа
+var (
+	adminOnly  = policy{RoleAdmin}
+	moderators = policy{RoleAdmin, RoleModerator, RoleService}
+	anyUser    = policy{RoleAdmin, RoleModerator, RoleUser, RoleService}
+)
+
+// allows - проверяет, разрешен ли доступ для роли
+func (p policy) allows(role string) bool {
+	for _, allowed := range p {
+		if allowed == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wrap - оборачивает обработчик проверкой роли из токена JWT, должен вызываться после authMiddleware
+func (p policy) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := r.Context().Value("user").(token.IUser)
+		if !ok || !p.allows(user.GetRole()) {
+			if ok {
+				logger.Warn("Отказано в доступе: %s [%s], пользователь %d с ролью %q", r.URL.String(), r.Method,
+					user.GetUserId(), user.GetRole())
+			}
+			SetHTTPError(w, "Недостаточно прав для выполнения операции", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
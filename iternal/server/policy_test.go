@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// rolePrincipal - пользователь с заданной ролью для проверки политик доступа
+type rolePrincipal struct {
+	role string
+}
+
+func (p *rolePrincipal) GetUserId() uint64 {
+	return 1
+}
+
+func (p *rolePrincipal) GetRole() string {
+	return p.role
+}
+
+func (p *rolePrincipal) GetSessionId() uint64 {
+	return 1
+}
+
+func TestPolicyWrap(t *testing.T) {
+	policies := []struct {
+		name   string
+		policy policy
+	}{
+		{name: "adminOnly", policy: adminOnly},
+		{name: "moderators", policy: moderators},
+		{name: "anyUser", policy: anyUser},
+	}
+
+	const ok, denied = http.StatusOK, http.StatusForbidden
+
+	// want - коды ответа для политик в порядке policies, пустая роль - запрос без пользователя в контексте
+	tests := []struct {
+		name string
+		role string
+		want [3]int
+	}{
+		{name: "admin", role: RoleAdmin, want: [3]int{ok, ok, ok}},
+		{name: "moderator", role: RoleModerator, want: [3]int{denied, ok, ok}},
+		{name: "user", role: RoleUser, want: [3]int{denied, denied, ok}},
+		{name: "service", role: RoleService, want: [3]int{denied, ok, ok}},
+		{name: "Неизвестная роль", role: "guest", want: [3]int{denied, denied, denied}},
+		{name: "Нет пользователя", role: "", want: [3]int{denied, denied, denied}},
+	}
+
+	for _, tt := range tests {
+		for i, p := range policies {
+			t.Run(p.name+"/"+tt.name, func(t *testing.T) {
+				called := false
+				handler := p.policy.wrap(func(w http.ResponseWriter, r *http.Request) {
+					called = true
+				})
+
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/wards", nil)
+				if tt.role != "" {
+					req = req.WithContext(context.WithValue(req.Context(), "user", &rolePrincipal{role: tt.role}))
+				}
+
+				rec := httptest.NewRecorder()
+				handler(rec, req)
+
+				if rec.Code != tt.want[i] {
+					t.Errorf("code = %d, want %d", rec.Code, tt.want[i])
+				}
+				if called != (tt.want[i] == ok) {
+					t.Errorf("обработчик вызван: %v", called)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/money"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/saga"
+	"context"
+	"errors"
+	"time"
+)
+
+// refundSagaName - имя саги возврата в журнале
+const refundSagaName = "payment.refund"
+
+var (
+	errNotRefundable  = errors.New("возврат доступен только для успешного платежа")
+	errRefundExceeded = errors.New("сумма возврата превышает сумму, доступную для возврата")
+)
+
+// refundData - данные саги возврата, сохраняются в журнал после каждого шага
+type refundData struct {
+	PaymentId     string       `json:"paymentId"`
+	RefundId      string       `json:"refundId"`
+	WardId        uint64       `json:"wardId"`
+	TransactionId string       `json:"transactionId"`
+	Amount        money.Amount `json:"amount"` // Сумма возврата
+	Reason        string       `json:"reason,omitempty"`
+	CreatedBy     uint64       `json:"createdBy"`
+}
+
+// newRefundSaga - сага возврата: резервирование суммы возврата в реестре (компенсация - возврат отмечается
+// неуспешным), уменьшение собранной суммы подопечного (компенсация - увеличение обратно), возврат у провайдера
+// и завершение возврата. Возврат у провайдера отменить нельзя, поэтому после него сага не откатывается:
+// завершение возврата повторяется при восстановлении. Если перезапуск прервал сам возврат у провайдера, деньги
+// могли уже вернуться, поэтому сага не откатывается, а блокируется до ручной сверки
+func (route *Router) newRefundSaga(journal saga.Journal) *saga.Saga[refundData] {
+	return saga.New(refundSagaName, journal,
+		saga.Step[refundData]{
+			Name:       "reserve",
+			Action:     route.reserveRefundStep,
+			Compensate: route.failRefundStep,
+			Retry:      true,
+		},
+		saga.Step[refundData]{
+			Name:       "updateWard",
+			Action:     route.decreaseWardStep,
+			Compensate: route.restoreWardStep,
+		},
+		saga.Step[refundData]{
+			Name:   "refund",
+			Action: route.providerRefundStep,
+			Pivot:  true,
+		},
+		saga.Step[refundData]{
+			Name:   "complete",
+			Action: route.completeRefundStep,
+			Retry:  true,
+		},
+	)
+}
+
+// reserveRefundStep - добавляет возврат в платеж, если сумма возврата не превышает остаток платежа с учетом
+// выполняющихся возвратов. Проверка и запись выполняются атомарно, поэтому параллельные возвраты не превысят сумму
+func (route *Router) reserveRefundStep(ctx context.Context, data *refundData) error {
+	_, err := route.ledger.Update(ctx, data.PaymentId, func(p *payment.Payment) error {
+		if _, err := p.Refund(data.RefundId); err == nil {
+			return nil
+		}
+
+		if p.Status != payment.PaymentSucceeded {
+			return errNotRefundable
+		}
+
+		if data.Amount > refundable(p) {
+			return errRefundExceeded
+		}
+
+		p.Refunds = append(p.Refunds, payment.Refund{
+			Id:        data.RefundId,
+			Amount:    data.Amount,
+			Status:    payment.RefundPending,
+			Reason:    data.Reason,
+			CreatedBy: data.CreatedBy,
+			CreatedAt: time.Now().UTC(),
+		})
+
+		return nil
+	})
+
+	return err
+}
+
+// failRefundStep - отмечает возврат неуспешным, сумма снова доступна для возврата
+func (route *Router) failRefundStep(ctx context.Context, data *refundData) error {
+	_, err := route.ledger.Update(ctx, data.PaymentId, func(p *payment.Payment) error {
+		refund, err := p.Refund(data.RefundId)
+		if err != nil {
+			return nil
+		}
+		refund.Status = payment.RefundFailed
+		return nil
+	})
+
+	return err
+}
+
+// decreaseWardStep - уменьшает собранную сумму подопечного на сумму возврата
+func (route *Router) decreaseWardStep(ctx context.Context, data *refundData) error {
+	_, err := route.changeWard(ctx, data.WardId, func(ward *DatabaseServicev1.Ward) error {
+		addCollected(ward, -data.Amount)
+		return nil
+	})
+
+	return err
+}
+
+// restoreWardStep - возвращает собранную сумму подопечного, уменьшенную decreaseWardStep
+func (route *Router) restoreWardStep(ctx context.Context, data *refundData) error {
+	_, err := route.changeWard(ctx, data.WardId, func(ward *DatabaseServicev1.Ward) error {
+		addCollected(ward, data.Amount)
+		return nil
+	})
+
+	return err
+}
+
+// providerRefundStep - возвращает сумму на карту через платежного провайдера
+func (route *Router) providerRefundStep(ctx context.Context, data *refundData) error {
+	_, err := route.provider.Refund(ctx, data.TransactionId, int64(data.Amount))
+	return err
+}
+
+// completeRefundStep - отмечает возврат выполненным, после полного возврата платеж переходит в состояние refunded
+func (route *Router) completeRefundStep(ctx context.Context, data *refundData) error {
+	_, err := route.ledger.Update(ctx, data.PaymentId, func(p *payment.Payment) error {
+		refund, err := p.Refund(data.RefundId)
+		if err != nil {
+			return err
+		}
+		if refund.Status == payment.RefundSucceeded {
+			return nil
+		}
+
+		refund.Status = payment.RefundSucceeded
+		p.Refunded += refund.Amount
+
+		if p.Refunded >= p.Amount {
+			return p.Transition(payment.PaymentRefunded)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// refundable - сумма платежа, которую еще можно вернуть: без выполненных и выполняющихся возвратов
+func refundable(p *payment.Payment) money.Amount {
+	available := p.Amount - p.Refunded
+	for _, refund := range p.Refunds {
+		if refund.Status == payment.RefundPending {
+			available -= refund.Amount
+		}
+	}
+
+	return available
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/rs/cors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// access - классификация доступа к маршруту
+type access int
+
+const (
+	accessPublic  access = iota + 1 // Маршрут доступен без токена, должен присутствовать в publicRoutes
+	accessPrivate                   // Маршрут доступен только с валидным токеном
+)
+
+// publicRoutes - проверенный список маршрутов, доступных без аутентификации, в формате "METHOD /path?query".
+// Любое изменение этого списка должно проходить отдельное ревью: мутирующие маршруты и маршруты,
+// проверяющие учетные данные, сюда не добавляются
+var publicRoutes = map[string]struct{}{
+	"GET /swagger/":                           {}, // Swagger-документация, включается флагом swagger
+	"POST /api/v1/auth/login":                 {}, // Вход по телефону и паролю
+	"POST /api/v1/auth/registration":          {}, // Регистрация
+	"POST /api/v1/auth/refresh":               {}, // Обновление токенов, аутентификация по refresh токену
+	"POST /api/v1/auth/password/forgot":       {}, // Запрос кода сброса пароля, ответ не раскрывает существование аккаунта
+	"POST /api/v1/auth/password/reset":        {}, // Сброс пароля, аутентификация по одноразовому коду
+	"POST /api/v1/auth/otp/request":           {}, // Запрос кода для входа по SMS, ответ не раскрывает существование аккаунта
+	"POST /api/v1/auth/otp/verify":            {}, // Вход по коду из SMS
+	"POST /api/v1/auth/2fa/login":             {}, // Второй шаг входа, аутентификация по токену второго шага
+	"POST /api/v1/users/isExists":             {}, // Проверка занятости телефона при регистрации
+	"GET /api/v1/users/{id:[0-9]+}/photo":     {}, // Фото профиля
+	"GET /api/v1/donations":                   {}, // Лента пожертвований
+	"GET /api/v1/wards":                       {}, // Каталог подопечных
+	"GET /api/v1/wards/{id:[0-9]+}":           {}, // Карточка подопечного
+	"GET /.well-known/jwks.json":              {}, // Открытые ключи для проверки токенов другими сервисами
+	"POST /api/v1/payment/webhook/{provider}": {}, // Уведомления платежного провайдера, аутентификация по подписи HMAC
+}
+
+// apiKeyGroups - группы приватных маршрутов, доступные по ключам API: префикс пути после /api/v1/ -> имя группы
+// в областях ключа. Область "группа:read" открывает GET запросы группы, "группа:write" - остальные методы.
+// Маршруты вне этого списка (аутентификация, платежи от имени пользователя, управление ключами) ключам недоступны
+var apiKeyGroups = map[string]string{
+	"users":        "users",
+	"companies":    "companies",
+	"cards":        "cards",
+	"card/company": "cardCompanies",
+	"donations":    "donations",
+	"wards":        "wards",
+}
+
+// apiKeyScopes - все допустимые области ключей API
+func apiKeyScopes() []string {
+	scopes := make([]string, 0, len(apiKeyGroups)*2)
+	for _, group := range apiKeyGroups {
+		scopes = append(scopes, group+":read", group+":write")
+	}
+	sort.Strings(scopes)
+
+	return scopes
+}
+
+// requiredScope - область ключа API, необходимая для запроса, ok = false, если маршрут ключам недоступен
+func requiredScope(r *http.Request) (scope string, ok bool) {
+	current := mux.CurrentRoute(r)
+	if current == nil {
+		return "", false
+	}
+
+	path, err := current.GetPathTemplate()
+	if err != nil {
+		return "", false
+	}
+	path = strings.TrimPrefix(path, apiStr)
+
+	for prefix, group := range apiKeyGroups {
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return group + ":read", true
+		}
+		return group + ":write", true
+	}
+
+	return "", false
+}
+
+// privateRouter - создает подмаршрутизатор API, все маршруты которого доступны только с валидным токеном
+func (route *Router) privateRouter(endpoint string) *mux.Router {
+	return route.subrouter(getEndpoint(endpoint), accessPrivate)
+}
+
+// publicRouter - создает подмаршрутизатор API для маршрутов, доступных без аутентификации
+func (route *Router) publicRouter(endpoint string) *mux.Router {
+	return route.subrouter(getEndpoint(endpoint), accessPublic)
+}
+
+// subrouter - создает подмаршрутизатор с префиксом prefix и промежуточным ПО, соответствующим классификации доступа
+func (route *Router) subrouter(prefix string, acc access) *mux.Router {
+	sub := route.r.PathPrefix(prefix).Subrouter()
+
+	switch acc {
+	case accessPrivate:
+		sub.Use(cors.Default().Handler, route.authMiddleware)
+	case accessPublic:
+		sub.Use(cors.Default().Handler, route.publicMiddleware)
+	}
+
+	route.routers[sub] = acc
+
+	return sub
+}
+
+// handle - регистрирует обработчик на подмаршрутизаторе, маршрут наследует классификацию доступа подмаршрутизатора.
+// Маршруты, зарегистрированные в обход privateRouter/publicRouter, не проходят проверку checkAccess
+func (route *Router) handle(sub *mux.Router, path string, handler http.HandlerFunc, methods ...string) *mux.Route {
+	r := sub.HandleFunc(path, handler).Methods(append(methods, http.MethodOptions)...)
+	route.access[r] = route.routers[sub]
+
+	return r
+}
+
+// routeKey - ключ маршрута в формате списка publicRoutes
+func routeKey(r *mux.Route, method string) string {
+	path, err := r.GetPathTemplate()
+	if err != nil {
+		path = "<unknown>"
+	}
+
+	key := fmt.Sprintf("%s %s", method, path)
+
+	if queries, err := r.GetQueriesTemplates(); err == nil && len(queries) > 0 {
+		sort.Strings(queries)
+		key = fmt.Sprintf("%s?%s", key, strings.Join(queries, "&"))
+	}
+
+	return key
+}
+
+// checkAccess - проверяет таблицу маршрутов: у каждого обработчика должна быть классификация доступа,
+// а каждый публичный маршрут должен присутствовать в списке publicRoutes
+func (route *Router) checkAccess() error {
+	return route.r.Walk(func(r *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		// Подмаршрутизаторы не имеют собственного обработчика
+		if r.GetHandler() == nil {
+			return nil
+		}
+
+		methods, err := r.GetMethods()
+		if err != nil {
+			return fmt.Errorf("маршрут %s зарегистрирован без списка методов", routeKey(r, "*"))
+		}
+
+		acc := route.access[r]
+
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+
+			key := routeKey(r, method)
+
+			if acc == 0 {
+				return fmt.Errorf("маршрут %s зарегистрирован без классификации доступа", key)
+			}
+
+			if _, allowed := publicRoutes[key]; acc == accessPublic && !allowed {
+				return fmt.Errorf("публичный маршрут %s отсутствует в списке publicRoutes", key)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/config"
+	"github.com/gorilla/mux"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCheckAccess(t *testing.T) {
+	cfg := &config.Config{Swagger: true}
+
+	tests := []struct {
+		name    string
+		prepare func(route *Router)
+		wantErr bool
+	}{
+		{
+			name:    "Таблица маршрутов шлюза",
+			prepare: func(route *Router) {},
+		},
+		{
+			name: "Маршрут без классификации доступа",
+			prepare: func(route *Router) {
+				route.r.HandleFunc("/api/v1/unclassified", route.Wards).Methods(http.MethodGet)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Публичный маршрут вне списка publicRoutes",
+			prepare: func(route *Router) {
+				route.handle(route.publicRouter("wards"), "", route.CreateWard, http.MethodPost)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := newRouter(cfg, nil, nil)
+			route.loadEndpoints()
+			tt.prepare(route)
+
+			err := route.checkAccess()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkAccess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// sensitiveBodies - тела запросов, в которых заполнены поля всех запросов шлюза, чтобы обработчики доходили
+// до вызова DatabaseService. Поля card и amount в разных запросах имеют разный тип, поэтому тел несколько
+var sensitiveBodies = []string{
+	`{"id":1,"userId":1,"wardId":1,"companyId":1,"title":"1","amount":"1.00","phone":"+79990000001",
+"email":"user@example.com","password":"Password1!","username":"1","fullName":"1","number":"4111111111111111",
+"date":"12/49","card":{"userId":1,"companyId":1,"number":"5500000000000004","date":"12/49"},
+"company":{"id":1,"userId":1},"type":1}`,
+	`{"id":1,"userId":1,"wardId":1,"title":"1","amount":1,"phone":"+79990000001","email":"user@example.com",
+"password":"Password1!","username":"1","card":[{"userId":1}],"type":1}`,
+}
+
+func TestNoSensitiveFields(t *testing.T) {
+	db := DatabaseServicev1.NewDatabaseServiceClient(sensitiveConn{})
+	route, _ := newTestRouter(t, db)
+
+	admin := &DatabaseServicev1.CreateUserResponse{Id: 1, Role: RoleAdmin}
+	accessToken, err := route.tokens.CreateToken(admin, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Маршруты, которые отдают карты и пользователей, должны ответить успешно, иначе проверка ничего не доказывает
+	mustSucceed := map[string]bool{
+		"GET /api/v1/users":             false,
+		"POST /api/v1/users":            false,
+		"PUT /api/v1/users/{id:[0-9]+}": false,
+		"POST /api/v1/users/addCard":    false,
+		"GET /api/v1/users/":            false,
+		"GET /api/v1/users/{id:[0-9]+}": false,
+		"GET /api/v1/cards":             false,
+		"GET /api/v1/cards/shared":      false,
+		"GET /api/v1/card/company":      false,
+		"GET /api/v1/companies":         false,
+	}
+
+	vars := regexp.MustCompile(`\{[^}]+}`)
+
+	err = route.r.Walk(func(r *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if r.GetHandler() == nil {
+			return nil
+		}
+
+		template, err := r.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := r.GetMethods()
+		if err != nil {
+			return err
+		}
+
+		path := vars.ReplaceAllString(template, "1")
+		if queries, err := r.GetQueriesTemplates(); err == nil {
+			path += "?" + vars.ReplaceAllString(strings.Join(queries, "&"), "1")
+		}
+
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+
+			key := method + " " + template
+			for _, body := range sensitiveBodies {
+				rec := serveWith(route, method, path, "Bearer "+accessToken, body)
+
+				for _, secret := range []string{sensitivePan, sensitivePasswordHash, `"cvv"`, `"password"`} {
+					if strings.Contains(rec.Body.String(), secret) {
+						t.Errorf("%s: ответ содержит %s: %s", key, secret, rec.Body.String())
+					}
+				}
+
+				if _, ok := mustSucceed[key]; ok && rec.Code == http.StatusOK {
+					mustSucceed[key] = true
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, ok := range mustSucceed {
+		if !ok {
+			t.Errorf("%s: маршрут не ответил успешно", key)
+		}
+	}
+}
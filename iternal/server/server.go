@@ -4,35 +4,270 @@ import (
 	_ "apiGateway/docs"
 	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
 	"apiGateway/iternal/grpc"
+	"apiGateway/pkg/apikey"
 	"apiGateway/pkg/config"
+	"apiGateway/pkg/idempotency"
+	"apiGateway/pkg/listing"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/mfa"
+	"apiGateway/pkg/money"
+	"apiGateway/pkg/notifier"
+	"apiGateway/pkg/onetime"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/saga"
+	"apiGateway/pkg/subscription"
+	"apiGateway/pkg/throttle"
+	"apiGateway/pkg/token"
+	"apiGateway/pkg/vault"
+	"apiGateway/pkg/verification"
+	"context"
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"net/http"
+	"strings"
 	"sync"
 )
 
 // Router - сущность маршрутизатора, содержит приватные поля для работы исключительно внутри пакета
 type Router struct {
-	r               *mux.Router
-	mu              sync.Mutex
-	databaseService DatabaseServicev1.DatabaseServiceClient
-	cfg             *config.Config
+	r                *mux.Router
+	mu               sync.Mutex
+	databaseService  DatabaseServicev1.DatabaseServiceClient
+	cfg              *config.Config
+	tokens           *token.Issuer
+	phoneAttempts    *throttle.Guard         // Неудачные попытки входа по номеру телефона
+	ipAttempts       *throttle.Guard         // Неудачные попытки входа по IP адресу
+	otpPhoneRequests *throttle.Guard         // Запросы кода для входа по SMS по номеру телефона
+	otpIpRequests    *throttle.Guard         // Запросы кодов входа по SMS и сброса пароля по IP адресу
+	notifier         notifier.Notifier       // Доставка одноразовых кодов
+	codes            *onetime.Codes          // Одноразовые коды подтверждения
+	verifications    verification.Store      // Подтвержденные телефоны и email
+	authenticator    *mfa.Authenticator      // Двухфакторная аутентификация (TOTP)
+	apiKeys          *apikey.Manager         // Ключи API для межсервисных запросов
+	idempotency      *idempotency.Keeper     // Ответы на запросы с заголовком Idempotency-Key
+	payments         *saga.Saga[paymentData] // Сага платежа: пожертвование и сумма подопечного
+	settlements      *saga.Saga[paymentData] // Сага подтверждения платежа уведомлением провайдера
+	refunds          *saga.Saga[refundData]  // Сага возврата платежа
+	provider         payment.Provider        // Платежный провайдер
+	ledger           payment.Ledger          // Реестр платежей
+	webhookEvents    *idempotency.Keeper     // Обработанные уведомления платежного провайдера
+	subscriptions    subscription.Store      // Ежемесячные пожертвования
+	scheduler        *subscription.Scheduler // Планировщик ежемесячных пожертвований
+	vault            *vault.Vault            // Номера карт, в DatabaseService передаются токены
+	lists            *listing.Cache          // Отфильтрованные и отсортированные списки для постраничной выдачи
+	routers          map[*mux.Router]access  // Классификация доступа подмаршрутизаторов
+	access           map[*mux.Route]access   // Классификация доступа зарегистрированных маршрутов
+	wardLocks        keyLocks                // Блокировки подопечных на время изменения
+	sessionLocks     keyLocks                // Блокировки сессий на время ротации refresh токена
 }
 
 const apiStr = "/api/v1/"
 
 // New - создает новый роутер для маршрутизации
 func New(cfg *config.Config, grpcClient *grpc.Api) *http.Server {
+	tokens, err := token.NewIssuer(cfg)
+	if err != nil {
+		panic(any(fmt.Errorf("ошибка при загрузке ключей JWT: %v", err)))
+	}
+
+	// Файловые хранилища не разделяются между экземплярами шлюза, а каждый экземпляр перезаписывает файл целиком
+	if stores := cfg.FileStores(); cfg.Env == config.EnvProd && len(stores) > 0 {
+		panic(any(fmt.Errorf("в окружении %s файловые хранилища не поддерживаются: %s", config.EnvProd,
+			strings.Join(stores, ", "))))
+	}
+
+	router := newRouter(cfg, grpcClient.Client, tokens)
+
+	router.notifier, err = notifier.New(cfg.Notifier)
+	if err != nil {
+		panic(any(fmt.Errorf("ошибка в настройках notifier: %v", err)))
+	}
+
+	router.codes, err = onetime.NewCodes(cfg.Codes, onetime.NewMemoryStore())
+	if err != nil {
+		panic(any(fmt.Errorf("ошибка в настройках одноразовых кодов: %v", err)))
+	}
+
+	if cfg.Verifications.Store != "" {
+		router.verifications, err = verification.NewFileStore(cfg.Verifications.Store)
+		if err != nil {
+			panic(any(fmt.Errorf("ошибка при загрузке подтверждений контактов: %v", err)))
+		}
+	} else {
+		logger.Warn("Файл подтверждений контактов не указан, подтверждения хранятся в памяти и теряются при перезапуске")
+	}
+
+	// Блокировка ключа идемпотентности продлевается, пока выполняется запрос, но должна пережить таймаут сервера
+	if cfg.Idempotency.PendingTTL <= cfg.APIServer.Timeout {
+		panic(any(fmt.Errorf("idempotency.pending_ttl (%s) должен превышать api_server.timeout (%s)",
+			cfg.Idempotency.PendingTTL, cfg.APIServer.Timeout)))
+	}
+
+	if cfg.ApiKeys.Store != "" {
+		store, err := apikey.NewFileStore(cfg.ApiKeys.Store)
+		if err != nil {
+			panic(any(fmt.Errorf("ошибка при загрузке ключей API: %v", err)))
+		}
+		router.apiKeys = apikey.NewManager(store)
+	} else {
+		logger.Warn("Файл ключей API не указан, ключи хранятся в памяти и теряются при перезапуске")
+	}
+
+	// Провайдер fake не списывает деньги и хранит транзакции в памяти: в prod пожертвования записывались бы без оплаты
+	if cfg.Env == config.EnvProd && (cfg.Payment.Provider == "" || cfg.Payment.Provider == "fake") {
+		panic(any(fmt.Errorf("в окружении %s нужен настоящий платежный провайдер (payment.provider)", config.EnvProd)))
+	}
+
+	money.SetCurrency(cfg.Payment.Currency)
+
+	router.provider, err = payment.New(cfg.Payment)
+	if err != nil {
+		panic(any(fmt.Errorf("ошибка в настройках платежного провайдера: %v", err)))
+	}
+	if router.provider.Name() == "fake" {
+		logger.Warn("Используется платежный провайдер fake, деньги с карт не списываются")
+	}
+
+	if cfg.Payment.Ledger != "" {
+		router.ledger, err = payment.NewFileLedger(cfg.Payment.Ledger)
+		if err != nil {
+			panic(any(fmt.Errorf("ошибка при загрузке реестра платежей: %v", err)))
+		}
+	} else {
+		logger.Warn("Файл реестра платежей не указан, реестр хранится в памяти и теряется при перезапуске")
+	}
+	// Значение secret было в примере конфигурации и считается известным
+	if cfg.Payment.WebhookSecret == "" || cfg.Payment.WebhookSecret == "secret" {
+		panic(any(fmt.Errorf("не указан секрет уведомлений платежного провайдера (PAYMENT_WEBHOOK_SECRET)")))
+	}
+
+	if cfg.PaymentSaga.Journal != "" {
+		journal, err := saga.NewFileJournal(cfg.PaymentSaga.Journal)
+		if err != nil {
+			panic(any(fmt.Errorf("ошибка при загрузке журнала платежей: %v", err)))
+		}
+		router.payments = router.newPaymentSaga(journal)
+		router.settlements = router.newSettleSaga(journal)
+		router.refunds = router.newRefundSaga(journal)
+	} else {
+		logger.Warn("Файл журнала платежей не указан, прерванные платежи не восстанавливаются после перезапуска")
+	}
+
+	if cfg.Subscriptions.Store != "" {
+		router.subscriptions, err = subscription.NewFileStore(cfg.Subscriptions.Store)
+		if err != nil {
+			panic(any(fmt.Errorf("ошибка при загрузке ежемесячных пожертвований: %v", err)))
+		}
+		router.scheduler = subscription.NewScheduler(cfg.Subscriptions, router.subscriptions, router.chargeSubscription)
+	} else {
+		logger.Warn("Файл ежемесячных пожертвований не указан, подписки хранятся в памяти и теряются при перезапуске")
+	}
+
+	// В prod ключи хранилища карт читаются только из отдельного файла
+	if cfg.Env == config.EnvProd && cfg.Vault.KeysFile == "" {
+		panic(any(fmt.Errorf("в окружении %s нужен файл ключей vault.keys_file (VAULT_KEYS_FILE)", config.EnvProd)))
+	}
+
+	if len(cfg.Vault.Keys) > 0 {
+		keys, err := vault.NewKeys(cfg.Vault)
+		if err != nil {
+			panic(any(fmt.Errorf("ошибка в ключах хранилища карт: %v", err)))
+		}
+
+		var store vault.Store = vault.NewMemoryStore()
+		if cfg.Vault.Store != "" {
+			if store, err = vault.NewFileStore(cfg.Vault.Store); err != nil {
+				panic(any(fmt.Errorf("ошибка при загрузке хранилища карт: %v", err)))
+			}
+		} else {
+			logger.Warn("Файл хранилища карт не указан, номера карт хранятся в памяти и теряются при перезапуске")
+		}
+		router.vault = vault.New(store, keys)
+	} else if cfg.Vault.Store != "" {
+		panic(any(fmt.Errorf("для хранилища карт %s не указаны ключи шифрования", cfg.Vault.Store)))
+	} else {
+		logger.Warn("Ключи хранилища карт не указаны, номера карт хранятся в памяти и теряются при перезапуске")
+	}
+
+	// Секреты TOTP шифруются ключами хранилища карт, со случайными ключами их нельзя прочитать после перезапуска
+	if cfg.Mfa.Store != "" {
+		if len(cfg.Vault.Keys) == 0 {
+			panic(any(fmt.Errorf("для файла настроек 2FA %s не указаны ключи хранилища карт", cfg.Mfa.Store)))
+		}
+
+		store, err := mfa.NewFileStore(cfg.Mfa.Store)
+		if err != nil {
+			panic(any(fmt.Errorf("ошибка при загрузке настроек 2FA: %v", err)))
+		}
+		router.authenticator = mfa.NewAuthenticator(cfg.Mfa.Issuer, store, router.vault.Keys())
+	} else {
+		router.authenticator = mfa.NewAuthenticator(cfg.Mfa.Issuer, mfa.NewMemoryStore(), router.vault.Keys())
+		logger.Warn("Файл настроек 2FA не указан, настройки хранятся в памяти и теряются при перезапуске")
+	}
+
+	srv := router.loadEndpoints()
+
+	if err := router.checkAccess(); err != nil {
+		panic(any(fmt.Errorf("ошибка в таблице маршрутов: %v", err)))
+	}
+
+	// Восстановление платежей и планировщик останавливаются вместе с сервером
+	ctx, cancel := context.WithCancel(context.Background())
+	srv.RegisterOnShutdown(cancel)
+	go router.recoverPayments(ctx)
+	if cfg.Subscriptions.Scheduler {
+		go router.scheduler.Run(ctx)
+	}
+
+	return srv
+}
+
+// newRouter - создает маршрутизатор без зарегистрированных маршрутов
+func newRouter(cfg *config.Config, databaseService DatabaseServicev1.DatabaseServiceClient,
+	tokens *token.Issuer) *Router {
+	// Счетчики попыток входа хранятся в памяти, при нескольких экземплярах шлюза нужна общая реализация throttle.Store
+	attempts := throttle.NewMemoryStore()
+
 	router := &Router{
-		r:               mux.NewRouter(),
-		mu:              sync.Mutex{},
-		databaseService: grpcClient.Client,
-		cfg:             cfg,
+		r:                mux.NewRouter(),
+		mu:               sync.Mutex{},
+		databaseService:  databaseService,
+		cfg:              cfg,
+		tokens:           tokens,
+		phoneAttempts:    throttle.NewGuard("phone:", cfg.LoginGuard.Phone, attempts),
+		ipAttempts:       throttle.NewGuard("ip:", cfg.LoginGuard.Ip, attempts),
+		otpPhoneRequests: throttle.NewGuard("otp:phone:", cfg.OtpLogin.Phone, attempts),
+		otpIpRequests:    throttle.NewGuard("otp:ip:", cfg.OtpLogin.Ip, attempts),
+		verifications:    verification.NewMemoryStore(),
+		apiKeys:          apikey.NewManager(apikey.NewMemoryStore()),
+		idempotency:      idempotency.NewKeeper(cfg.Idempotency, idempotency.NewMemoryStore()),
+		routers:          make(map[*mux.Router]access),
+		access:           make(map[*mux.Route]access),
+		provider:         payment.NewFake(),
+		ledger:           payment.NewMemoryLedger(),
+		lists:            listing.NewCache(cfg.Pagination.CacheSize, cfg.Pagination.CacheTTL),
+		// Повторная доставка уведомления после webhook_dedupe_ttl безопасна: платеж уже не в состоянии pending
+		webhookEvents: idempotency.NewKeeper(config.Idempotency{TTL: cfg.Payment.WebhookDedupeTTL,
+			PendingTTL: cfg.Idempotency.PendingTTL}, idempotency.NewMemoryStore()),
+	}
+
+	keys, err := vault.NewEphemeralKeys()
+	if err != nil {
+		panic(any(fmt.Errorf("ошибка при создании ключей хранилища карт: %v", err)))
 	}
+	router.vault = vault.New(vault.NewMemoryStore(), keys)
+	router.authenticator = mfa.NewAuthenticator(cfg.Mfa.Issuer, mfa.NewMemoryStore(), keys)
+
+	journal := saga.NewMemoryJournal()
+	router.payments = router.newPaymentSaga(journal)
+	router.settlements = router.newSettleSaga(journal)
+	router.refunds = router.newRefundSaga(journal)
+	router.subscriptions = subscription.NewMemoryStore()
+	router.scheduler = subscription.NewScheduler(cfg.Subscriptions, router.subscriptions, router.chargeSubscription)
 
-	return router.loadEndpoints()
+	return router
 }
 
 func getEndpoint(endpoint string) string {
@@ -43,118 +278,127 @@ func (route *Router) loadEndpoints() *http.Server {
 	addr := fmt.Sprintf(":%d", route.cfg.APIServer.Port)
 
 	//Эндпоинты auth
-	authRoute := route.r.PathPrefix(getEndpoint("auth")).Subrouter()
-
-	//Эндпоинты usersPrivate
-	usersPrivateRoute := route.r.PathPrefix(getEndpoint("users")).Subrouter()
-	usersPrivateRoute.Use(cors.Default().Handler, route.authMiddleware)
+	authPrivateRoute := route.privateRouter("auth")
+	authPublicRoute := route.publicRouter("auth")
 
-	//Эндпоинты usersPublic
-	usersPublicRoute := route.r.PathPrefix(getEndpoint("users")).Subrouter()
-	usersPublicRoute.Use(cors.Default().Handler, route.publicMiddleware)
+	//Эндпоинты users
+	usersPrivateRoute := route.privateRouter("users")
+	usersPublicRoute := route.publicRouter("users")
 
-	//Эндпоинты companiesPrivate
-	companiesPrivateRoute := route.r.PathPrefix(getEndpoint("companies")).Subrouter()
-	companiesPrivateRoute.Use(cors.Default().Handler, route.authMiddleware)
+	//Эндпоинты companies
+	companiesPrivateRoute := route.privateRouter("companies")
 
-	//Эндпоинты companiesPublic
-	companiesPublicRoute := route.r.PathPrefix(getEndpoint("companies")).Subrouter()
-	companiesPublicRoute.Use(cors.Default().Handler, route.publicMiddleware)
+	//Эндпоинты cards
+	cardsPrivateRoute := route.privateRouter("cards")
 
-	//Эндпоинты cardsPrivate
-	cardsPrivateRoute := route.r.PathPrefix(getEndpoint("cards")).Subrouter()
-	cardsPrivateRoute.Use(cors.Default().Handler, route.authMiddleware)
+	//Эндпоинты cardCompanies
+	cardCompaniesPrivateRoute := route.privateRouter("card/company")
 
-	//Эндпоинты companiesPublic
-	cardsPublicRoute := route.r.PathPrefix(getEndpoint("cards")).Subrouter()
-	cardsPublicRoute.Use(cors.Default().Handler, route.publicMiddleware)
+	//Эндпоинты donations
+	donationsPrivateRoute := route.privateRouter("donations")
+	donationsPublicRoute := route.publicRouter("donations")
 
-	//Эндпоинты cardCompaniesPrivate
-	cardCompaniesPrivateRoute := route.r.PathPrefix(getEndpoint("card/company")).Subrouter()
-	cardCompaniesPrivateRoute.Use(cors.Default().Handler, route.authMiddleware)
+	//Эндпоинты wards
+	wardsPrivateRoute := route.privateRouter("wards")
+	wardsPublicRoute := route.publicRouter("wards")
 
-	//Эндпоинты cardCompaniesPublic
-	cardCompaniesPublicRoute := route.r.PathPrefix(getEndpoint("card/company")).Subrouter()
-	cardCompaniesPublicRoute.Use(cors.Default().Handler, route.publicMiddleware)
+	//Эндпоинты payment
+	paymentPrivateRoute := route.privateRouter("payment")
+	paymentPublicRoute := route.publicRouter("payment")
 
-	//Эндпоинты donationsPrivate
-	donationsPrivateRoute := route.r.PathPrefix(getEndpoint("donations")).Subrouter()
-	donationsPrivateRoute.Use(cors.Default().Handler, route.authMiddleware)
+	//Эндпоинты subscriptions
+	subscriptionsPrivateRoute := route.privateRouter("subscriptions")
 
-	//Эндпоинты donationsPublic
-	donationsPublicRoute := route.r.PathPrefix(getEndpoint("donations")).Subrouter()
-	donationsPublicRoute.Use(cors.Default().Handler, route.publicMiddleware)
+	//Эндпоинты apikeys
+	apiKeysPrivateRoute := route.privateRouter("apikeys")
 
-	//Эндпоинты wardsPrivate
-	wardsPrivateRoute := route.r.PathPrefix(getEndpoint("wards")).Subrouter()
-	wardsPrivateRoute.Use(cors.Default().Handler, route.authMiddleware)
+	//Эндпоинты vault
+	vaultPrivateRoute := route.privateRouter("vault")
 
-	//Эндпоинты wardsPublic
-	wardsPublicRoute := route.r.PathPrefix(getEndpoint("wards")).Subrouter()
-	wardsPublicRoute.Use(cors.Default().Handler, route.publicMiddleware)
-
-	//Эндпоинты wardsPrivate
-	paymentPrivateRoute := route.r.PathPrefix(getEndpoint("payment")).Subrouter()
-	paymentPrivateRoute.Use(cors.Default().Handler, route.authMiddleware)
-
-	//Эндпоинты wardsPublic
-	paymentPublicRoute := route.r.PathPrefix(getEndpoint("payment")).Subrouter()
-	paymentPublicRoute.Use(cors.Default().Handler, route.publicMiddleware)
+	//Эндпоинты well-known
+	wellKnownPublicRoute := route.subrouter("/.well-known", accessPublic)
 
 	//Swagger
 	{
 		if route.cfg.Swagger {
-			route.r.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
+			swaggerRoute := route.r.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
 				httpSwagger.URL("/swagger/doc.json"), //The url pointing to API definition
 				httpSwagger.DeepLinking(true),
 				httpSwagger.DocExpansion("none"),
 				httpSwagger.DomID("swagger-ui"),
 			)).Methods(http.MethodGet)
+			route.access[swaggerRoute] = accessPublic
 		}
 	}
 
 	//Аутентификация
 	{
-		authRoute.HandleFunc("/login", route.Login).Methods(http.MethodPost, http.MethodOptions)
-		authRoute.HandleFunc("/registration", route.Registration).Methods(http.MethodPost, http.MethodOptions)
+		//Приватные
+		{
+			route.handle(authPrivateRoute, "/logout", anyUser.wrap(route.Logout), http.MethodPost)
+			route.handle(authPrivateRoute, "/sessions", anyUser.wrap(route.Sessions), http.MethodGet)
+			route.handle(authPrivateRoute, "/sessions/{id:[0-9]+}", anyUser.wrap(route.DeleteSession),
+				http.MethodDelete)
+			route.handle(authPrivateRoute, "/verify", anyUser.wrap(route.Verification), http.MethodGet)
+			route.handle(authPrivateRoute, "/verify/phone", anyUser.wrap(route.VerifyPhone), http.MethodPost)
+			route.handle(authPrivateRoute, "/verify/email", anyUser.wrap(route.VerifyEmail), http.MethodPost)
+			route.handle(authPrivateRoute, "/2fa/setup", anyUser.wrap(route.MfaSetup), http.MethodPost)
+			route.handle(authPrivateRoute, "/2fa/confirm", anyUser.wrap(route.MfaConfirm), http.MethodPost)
+			route.handle(authPrivateRoute, "/2fa/disable", anyUser.wrap(route.MfaDisable), http.MethodPost)
+		}
+
+		//Публичные
+		{
+			route.handle(authPublicRoute, "/login", route.Login, http.MethodPost)
+			route.handle(authPublicRoute, "/registration", route.Registration, http.MethodPost)
+			route.handle(authPublicRoute, "/refresh", route.Refresh, http.MethodPost)
+			route.handle(authPublicRoute, "/password/forgot", route.ForgotPassword, http.MethodPost)
+			route.handle(authPublicRoute, "/password/reset", route.ResetPassword, http.MethodPost)
+			route.handle(authPublicRoute, "/otp/request", route.OtpRequestCode, http.MethodPost)
+			route.handle(authPublicRoute, "/otp/verify", route.OtpVerify, http.MethodPost)
+			route.handle(authPublicRoute, "/2fa/login", route.MfaLogin, http.MethodPost)
+			route.handle(wellKnownPublicRoute, "/jwks.json", route.JWKS, http.MethodGet)
+		}
 	}
 
 	//Пользователи
 	{
 		//Приватные
 		{
-			usersPrivateRoute.HandleFunc("", route.GetUsers).Methods(http.MethodGet, http.MethodOptions)
-			usersPrivateRoute.HandleFunc("", route.CreateUser).Methods(http.MethodPost, http.MethodOptions)
-			usersPrivateRoute.HandleFunc("/{id:[0-9]+}", route.ChangeUserType).Methods(http.MethodPatch, http.MethodOptions)
-			usersPrivateRoute.HandleFunc("/{id:[0-9]+}/company", route.FindUserCompany).Methods(http.MethodGet,
-				http.MethodOptions)
-			usersPrivateRoute.HandleFunc("/{id:[0-9]+}/donation", route.FindUserDonations).Methods(http.MethodGet,
-				http.MethodOptions)
-			usersPrivateRoute.HandleFunc("/{id:[0-9]+}/card", route.FindUserCard).Methods(http.MethodGet,
-				http.MethodOptions)
-			usersPrivateRoute.HandleFunc("/addCard", route.AddCardToUser).Methods(http.MethodPost,
-				http.MethodOptions)
-			usersPrivateRoute.HandleFunc("/deleteModel", route.DeleteUserByModel).Methods(http.MethodPost,
-				http.MethodOptions)
-			usersPrivateRoute.HandleFunc("/{id:[0-9]+}/photo", route.DeleteUserPhoto).Methods(http.MethodDelete,
-				http.MethodOptions)
-			usersPrivateRoute.HandleFunc("/{id:[0-9]+}/photo", route.SetUserPhoto).Methods(http.MethodPost,
-				http.MethodOptions)
+			route.handle(usersPrivateRoute, "", adminOnly.wrap(route.GetUsers), http.MethodGet)
+			route.handle(usersPrivateRoute, "", adminOnly.wrap(route.CreateUser), http.MethodPost)
+			route.handle(usersPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.userFromPath,
+				route.GetUser)), http.MethodGet)
+			route.handle(usersPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.userFromPath,
+				route.UpdateUser)), http.MethodPut)
+			route.handle(usersPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.userFromPath,
+				route.DeleteUserByID)), http.MethodDelete)
+			route.handle(usersPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.userFromPath,
+				route.ChangeUserType)), http.MethodPatch)
+			route.handle(usersPrivateRoute, "/{id:[0-9]+}/company", anyUser.wrap(route.ownedBy(route.userFromPath,
+				route.FindUserCompany)), http.MethodGet)
+			route.handle(usersPrivateRoute, "/{id:[0-9]+}/donation", anyUser.wrap(route.ownedBy(route.userFromPath,
+				route.FindUserDonations)), http.MethodGet)
+			route.handle(usersPrivateRoute, "/{id:[0-9]+}/card", anyUser.wrap(route.ownedBy(route.userFromPath,
+				route.FindUserCard)), http.MethodGet)
+			route.handle(usersPrivateRoute, "/{id:[0-9]+}/photo", anyUser.wrap(route.ownedBy(route.userFromPath,
+				route.DeleteUserPhoto)), http.MethodDelete)
+			route.handle(usersPrivateRoute, "/{id:[0-9]+}/photo", anyUser.wrap(route.ownedBy(route.userFromPath,
+				route.SetUserPhoto)), http.MethodPost)
+			route.handle(usersPrivateRoute, "/addCard", anyUser.wrap(route.AddCardToUser), http.MethodPost)
+			route.handle(usersPrivateRoute, "/deleteModel", adminOnly.wrap(route.DeleteUserByModel), http.MethodPost)
+			route.handle(usersPrivateRoute, "/isRole", anyUser.wrap(route.UserIsRole), http.MethodPost)
+			route.handle(usersPrivateRoute, "/comparePassword", anyUser.wrap(route.ComparePassword), http.MethodPost)
+			route.handle(usersPrivateRoute, "/", moderators.wrap(route.FindUserByEmail),
+				http.MethodGet).Queries("email", "{email}")
+			route.handle(usersPrivateRoute, "/", moderators.wrap(route.FindUserByPhone),
+				http.MethodGet).Queries("phone", "{phone}")
 		}
 
 		//Публичные
 		{
-			usersPublicRoute.HandleFunc("/{id:[0-9]+}", route.GetUser).Methods(http.MethodGet, http.MethodOptions)
-			usersPublicRoute.HandleFunc("/{id:[0-9]+}", route.UpdateUser).Methods(http.MethodPut, http.MethodOptions)
-			usersPublicRoute.HandleFunc("/{id:[0-9]+}", route.DeleteUserByID).Methods(http.MethodDelete, http.MethodOptions)
-			usersPublicRoute.HandleFunc("/isExists", route.UserIsExists).Methods(http.MethodPost, http.MethodOptions)
-			usersPublicRoute.HandleFunc("/isRole", route.UserIsRole).Methods(http.MethodPost, http.MethodOptions)
-			usersPublicRoute.HandleFunc("/comparePassword", route.ComparePassword).Methods(http.MethodPost, http.MethodOptions)
-			usersPublicRoute.HandleFunc("/", route.FindUserByEmail).Queries("email", "{email}").Methods(http.MethodGet,
-				http.MethodOptions)
-			usersPublicRoute.HandleFunc("/", route.FindUserByPhone).Queries("phone", "{phone}").Methods(http.MethodGet,
-				http.MethodOptions)
-			usersPublicRoute.HandleFunc("/{id:[0-9]+}/photo", route.GetUserPhoto).Methods(http.MethodGet, http.MethodOptions)
+			route.handle(usersPublicRoute, "/isExists", route.UserIsExists, http.MethodPost)
+			route.handle(usersPublicRoute, "/{id:[0-9]+}/photo", route.GetUserPhoto, http.MethodGet)
 		}
 	}
 
@@ -162,125 +406,148 @@ func (route *Router) loadEndpoints() *http.Server {
 	{
 		//Приватные
 		{
-			companiesPrivateRoute.HandleFunc("", route.Companies).Methods(http.MethodGet, http.MethodOptions)
-			companiesPrivateRoute.HandleFunc("/{id:[0-9]+}", route.Company).Methods(http.MethodGet, http.MethodOptions)
-			companiesPrivateRoute.HandleFunc("/{id:[0-9]+}/card", route.FindCompanyCard).Methods(http.MethodGet,
-				http.MethodOptions)
-			companiesPrivateRoute.HandleFunc("/deleteModel", route.DeleteCompanyByModel).Methods(http.MethodPost,
-				http.MethodOptions)
-			companiesPrivateRoute.HandleFunc("/{id:[0-9]+}", route.DeleteCompanyByID).Methods(http.MethodDelete, http.MethodOptions)
-			companiesPrivateRoute.HandleFunc("", route.UpdateCompany).Methods(http.MethodPut, http.MethodOptions)
-			companiesPrivateRoute.HandleFunc("/addCard", route.AddCardToCompany).Methods(http.MethodPost,
-				http.MethodOptions)
+			route.handle(companiesPrivateRoute, "", moderators.wrap(route.Companies), http.MethodGet)
+			route.handle(companiesPrivateRoute, "", anyUser.wrap(route.CreateCompany), http.MethodPost)
+			route.handle(companiesPrivateRoute, "", anyUser.wrap(route.UpdateCompany), http.MethodPut)
+			route.handle(companiesPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.companyOwner,
+				route.Company)), http.MethodGet)
+			route.handle(companiesPrivateRoute, "/{id:[0-9]+}", adminOnly.wrap(route.DeleteCompanyByID),
+				http.MethodDelete)
+			route.handle(companiesPrivateRoute, "/{id:[0-9]+}/card", anyUser.wrap(route.ownedBy(route.companyOwner,
+				route.FindCompanyCard)), http.MethodGet)
+			route.handle(companiesPrivateRoute, "/deleteModel", adminOnly.wrap(route.DeleteCompanyByModel),
+				http.MethodPost)
+			route.handle(companiesPrivateRoute, "/addCard", anyUser.wrap(route.AddCardToCompany), http.MethodPost)
+			route.handle(companiesPrivateRoute, "/", moderators.wrap(route.FindCompanyByPhone),
+				http.MethodGet).Queries("phone", "{phone}")
 		}
+	}
 
-		//Публичные
+	//Банковские карты пользователей
+	{
+		//Приватные
 		{
-			companiesPublicRoute.HandleFunc("", route.CreateCompany).Methods(http.MethodPost, http.MethodOptions)
-			companiesPublicRoute.HandleFunc("/", route.FindCompanyByPhone).Queries("phone", "{phone}").Methods(http.MethodGet,
-				http.MethodOptions)
+			route.handle(cardsPrivateRoute, "", adminOnly.wrap(route.Cards), http.MethodGet)
+			route.handle(cardsPrivateRoute, "", anyUser.wrap(route.CreateCard), http.MethodPost)
+			route.handle(cardsPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.cardOwner, route.Card)),
+				http.MethodGet)
+			route.handle(cardsPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.cardOwner,
+				route.DeleteCardById)), http.MethodDelete)
+			route.handle(cardsPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.cardOwner,
+				route.UpdateCard)), http.MethodPut)
+			route.handle(cardsPrivateRoute, "/deleteModel", adminOnly.wrap(route.DeleteCardByModel), http.MethodPost)
+			route.handle(cardsPrivateRoute, "/shared", adminOnly.wrap(route.SharedCards), http.MethodGet)
 		}
 	}
 
-	//Банковские карты пользователей
+	//Банковские карты компаний
+	{
+		//Приватные
+		{
+			route.handle(cardCompaniesPrivateRoute, "", adminOnly.wrap(route.CardCompanies), http.MethodGet)
+			route.handle(cardCompaniesPrivateRoute, "", anyUser.wrap(route.CreateCardCompany), http.MethodPost)
+			route.handle(cardCompaniesPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.cardCompanyOwner,
+				route.CardCompany)), http.MethodGet)
+			route.handle(cardCompaniesPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.cardCompanyOwner,
+				route.DeleteCardCompanyById)), http.MethodDelete)
+			route.handle(cardCompaniesPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.cardCompanyOwner,
+				route.UpdateCardCompany)), http.MethodPut)
+			route.handle(cardCompaniesPrivateRoute, "/deleteModel", adminOnly.wrap(route.DeleteCardCompaniesByModel),
+				http.MethodPost)
+		}
+	}
+
+	//Пожертвования
 	{
 		//Приватные
 		{
-			cardsPrivateRoute.HandleFunc("", route.Cards).Methods(http.MethodGet, http.MethodOptions)
-			cardsPrivateRoute.HandleFunc("/{id:[0-9]+}", route.Card).Methods(http.MethodGet, http.MethodOptions)
-			cardsPrivateRoute.HandleFunc("/deleteModel", route.DeleteCardByModel).Methods(http.MethodPost,
-				http.MethodOptions)
-			cardsPrivateRoute.HandleFunc("/{id:[0-9]+}", route.DeleteCardById).Methods(http.MethodDelete,
-				http.MethodOptions)
-			cardsPrivateRoute.HandleFunc("/{id:[0-9]+}", route.UpdateCard).Methods(http.MethodPut, http.MethodOptions)
+			route.handle(donationsPrivateRoute, "", adminOnly.wrap(route.CreateDonation), http.MethodPost)
+			route.handle(donationsPrivateRoute, "", adminOnly.wrap(route.UpdateDonation), http.MethodPut)
+			route.handle(donationsPrivateRoute, "/{id:[0-9]+}", anyUser.wrap(route.ownedBy(route.donationOwner,
+				route.Donation)), http.MethodGet)
+			route.handle(donationsPrivateRoute, "/{id:[0-9]+}", adminOnly.wrap(route.DeleteDonationById),
+				http.MethodDelete)
+			route.handle(donationsPrivateRoute, "/{id:[0-9]+}/wards", anyUser.wrap(route.ownedBy(route.donationOwner,
+				route.FindDonationWards)), http.MethodGet)
+			route.handle(donationsPrivateRoute, "/{id:[0-9]+}/user", anyUser.wrap(route.ownedBy(route.donationOwner,
+				route.FindDonationUser)), http.MethodGet)
+			route.handle(donationsPrivateRoute, "/deleteModel", adminOnly.wrap(route.DeleteDonationByModel),
+				http.MethodPost)
 		}
 
 		//Публичные
 		{
-			cardsPublicRoute.HandleFunc("", route.CreateCard).Methods(http.MethodPost, http.MethodOptions)
+			route.handle(donationsPublicRoute, "", route.Donations, http.MethodGet)
 		}
 	}
 
-	//Банковские карты компаний
+	//Подопечные
 	{
 		//Приватные
 		{
-			cardCompaniesPrivateRoute.HandleFunc("", route.CardCompanies).Methods(http.MethodGet, http.MethodOptions)
-			cardCompaniesPrivateRoute.HandleFunc("/{id:[0-9]+}", route.CardCompany).Methods(http.MethodGet,
-				http.MethodOptions)
-			cardCompaniesPrivateRoute.HandleFunc("/deleteModel", route.DeleteCardCompaniesByModel).Methods(http.
-				MethodPost,
-				http.MethodOptions)
-			cardCompaniesPrivateRoute.HandleFunc("/{id:[0-9]+}", route.DeleteCardCompanyById).Methods(http.MethodDelete,
-				http.MethodOptions)
-			cardCompaniesPrivateRoute.HandleFunc("/{id:[0-9]+}", route.UpdateCardCompany).Methods(http.MethodPut,
-				http.MethodOptions)
+			route.handle(wardsPrivateRoute, "", moderators.wrap(route.CreateWard), http.MethodPost)
+			route.handle(wardsPrivateRoute, "", moderators.wrap(route.UpdateWard), http.MethodPut)
+			route.handle(wardsPrivateRoute, "/{id:[0-9]+}", adminOnly.wrap(route.DeleteWardById), http.MethodDelete)
+			route.handle(wardsPrivateRoute, "/{id:[0-9]+}/donations", anyUser.wrap(route.FindWardDonations),
+				http.MethodGet)
+			route.handle(wardsPrivateRoute, "/deleteModel", adminOnly.wrap(route.DeleteWardByModel), http.MethodPost)
 		}
 
 		//Публичные
 		{
-			cardCompaniesPublicRoute.HandleFunc("", route.CreateCardCompany).Methods(http.MethodPost,
-				http.MethodOptions)
+			route.handle(wardsPublicRoute, "", route.Wards, http.MethodGet)
+			route.handle(wardsPublicRoute, "/{id:[0-9]+}", route.Ward, http.MethodGet)
 		}
 	}
 
-	//Пожертвования
+	//Платежи
 	{
 		//Приватные
 		{
-			donationsPrivateRoute.HandleFunc("/{id:[0-9]+}/wards", route.FindDonationWards).Methods(http.MethodGet,
-				http.MethodOptions)
-			donationsPrivateRoute.HandleFunc("/{id:[0-9]+}/user", route.FindDonationUser).Methods(http.MethodGet,
-				http.MethodOptions)
-			donationsPrivateRoute.HandleFunc("/{id:[0-9]+}", route.Donation).Methods(http.MethodGet,
-				http.MethodOptions)
-			donationsPrivateRoute.HandleFunc("/deleteModel", route.DeleteDonationByModel).Methods(http.
-				MethodPost,
-				http.MethodOptions)
-			donationsPrivateRoute.HandleFunc("/{id:[0-9]+}", route.DeleteDonationById).Methods(http.MethodDelete,
-				http.MethodOptions)
-			donationsPrivateRoute.HandleFunc("", route.UpdateDonation).Methods(http.MethodPut,
-				http.MethodOptions)
+			route.handle(paymentPrivateRoute, "", anyUser.wrap(route.idempotent(route.Payment)), http.MethodPost)
+			route.handle(paymentPrivateRoute, "/{id:[0-9a-f]+}", anyUser.wrap(route.ownedBy(route.paymentOwner,
+				route.PaymentStatus)), http.MethodGet)
+			route.handle(paymentPrivateRoute, "/{donationId:[0-9]+}/refund",
+				anyUser.wrap(route.idempotent(route.RefundDonation)), http.MethodPost)
 		}
 
 		//Публичные
 		{
-			donationsPublicRoute.HandleFunc("", route.CreateDonation).Methods(http.MethodPost,
-				http.MethodOptions)
-			donationsPublicRoute.HandleFunc("", route.Donations).Methods(http.MethodGet, http.MethodOptions)
+			route.handle(paymentPublicRoute, "/webhook/{provider}", route.PaymentWebhook, http.MethodPost)
 		}
 	}
 
-	//Подопечные
+	//Ежемесячные пожертвования
 	{
 		//Приватные
 		{
-			wardsPrivateRoute.HandleFunc("", route.CreateWard).Methods(http.MethodPost,
-				http.MethodOptions)
-			wardsPrivateRoute.HandleFunc("/deleteModel", route.DeleteWardByModel).Methods(http.
-				MethodPost,
-				http.MethodOptions)
-			wardsPrivateRoute.HandleFunc("/{id:[0-9]+}", route.DeleteWardById).Methods(http.MethodDelete,
-				http.MethodOptions)
-			wardsPrivateRoute.HandleFunc("", route.UpdateWard).Methods(http.MethodPut,
-				http.MethodOptions)
-			wardsPrivateRoute.HandleFunc("/{id:[0-9]+}/donations", route.FindWardDonations).Methods(http.MethodGet,
-				http.MethodOptions)
+			route.handle(subscriptionsPrivateRoute, "", anyUser.wrap(route.Subscriptions), http.MethodGet)
+			route.handle(subscriptionsPrivateRoute, "", anyUser.wrap(route.CreateSubscription), http.MethodPost)
+			route.handle(subscriptionsPrivateRoute, "/{id:[0-9a-f]+}", anyUser.wrap(route.ownedBy(
+				route.subscriptionOwner, route.Subscription)), http.MethodGet)
+			route.handle(subscriptionsPrivateRoute, "/{id:[0-9a-f]+}", anyUser.wrap(route.ownedBy(
+				route.subscriptionOwner, route.UpdateSubscription)), http.MethodPut)
+			route.handle(subscriptionsPrivateRoute, "/{id:[0-9a-f]+}", anyUser.wrap(route.ownedBy(
+				route.subscriptionOwner, route.DeleteSubscription)), http.MethodDelete)
 		}
+	}
 
-		//Публичные
+	//Ключи API
+	{
+		//Приватные
 		{
-			wardsPublicRoute.HandleFunc("", route.Wards).Methods(http.MethodGet, http.MethodOptions)
-			wardsPublicRoute.HandleFunc("/{id:[0-9]+}", route.Ward).Methods(http.MethodGet,
-				http.MethodOptions)
+			route.handle(apiKeysPrivateRoute, "", adminOnly.wrap(route.ApiKeys), http.MethodGet)
+			route.handle(apiKeysPrivateRoute, "", adminOnly.wrap(route.IssueApiKey), http.MethodPost)
+			route.handle(apiKeysPrivateRoute, "/{id:[0-9a-f]+}", adminOnly.wrap(route.RevokeApiKey),
+				http.MethodDelete)
 		}
 	}
 
-	//Платежи
+	//Хранилище карт
 	{
 		//Приватные
 		{
-			paymentPrivateRoute.HandleFunc("", route.Payment).Methods(http.MethodPost, http.MethodOptions)
+			route.handle(vaultPrivateRoute, "/reencrypt", adminOnly.wrap(route.VaultReencrypt), http.MethodPost)
 		}
 	}
 
@@ -291,6 +558,7 @@ func (route *Router) loadEndpoints() *http.Server {
 		AllowedOrigins: []string{"*"},
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "PATCH"},
 		AllowedHeaders: []string{"Content-Type", "application/json"},
+		ExposedHeaders: []string{"Link", "X-Total-Count"}, // Заголовки постраничной выдачи списков
 	})
 	handler := crs.Handler(route.r)
 
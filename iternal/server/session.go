@@ -0,0 +1,181 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"apiGateway/pkg/token"
+	"apiGateway/pkg/utilities"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// errSessionNotFound - сессия не найдена или уже отозвана
+	errSessionNotFound = errors.New("session not found")
+	// errRefreshTokenReused - предъявлен уже ротированный refresh токен, сессия отзывается целиком
+	errRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// maxUserAgentLen - максимальная длина User-Agent, сохраняемого в сессии
+const maxUserAgentLen = 256
+
+// sessionRecord - данные сессии, которые шлюз хранит в поле RefreshToken сервиса DatabaseService
+type sessionRecord struct {
+	Hash      string `json:"hash"`                // Хэш идентификатора (jti) актуального refresh токена
+	UserAgent string `json:"userAgent,omitempty"` // User-Agent устройства, с которого открыта сессия
+	Mfa       bool   `json:"mfa,omitempty"`       // Сессия открыта с подтверждением вторым фактором
+}
+
+// newSessionRecord - формирует запись сессии для refresh токена с идентификатором tokenId
+func newSessionRecord(tokenId, userAgent string, mfa bool) sessionRecord {
+	if len(userAgent) > maxUserAgentLen {
+		userAgent = userAgent[:maxUserAgentLen]
+	}
+
+	return sessionRecord{Hash: utilities.SHA256(tokenId), UserAgent: userAgent, Mfa: mfa}
+}
+
+// encode - сериализует запись сессии для хранения
+func (s sessionRecord) encode() string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return s.Hash
+	}
+
+	return string(data)
+}
+
+// decodeSessionRecord - разбирает сохраненную запись сессии, записи без User-Agent содержат только хэш
+func decodeSessionRecord(raw string) sessionRecord {
+	record := sessionRecord{}
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		return sessionRecord{Hash: raw}
+	}
+
+	return record
+}
+
+// openSession - создает новую сессию пользователя и выпускает для нее пару токенов, mfa - вход подтвержден
+// вторым фактором. В базе данных хранится не сам refresh токен, а хэш его идентификатора (jti)
+func (route *Router) openSession(ctx context.Context, user *DatabaseServicev1.CreateUserResponse,
+	userAgent string, mfa bool) (*LoginResponse, error) {
+	tokenId, err := token.NewTokenId()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := route.databaseService.CreateSessions(ctx, &DatabaseServicev1.CreateSessionRequest{
+		UserId:       user.GetId(),
+		RefreshToken: newSessionRecord(tokenId, userAgent, mfa).encode(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return route.issueTokens(user, session.GetId(), tokenId, mfa)
+}
+
+// rotateSession - проверяет refresh токен, заменяет его в сессии на новый и выпускает новую пару токенов.
+// Повторное предъявление уже ротированного refresh токена отзывает всю сессию. Ротации одной сессии выполняются
+// по очереди: DatabaseService не умеет условно заменять refresh токен, поэтому без блокировки два параллельных
+// запроса с одним токеном прочитали бы один хэш и оба открыли бы продолжение сессии
+func (route *Router) rotateSession(ctx context.Context, claims *token.RefreshClaims, userAgent string) (*LoginResponse,
+	error) {
+	unlock := route.sessionLocks.lock(claims.SessionId)
+	defer unlock()
+
+	session, err := route.databaseService.FindSessionsById(ctx,
+		&DatabaseServicev1.FindSessionsByIdRequest{Id: claims.SessionId})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, errSessionNotFound
+		}
+		return nil, err
+	}
+
+	if claims.Subject != fmt.Sprint(session.GetUserId()) {
+		return nil, errSessionNotFound
+	}
+
+	record := decodeSessionRecord(session.GetRefreshToken())
+
+	if record.Hash != utilities.SHA256(claims.ID) {
+		logger.Warn("Повторное использование refresh токена, сессия %d отозвана", session.GetId())
+
+		_, err = route.databaseService.DeleteSessionById(ctx,
+			&DatabaseServicev1.DeleteSessionByIdRequest{Id: session.GetId()})
+		if err != nil {
+			logger.Error("Ошибка при отзыве сессии %d: %v", session.GetId(), err)
+		}
+
+		return nil, errRefreshTokenReused
+	}
+
+	user, err := route.databaseService.FindUserById(ctx,
+		&DatabaseServicev1.FindUserByIdRequest{Id: session.GetUserId()})
+	if err != nil {
+		return nil, err
+	}
+
+	// Сессия, открытая без второго фактора, не продлевается, если для роли пользователя 2FA стала обязательной
+	if route.mfaRequired(user.GetRole()) && !record.Mfa {
+		return nil, errSessionNotFound
+	}
+
+	tokenId, err := token.NewTokenId()
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := route.databaseService.ChangeRefreshTokenById(ctx, &DatabaseServicev1.ChangeRefreshTokenByIdRequest{
+		Id:           session.GetId(),
+		RefreshToken: newSessionRecord(tokenId, userAgent, record.Mfa).encode(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed.GetAccessory() {
+		return nil, fmt.Errorf("не удалось обновить refresh токен сессии %d", session.GetId())
+	}
+
+	return route.issueTokens(user, session.GetId(), tokenId, record.Mfa)
+}
+
+// checkSession - проверяет, что сессия, в рамках которой выпущен токен доступа, не отозвана
+func (route *Router) checkSession(ctx context.Context, user token.IUser) error {
+	session, err := route.databaseService.FindSessionsById(ctx,
+		&DatabaseServicev1.FindSessionsByIdRequest{Id: user.GetSessionId()})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return errSessionNotFound
+		}
+		return err
+	}
+
+	if session.GetUserId() != user.GetUserId() {
+		return errSessionNotFound
+	}
+
+	return nil
+}
+
+// issueTokens - выпускает access и refresh токены для сессии
+func (route *Router) issueTokens(user *DatabaseServicev1.CreateUserResponse, sessionId uint64,
+	tokenId string, mfa bool) (*LoginResponse, error) {
+	accessToken, err := route.tokens.CreateToken(user, sessionId, mfa)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := route.tokens.CreateRefreshToken(user.GetId(), sessionId, tokenId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{Token: accessToken, RefreshToken: refreshToken}, nil
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRefreshConcurrent(t *testing.T) {
+	const requests = 20
+
+	user := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	db := newFakeDatabase(user)
+	db.findSessionDelay = 10 * time.Millisecond
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), user, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Из параллельных запросов с одним refresh токеном продлевает сессию только один,
+	// остальные считаются повторным использованием токена
+	codes := make(chan int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve(route, http.MethodPost, "/api/v1/auth/refresh",
+				`{"refreshToken":"`+tokens.RefreshToken+`"}`).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	succeeded := 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			succeeded++
+		case http.StatusUnauthorized:
+		default:
+			t.Errorf("code = %d", code)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("успешных обновлений: %d, want 1", succeeded)
+	}
+}
+
+func TestSessionsList(t *testing.T) {
+	owner := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleUser}
+	other := &DatabaseServicev1.CreateUserResponse{Id: 2, Phone: "+79997654321", Role: RoleUser}
+	db := newFakeDatabase(owner, other)
+	route, _ := newTestRouter(t, db)
+
+	if _, err := route.openSession(context.Background(), other, "other", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := route.openSession(context.Background(), owner, "phone", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := route.openSession(context.Background(), owner, "tablet", false); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := route.openSession(context.Background(), owner, "laptop", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serveWith(route, http.MethodGet, "/api/v1/auth/sessions", "Bearer "+tokens.Token, ``)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d, body = %s", rec.Code, rec.Body)
+	}
+	response := SessionsResponse{}
+	if err = json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	// Текущая сессия и сессия, которую DatabaseService вернул по пользователю, чужие сессии не попадают в список
+	var agents []string
+	for _, session := range response.Sessions {
+		agents = append(agents, session.UserAgent)
+	}
+	if want := []string{"laptop", "phone"}; !slices.Equal(agents, want) {
+		t.Errorf("сессии = %v, want %v", agents, want)
+	}
+	if !response.Sessions[0].Current || response.Sessions[1].Current {
+		t.Errorf("текущая сессия: %+v", response.Sessions)
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/money"
+	"apiGateway/pkg/payment"
+	"apiGateway/pkg/subscription"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// chargeSubscription - выполняет платеж подписки за период period той же сагой, что и POST /api/v1/payment.
+// ID платежа вычисляется из подписки, периода и номера попытки, поэтому попытка, прерванная перезапуском шлюза,
+// не выполняется повторно: ее результат берется из реестра платежей
+func (route *Router) chargeSubscription(ctx context.Context, s subscription.Subscription, period time.Time,
+	attempt int) (string, error) {
+	paymentId := fmt.Sprintf("%s%s%02d", s.Id, period.Format("20060102"), attempt)
+
+	p, err := route.ledger.Get(ctx, paymentId)
+	switch {
+	case err == nil && p.Status == payment.PaymentFailed:
+		return "", errors.New(p.FailureReason)
+	case err == nil:
+		return p.Id, nil
+	case !errors.Is(err, payment.ErrPaymentNotFound):
+		return "", err
+	}
+
+	ward, err := route.databaseService.FindWardById(ctx, &DatabaseServicev1.FindWardByIdRequest{Id: s.WardId})
+	if err != nil {
+		return "", err
+	}
+	necessary := money.FromFloat32(ward.GetNecessary())
+	if necessary > 0 && money.FromFloat32(ward.GetCollected()) >= necessary {
+		return "", fmt.Errorf("%w: подопечный собрал нужную сумму", subscription.ErrPause)
+	}
+
+	cards, err := route.databaseService.FindUserCard(ctx, &DatabaseServicev1.FindUserCardRequest{Id: s.UserId})
+	if err != nil {
+		return "", err
+	}
+	card, ok := selectCard(cards.GetCards(), s.CardId)
+	if !ok {
+		return "", fmt.Errorf("%w: карта для оплаты не найдена", subscription.ErrPause)
+	}
+
+	p, err = route.pay(ctx, &paymentData{
+		PaymentId: paymentId,
+		UserId:    s.UserId,
+		WardId:    s.WardId,
+		CardId:    card.GetId(),
+		Title:     ward.GetWant(),
+		Amount:    s.Amount,
+	})
+	if errors.Is(err, errPaymentUnread) {
+		return paymentId, nil
+	}
+	if errors.Is(err, errCollectedInexact) {
+		return "", fmt.Errorf("%w: %v", subscription.ErrPause, err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return p.Id, nil
+}
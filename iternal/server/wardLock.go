@@ -0,0 +1,79 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/logger"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"math/rand"
+	"time"
+)
+
+const (
+	wardUpdateAttempts = 5                     // Попыток изменения подопечного при конфликте версий
+	wardRetryDelay     = 20 * time.Millisecond // Базовая задержка перед повтором, растет с каждой попыткой
+)
+
+// errWardConflict - подопечный изменен другим запросом, изменение не применено
+var errWardConflict = status.Error(codes.Aborted, "Подопечный изменен другим запросом, повторите операцию")
+
+// changeWard - изменяет подопечного функцией change с оптимистичной блокировкой по UpdatedAt: подопечный читается,
+// перед записью версия перечитывается и сравнивается, UpdateWard получает прочитанную версию, а при конфликте
+// (в том числе ответе Aborted от DatabaseService, который отклоняет запись с устаревшей версией) изменение
+// повторяется на свежих данных. Блокировка внутри шлюза только снижает число конфликтов между запросами одного
+// экземпляра, от других экземпляров и прямых вызовов UpdateWard защищает проверка версии. Ошибка change
+// возвращается без повтора
+func (route *Router) changeWard(ctx context.Context, id uint64,
+	change func(ward *DatabaseServicev1.Ward) error) (*DatabaseServicev1.Ward, error) {
+	unlock := route.wardLocks.lock(id)
+	defer unlock()
+
+	for attempt := 1; ; attempt++ {
+		ward, err := route.databaseService.FindWardById(ctx, &DatabaseServicev1.FindWardByIdRequest{Id: id})
+		if err != nil {
+			return nil, err
+		}
+
+		version := ward.GetUpdatedAt()
+
+		if err = change(ward); err != nil {
+			return nil, err
+		}
+		ward.UpdatedAt = version
+
+		updated, err := route.updateWardVersion(ctx, ward, version)
+		if err == nil {
+			return updated, nil
+		}
+		if status.Code(err) != codes.Aborted {
+			return nil, err
+		}
+
+		if attempt == wardUpdateAttempts {
+			logger.Warn("Подопечный %d: конфликт версий после %d попыток", id, attempt)
+			return nil, errWardConflict
+		}
+
+		delay := time.Duration(attempt)*wardRetryDelay + time.Duration(rand.Int63n(int64(wardRetryDelay)))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// updateWardVersion - записывает подопечного, если его версия в базе все еще равна version
+func (route *Router) updateWardVersion(ctx context.Context, ward *DatabaseServicev1.Ward,
+	version string) (*DatabaseServicev1.Ward, error) {
+	current, err := route.databaseService.FindWardById(ctx, &DatabaseServicev1.FindWardByIdRequest{Id: ward.GetId()})
+	if err != nil {
+		return nil, err
+	}
+	if current.GetUpdatedAt() != version {
+		return nil, errWardConflict
+	}
+
+	return route.databaseService.UpdateWard(ctx, ward)
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWardCollectedConcurrent(t *testing.T) {
+	const payments = 100
+
+	db := newFakeDatabase()
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Necessary: 100000, UpdatedAt: "0"})
+	db.addCard(&DatabaseServicev1.Card{Id: 7, Number: "4111111111111111", UserId: 1})
+
+	// Два экземпляра шлюза с общей базой: внутри экземпляра изменения идут по очереди,
+	// между экземплярами конфликты разрешаются повтором по UpdatedAt
+	first, _ := newTestRouter(t, db)
+	second, _ := newTestRouter(t, db)
+
+	var wg sync.WaitGroup
+	for i := 0; i < payments; i++ {
+		route := first
+		if i%2 == 1 {
+			route = second
+		}
+
+		wg.Add(1)
+		go func(paymentId string) {
+			defer wg.Done()
+
+			err := route.payments.Run(context.Background(), &paymentData{PaymentId: paymentId, UserId: 1, WardId: 5,
+				CardId: 7, Amount: 1000})
+			if err != nil {
+				t.Errorf("платеж: %v", err)
+			}
+		}(fmt.Sprintf("payment%d", i))
+	}
+	wg.Wait()
+
+	if got := db.ward(5).GetCollected(); got != payments*10 {
+		t.Errorf("собрано: %v, want %v", got, payments*10)
+	}
+	if got := db.donationCount(); got != payments {
+		t.Errorf("создано пожертвований: %d, want %d", got, payments)
+	}
+	if first.wardLocks.len() != 0 || second.wardLocks.len() != 0 {
+		t.Errorf("блокировки подопечных не освобождены: %d, %d", first.wardLocks.len(), second.wardLocks.len())
+	}
+}
+
+func TestWardChangeRetriesOnConcurrentWrite(t *testing.T) {
+	db := newFakeDatabase()
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Collected: 300, UpdatedAt: "0"})
+	route, _ := newTestRouter(t, db)
+
+	// Другой экземпляр или администратор изменяет подопечного между чтением и записью шлюза
+	writes := 0
+	db.beforeUpdateWard = func(db *fakeDatabase) {
+		writes++
+		db.version++
+		ward := db.wards[5]
+		ward.Collected += 50
+		ward.UpdatedAt = strconv.FormatUint(db.version, 10)
+	}
+
+	ward, err := route.changeWard(context.Background(), 5, func(ward *DatabaseServicev1.Ward) error {
+		ward.Collected += 10
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if writes != 1 {
+		t.Fatalf("конкурентных записей: %d, want 1", writes)
+	}
+	if ward.GetCollected() != 360 || db.ward(5).GetCollected() != 360 {
+		t.Errorf("собрано: %v (в базе %v), want 360", ward.GetCollected(), db.ward(5).GetCollected())
+	}
+}
+
+func TestUpdateWardKeepsCollected(t *testing.T) {
+	admin := &DatabaseServicev1.CreateUserResponse{Id: 1, Phone: "+79991234567", Role: RoleAdmin}
+	db := newFakeDatabase(admin)
+	db.addWard(&DatabaseServicev1.Ward{Id: 5, Want: "Лекарства", Collected: 300, UpdatedAt: "0"})
+	route, _ := newTestRouter(t, db)
+
+	tokens, err := route.openSession(context.Background(), admin, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer := "Bearer " + tokens.Token
+
+	// Собранная сумма из запроса игнорируется
+	rec := serveWith(route, http.MethodPut, "/api/v1/wards", bearer,
+		`{"id":5,"want":"Коляска","collected":0,"updatedAt":"0"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	ward := db.ward(5)
+	if ward.GetWant() != "Коляска" || ward.GetCollected() != 300 {
+		t.Errorf("подопечный после обновления: want = %q, collected = %v", ward.GetWant(), ward.GetCollected())
+	}
+
+	// Обновление устаревшей копии отклоняется
+	rec = serveWith(route, http.MethodPut, "/api/v1/wards", bearer, `{"id":5,"want":"Лекарства","updatedAt":"0"}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "изменен другим запросом") {
+		t.Errorf("устаревшая копия: code = %d, body = %s", rec.Code, rec.Body)
+	}
+}
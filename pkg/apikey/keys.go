@@ -0,0 +1,154 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Prefix - префикс ключа API, по нему ключи проще найти в логах и репозиториях при утечке
+const Prefix = "agw_"
+
+// touchInterval - время последнего использования обновляется не чаще, чтобы не перезаписывать хранилище
+// на каждый запрос
+const touchInterval = time.Minute
+
+var (
+	ErrInvalidKey = errors.New("неверный ключ API")
+	ErrExpired    = errors.New("срок действия ключа API истек")
+	ErrRevoked    = errors.New("ключ API отозван")
+	ErrNotFound   = errors.New("ключ API не найден")
+)
+
+// Manager - выпуск, проверка и отзыв ключей API. Ключ имеет вид agw_<id>.<secret>, где id - открытый
+// идентификатор для поиска в хранилище, secret - случайная строка, хранится только ее SHA256
+type Manager struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewManager - создает Manager поверх хранилища store
+func NewManager(store Store) *Manager {
+	return &Manager{store: store, now: time.Now}
+}
+
+// Issue - выпускает ключ с областями scopes, ttl = 0 - бессрочный ключ. Возвращает сам ключ, который
+// показывается только один раз, и его описание
+func (m *Manager) Issue(ctx context.Context, name string, scopes []string, ttl time.Duration,
+	createdBy uint64) (string, Key, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return "", Key{}, err
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", Key{}, err
+	}
+
+	now := m.now().UTC()
+
+	key := Key{
+		Id:        id,
+		Name:      name,
+		Hash:      hashSecret(secret),
+		Scopes:    slices.Clone(scopes),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		key.ExpiresAt = now.Add(ttl)
+	}
+
+	if err = m.store.Put(ctx, key); err != nil {
+		return "", Key{}, err
+	}
+
+	return Prefix + id + "." + secret, key, nil
+}
+
+// Authenticate - проверяет ключ и отмечает его использование
+func (m *Manager) Authenticate(ctx context.Context, raw string) (Key, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(raw, Prefix), ".")
+	if !ok || !strings.HasPrefix(raw, Prefix) || id == "" || secret == "" {
+		return Key{}, ErrInvalidKey
+	}
+
+	key, found, err := m.store.Get(ctx, id)
+	if err != nil {
+		return Key{}, err
+	}
+	if !found || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashSecret(secret))) != 1 {
+		return Key{}, ErrInvalidKey
+	}
+
+	now := m.now().UTC()
+
+	if !key.RevokedAt.IsZero() {
+		return Key{}, ErrRevoked
+	}
+	if !key.ExpiresAt.IsZero() && !now.Before(key.ExpiresAt) {
+		return Key{}, ErrExpired
+	}
+
+	if now.Sub(key.LastUsedAt) >= touchInterval {
+		key.LastUsedAt = now
+		if err = m.store.Put(ctx, key); err != nil {
+			return Key{}, err
+		}
+	}
+
+	return key, nil
+}
+
+// Revoke - отзывает ключ, повторный отзыв не меняет время отзыва
+func (m *Manager) Revoke(ctx context.Context, id string) (Key, error) {
+	key, found, err := m.store.Get(ctx, id)
+	if err != nil {
+		return Key{}, err
+	}
+	if !found {
+		return Key{}, ErrNotFound
+	}
+
+	if key.RevokedAt.IsZero() {
+		key.RevokedAt = m.now().UTC()
+		if err = m.store.Put(ctx, key); err != nil {
+			return Key{}, err
+		}
+	}
+
+	return key, nil
+}
+
+// List - возвращает все выпущенные ключи
+func (m *Manager) List(ctx context.Context) ([]Key, error) {
+	return m.store.List(ctx)
+}
+
+// HasScope - ключ содержит область scope
+func (k Key) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// hashSecret - SHA256 секретной части ключа. Секрет случайный и длинный, поэтому медленный хеш не нужен
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex - случайная строка из n байт в hex
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,71 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	path := filepath.Join(t.TempDir(), "apikeys.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewManager(store)
+	manager.now = func() time.Time { return now }
+
+	raw, key, err := manager.Issue(ctx, "cron", []string{"wards:write"}, time.Hour, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bad := range []string{"", "agw_", key.Id, "agw_" + key.Id + ".wrong", raw[len(Prefix):]} {
+		if _, err = manager.Authenticate(ctx, bad); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Authenticate(%q): err = %v, want ErrInvalidKey", bad, err)
+		}
+	}
+
+	got, err := manager.Authenticate(ctx, raw)
+	if err != nil {
+		t.Fatalf("Authenticate(): %v", err)
+	}
+	if !got.HasScope("wards:write") || got.HasScope("wards:read") {
+		t.Errorf("области ключа: %v", got.Scopes)
+	}
+
+	// Время использования и хеш сохраняются в файле, сам ключ - нет
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, _, _ := reloaded.Get(ctx, key.Id)
+	if !stored.LastUsedAt.Equal(now) || stored.Hash == "" || stored.Hash == raw {
+		t.Errorf("сохраненный ключ: %+v", stored)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err = manager.Authenticate(ctx, raw); !errors.Is(err, ErrExpired) {
+		t.Errorf("истекший ключ: err = %v, want ErrExpired", err)
+	}
+
+	raw, key, err = manager.Issue(ctx, "partner", []string{"donations:read"}, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = manager.Revoke(ctx, key.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = manager.Authenticate(ctx, raw); !errors.Is(err, ErrRevoked) {
+		t.Errorf("отозванный ключ: err = %v, want ErrRevoked", err)
+	}
+	if _, err = manager.Revoke(ctx, "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Revoke() неизвестного ключа: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,120 @@
+package apikey
+
+import (
+	"apiGateway/pkg/filestore"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Key - ключ API. Сам ключ не хранится, хранится только SHA256 его секретной части
+type Key struct {
+	Id         string    `json:"id"`
+	Name       string    `json:"name"`                 // Назначение ключа (интеграция, задача)
+	Hash       string    `json:"hash"`                 // SHA256 секретной части ключа
+	Scopes     []string  `json:"scopes"`               // Области доступа в формате "группа:read" или "группа:write"
+	CreatedBy  uint64    `json:"createdBy"`            // ID администратора, выпустившего ключ
+	CreatedAt  time.Time `json:"createdAt"`            // Время выпуска
+	ExpiresAt  time.Time `json:"expiresAt,omitempty"`  // Срок действия, нулевое значение - бессрочный ключ
+	LastUsedAt time.Time `json:"lastUsedAt,omitempty"` // Время последнего использования
+	RevokedAt  time.Time `json:"revokedAt,omitempty"`  // Время отзыва, отозванные ключи хранятся для аудита
+}
+
+// Store - хранилище ключей API
+type Store interface {
+	// Get - возвращает ключ по ID, ok = false, если ключ не найден
+	Get(ctx context.Context, id string) (key Key, ok bool, err error)
+	// Put - сохраняет ключ
+	Put(ctx context.Context, key Key) error
+	// List - возвращает все ключи, включая отозванные, в порядке выпуска
+	List(ctx context.Context) ([]Key, error)
+}
+
+// MemoryStore - ключи API в памяти процесса, теряются при перезапуске (только для разработки и тестов)
+type MemoryStore struct {
+	mu   sync.Mutex
+	keys map[string]Key
+}
+
+// NewMemoryStore - создает хранилище ключей API в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[string]Key)}
+}
+
+// Get - возвращает ключ по ID
+func (s *MemoryStore) Get(_ context.Context, id string) (Key, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+
+	return key, ok, nil
+}
+
+// Put - сохраняет ключ
+func (s *MemoryStore) Put(_ context.Context, key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.Id] = key
+
+	return nil
+}
+
+// List - возвращает все ключи
+func (s *MemoryStore) List(_ context.Context) ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return sortedKeys(s.keys), nil
+}
+
+// FileStore - ключи API в JSON файле, файл перезаписывается целиком при каждом изменении
+type FileStore struct {
+	keys *filestore.Map[string, Key]
+}
+
+// NewFileStore - создает хранилище ключей API в файле path, существующий файл загружается
+func NewFileStore(path string) (*FileStore, error) {
+	keys, err := filestore.Open[string, Key](path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{keys: keys}, nil
+}
+
+// Get - возвращает ключ по ID
+func (s *FileStore) Get(_ context.Context, id string) (Key, bool, error) {
+	key, ok := s.keys.Get(id)
+
+	return key, ok, nil
+}
+
+// Put - сохраняет ключ
+func (s *FileStore) Put(_ context.Context, key Key) error {
+	return s.keys.Put(key.Id, key)
+}
+
+// List - возвращает все ключи
+func (s *FileStore) List(_ context.Context) ([]Key, error) {
+	return sortedKeys(s.keys.All()), nil
+}
+
+// sortedKeys - ключи в порядке выпуска
+func sortedKeys(keys map[string]Key) []Key {
+	list := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, key)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].Id < list[j].Id
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list
+}
@@ -0,0 +1,161 @@
+package bankcard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCvvLength - длина CVV, если платежная система неизвестна (номер не указан при обновлении карты)
+const defaultCvvLength = 3
+
+// Input - данные карты из запроса
+type Input struct {
+	Number string // Номер, допускаются пробелы и дефисы между цифрами
+	Date   string // Срок действия в формате MM/YY
+	Cvv    string // CVV/CVC строкой, чтобы не терялись ведущие нули
+}
+
+// FieldError - ошибка в поле запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError - ошибки во всех неверных полях карты
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+
+	return "неверные данные карты: " + strings.Join(messages, ", ")
+}
+
+// Validate - проверяет новую карту: номер и срок действия обязательны, CVV проверяется, если указан
+func Validate(in Input, now time.Time) error {
+	return validate(in, now, true)
+}
+
+// ValidateUpdate - проверяет только указанные поля карты (обновление карты)
+func ValidateUpdate(in Input, now time.Time) error {
+	return validate(in, now, false)
+}
+
+// validate - проверка полей карты, required - номер и срок действия обязательны
+func validate(in Input, now time.Time, required bool) error {
+	var fields []FieldError
+	add := func(field, message string) {
+		fields = append(fields, FieldError{Field: field, Message: message})
+	}
+
+	cvvLength := defaultCvvLength
+
+	switch {
+	case in.Number == "":
+		if required {
+			add("number", "не указан номер карты")
+		}
+	default:
+		number, ok := normalize(in.Number)
+		s, known := detect(number)
+		switch {
+		case !ok:
+			add("number", "номер карты должен содержать только цифры")
+		case !known:
+			add("number", "платежная система карты не поддерживается")
+		case !s.validLength(number):
+			add("number", fmt.Sprintf("номер карты %s не может содержать %d цифр", s.brand, len(number)))
+		case !Luhn(number):
+			add("number", "неверный номер карты")
+		}
+		if known {
+			cvvLength = s.cvv
+		}
+	}
+
+	switch {
+	case in.Date == "":
+		if required {
+			add("date", "не указан срок действия карты")
+		}
+	default:
+		expires, err := Expiry(in.Date)
+		switch {
+		case err != nil:
+			add("date", err.Error())
+		case !now.Before(expires):
+			add("date", "срок действия карты истек")
+		}
+	}
+
+	if in.Cvv != "" && (len(in.Cvv) != cvvLength || !isDigits(in.Cvv)) {
+		add("cvv", fmt.Sprintf("CVV должен содержать %d цифры", cvvLength))
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+
+	return nil
+}
+
+// Expiry - момент окончания срока действия карты MM/YY: карта действует до конца указанного месяца (UTC)
+func Expiry(date string) (time.Time, error) {
+	month, year, ok := strings.Cut(date, "/")
+	if !ok || len(month) != 2 || len(year) != 2 || !isDigits(month) || !isDigits(year) {
+		return time.Time{}, fmt.Errorf("срок действия карты должен быть в формате MM/YY")
+	}
+
+	m, _ := strconv.Atoi(month)
+	y, _ := strconv.Atoi(year)
+	if m < 1 || m > 12 {
+		return time.Time{}, fmt.Errorf("неверный месяц срока действия карты")
+	}
+
+	return time.Date(2000+y, time.Month(m)+1, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// Luhn - контрольная сумма номера карты по алгоритму Луна
+func Luhn(number string) bool {
+	if number == "" || !isDigits(number) {
+		return false
+	}
+
+	sum := 0
+	for i := range len(number) {
+		digit := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+
+	return sum%10 == 0
+}
+
+// normalize - цифры номера без пробелов и дефисов, ok = false, если номер содержит другие символы
+func normalize(number string) (string, bool) {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(number)
+
+	return digits, digits != "" && isDigits(digits)
+}
+
+// isDigits - строка из цифр 0-9
+func isDigits(str string) bool {
+	for _, r := range str {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
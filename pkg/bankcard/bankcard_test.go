@@ -0,0 +1,79 @@
+package bankcard
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	for number, want := range map[string]Brand{
+		"2200000000000004": Mir,
+		"2204123412341234": Mir,
+		"2221000000000009": Mastercard,
+		"5500000000000004": Mastercard,
+		"4111111111111111": Visa,
+		"6200000000000005": UnionPay,
+		"2205000000000000": "",
+		"3530111333300000": "",
+	} {
+		if got := Detect(number); got != want {
+			t.Errorf("Detect(%s) = %q, want %q", number, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		in     Input
+		update bool
+		fields []string
+	}{
+		{name: "Visa", in: Input{Number: "4111 1111 1111 1111", Date: "03/26", Cvv: "012"}},
+		{name: "Мир", in: Input{Number: "2200-0000-0000-0004", Date: "12/30"}},
+		{name: "Пустая карта", fields: []string{"number", "date"}},
+		{name: "Пустое обновление", update: true},
+		{name: "Контрольная сумма", in: Input{Number: "4111111111111112", Date: "12/30"}, fields: []string{"number"}},
+		{name: "Длина номера", in: Input{Number: "5500000000000000004", Date: "12/30"}, fields: []string{"number"}},
+		{name: "Неизвестная система", in: Input{Number: "3530111333300000", Date: "12/30"}, fields: []string{"number"}},
+		{name: "Буквы в номере", in: Input{Number: "4111x11111111111", Date: "12/30"}, fields: []string{"number"}},
+		{name: "Срок истек", in: Input{Number: "4111111111111111", Date: "02/26"}, fields: []string{"date"}},
+		{name: "Формат срока", in: Input{Number: "4111111111111111", Date: "2030-12"}, fields: []string{"date"}},
+		{name: "Месяц", in: Input{Number: "4111111111111111", Date: "13/30"}, fields: []string{"date"}},
+		{name: "CVV", in: Input{Number: "4111111111111111", Date: "12/30", Cvv: "12"}, fields: []string{"cvv"}},
+		{name: "Все поля", in: Input{Number: "1", Date: "1", Cvv: "1"}, fields: []string{"number", "date", "cvv"}},
+		{name: "Обновление CVV", in: Input{Cvv: "12a"}, update: true, fields: []string{"cvv"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.update {
+				err = ValidateUpdate(tt.in, now)
+			} else {
+				err = Validate(tt.in, now)
+			}
+
+			var got []string
+			var validation *ValidationError
+			if errors.As(err, &validation) {
+				for _, field := range validation.Fields {
+					got = append(got, field.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+
+			if len(got) != len(tt.fields) {
+				t.Fatalf("поля с ошибками: %v, want %v", got, tt.fields)
+			}
+			for i := range got {
+				if got[i] != tt.fields[i] {
+					t.Errorf("поля с ошибками: %v, want %v", got, tt.fields)
+				}
+			}
+		})
+	}
+}
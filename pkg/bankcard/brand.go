@@ -0,0 +1,67 @@
+package bankcard
+
+import (
+	"slices"
+	"strconv"
+)
+
+// Brand - платежная система карты
+type Brand string
+
+const (
+	Mir        Brand = "mir"
+	Visa       Brand = "visa"
+	Mastercard Brand = "mastercard"
+	UnionPay   Brand = "unionpay"
+)
+
+// scheme - диапазон BIN платежной системы: первые prefixLen цифр номера от from до to включительно
+type scheme struct {
+	brand     Brand
+	prefixLen int
+	from, to  int
+	lengths   []int // Допустимая длина номера
+	cvv       int   // Длина CVV/CVC
+}
+
+// schemes - диапазоны BIN, более узкие диапазоны проверяются раньше
+var schemes = []scheme{
+	{brand: Mir, prefixLen: 4, from: 2200, to: 2204, lengths: []int{16, 17, 18, 19}, cvv: 3},
+	{brand: Mastercard, prefixLen: 4, from: 2221, to: 2720, lengths: []int{16}, cvv: 3},
+	{brand: Mastercard, prefixLen: 2, from: 51, to: 55, lengths: []int{16}, cvv: 3},
+	{brand: UnionPay, prefixLen: 2, from: 62, to: 62, lengths: []int{16, 17, 18, 19}, cvv: 3},
+	{brand: Visa, prefixLen: 1, from: 4, to: 4, lengths: []int{13, 16, 19}, cvv: 3},
+}
+
+// Detect - платежная система по номеру карты, пустая строка, если система не поддерживается
+func Detect(number string) Brand {
+	if s, ok := detect(number); ok {
+		return s.brand
+	}
+
+	return ""
+}
+
+// detect - диапазон BIN, в который попадает номер из цифр
+func detect(number string) (scheme, bool) {
+	for _, s := range schemes {
+		if len(number) < s.prefixLen {
+			continue
+		}
+
+		prefix, err := strconv.Atoi(number[:s.prefixLen])
+		if err != nil {
+			return scheme{}, false
+		}
+		if prefix >= s.from && prefix <= s.to {
+			return s, true
+		}
+	}
+
+	return scheme{}, false
+}
+
+// validLength - номер допустимой для платежной системы длины
+func (s scheme) validLength(number string) bool {
+	return slices.Contains(s.lengths, len(number))
+}
@@ -8,6 +8,10 @@ import (
 	"time"
 )
 
+// EnvProd - окружение production: небезопасные настройки для разработки (общий секрет JWT, ключи в памяти)
+// в нем не допускаются
+const EnvProd = "prod"
+
 type ServerConfig struct {
 	Port    int           `yaml:"port"`
 	Timeout time.Duration `yaml:"timeout"`
@@ -19,17 +23,157 @@ type GRPCServerConfig struct {
 	Timeout time.Duration `yaml:"timeout"`
 }
 
+// JwtKey - ключ подписи токенов JWT, закрытый ключ нужен только активному ключу подписи
+type JwtKey struct {
+	Kid        string `yaml:"kid"`         // Идентификатор ключа (заголовок kid)
+	PrivateKey string `yaml:"private_key"` // Путь к PEM файлу закрытого ключа (RSA или Ed25519, PKCS#8)
+	PublicKey  string `yaml:"public_key"`  // Путь к PEM файлу открытого ключа (PKIX)
+}
+
 type Jwt struct {
-	Secret  string `yaml:"secret"`
-	Expires string `yaml:"expires"`
+	Secret         string        `yaml:"secret"`
+	Expires        string        `yaml:"expires"`
+	RefreshExpires string        `yaml:"refresh_expires" env-default:"720h"`
+	SigningKey     string        `yaml:"signing_key"`                       // kid ключа, которым подписываются новые токены
+	Keys           []JwtKey      `yaml:"keys"`                              // Ключи подписи и проверки, без ключей используется HS256 с secret
+	Issuer         string        `yaml:"issuer" env-default:"apiGateway"`   // Издатель токенов (iss)
+	Audience       string        `yaml:"audience" env-default:"apiGateway"` // Получатель токенов (aud)
+	Leeway         time.Duration `yaml:"leeway" env-default:"30s"`          // Допустимое расхождение часов при проверке exp/nbf/iat
+}
+
+// Attempts - ограничение неудачных попыток по одному ключу (телефон, IP)
+type Attempts struct {
+	FreeAttempts int           `yaml:"free_attempts" env-default:"3"` // Неудачных попыток без задержки
+	BaseDelay    time.Duration `yaml:"base_delay" env-default:"1s"`   // Задержка после первой неудачи сверх free_attempts, удваивается
+	MaxDelay     time.Duration `yaml:"max_delay" env-default:"5m"`    // Максимальная задержка между попытками
+	MaxFailures  int           `yaml:"max_failures" env-default:"10"` // Неудачных попыток до временной блокировки
+	Lockout      time.Duration `yaml:"lockout" env-default:"15m"`     // Время блокировки
+	Window       time.Duration `yaml:"window" env-default:"1h"`       // Счетчик сбрасывается через window после последней неудачи
+}
+
+// LoginGuard - защита от перебора паролей
+type LoginGuard struct {
+	Phone Attempts `yaml:"phone"` // Ограничение по номеру телефона
+	Ip    Attempts `yaml:"ip"`    // Ограничение по IP адресу клиента
+}
+
+// Notifier - доставка сообщений пользователям
+type Notifier struct {
+	Type string `yaml:"type" env-default:"log"` // log - вывод в лог, file - запись в файл (только для разработки)
+	Path string `yaml:"path"`                   // Путь к файлу для type: file
+}
+
+// OneTimeCodes - одноразовые коды подтверждения (сброс пароля, подтверждение телефона и email)
+type OneTimeCodes struct {
+	Length         int           `yaml:"length" env-default:"6"`           // Количество цифр в коде
+	TTL            time.Duration `yaml:"ttl" env-default:"10m"`            // Срок действия кода
+	MaxAttempts    int           `yaml:"max_attempts" env-default:"5"`     // Попыток ввода до аннулирования кода
+	ResendInterval time.Duration `yaml:"resend_interval" env-default:"1m"` // Минимальный интервал между отправками кода
+}
+
+// Verifications - подтвержденные телефоны и email пользователей
+type Verifications struct {
+	Store string `yaml:"store"` // JSON файл подтверждений, без него подтверждения хранятся в памяти
+}
+
+// OtpLogin - ограничение запросов кода для входа по SMS, каждый запрос учитывается как попытка
+type OtpLogin struct {
+	Phone Attempts `yaml:"phone"` // Ограничение по номеру телефона
+	Ip    Attempts `yaml:"ip"`    // Ограничение по IP адресу клиента
+}
+
+// Mfa - двухфакторная аутентификация (TOTP)
+type Mfa struct {
+	Issuer        string        `yaml:"issuer" env-default:"apiGateway"`    // Название сервиса в приложении-аутентификаторе
+	Store         string        `yaml:"store"`                              // JSON файл настроек TOTP, без него настройки хранятся в памяти
+	PendingTTL    time.Duration `yaml:"pending_ttl" env-default:"5m"`       // Время жизни токена второго шага входа
+	RequiredRoles []string      `yaml:"required_roles" env-default:"admin"` // Роли, для которых 2FA обязательна
+}
+
+// ApiKeys - ключи API для межсервисных запросов
+type ApiKeys struct {
+	Store      string        `yaml:"store"`                           // JSON файл ключей, без него ключи хранятся в памяти
+	DefaultTTL time.Duration `yaml:"default_ttl" env-default:"2160h"` // Срок действия ключа, если он не указан при выпуске
+	MaxTTL     time.Duration `yaml:"max_ttl" env-default:"8760h"`     // Максимальный срок действия ключа
+}
+
+// Idempotency - ключи идемпотентности (заголовок Idempotency-Key)
+type Idempotency struct {
+	TTL        time.Duration `yaml:"ttl" env-default:"24h"`        // Время хранения ответа на запрос с ключом
+	PendingTTL time.Duration `yaml:"pending_ttl" env-default:"1m"` // Время блокировки ключа выполняющимся запросом
+}
+
+// PaymentSaga - журнал саг платежей
+type PaymentSaga struct {
+	Journal          string        `yaml:"journal"`                            // JSON файл журнала, без него журнал хранится в памяти
+	RecoveryInterval time.Duration `yaml:"recovery_interval" env-default:"1m"` // Интервал повтора незавершенных откатов
+}
+
+// Payment - платежный провайдер и реестр платежей
+type Payment struct {
+	Provider         string        `yaml:"provider" env-default:"fake"`                 // Платежный провайдер: fake - локальная заглушка без списания денег
+	Currency         string        `yaml:"currency" env-default:"RUB"`                  // Валюта платежей
+	Ledger           string        `yaml:"ledger"`                                      // JSON файл реестра платежей, без него реестр хранится в памяти
+	WebhookSecret    string        `yaml:"webhook_secret" env:"PAYMENT_WEBHOOK_SECRET"` // Секрет подписи уведомлений провайдера, обязателен
+	WebhookTolerance time.Duration `yaml:"webhook_tolerance" env-default:"5m"`          // Допустимое расхождение времени подписи уведомления
+	WebhookDedupeTTL time.Duration `yaml:"webhook_dedupe_ttl" env-default:"72h"`        // Время хранения ID обработанных уведомлений
+	RefundWindow     time.Duration `yaml:"refund_window" env-default:"336h"`            // Срок, в течение которого владелец может вернуть платеж, администратор - без ограничения
+}
+
+// Subscriptions - ежемесячные пожертвования и планировщик платежей
+type Subscriptions struct {
+	Store          string        `yaml:"store"`                              // JSON файл подписок, без него подписки хранятся в памяти
+	Scheduler      bool          `yaml:"scheduler" env-default:"true"`       // Планировщик платежей, при нескольких экземплярах шлюза включается на одном
+	DryRun         bool          `yaml:"dry_run"`                            // Планировщик только записывает в лог платежи, которые выполнил бы
+	CheckInterval  time.Duration `yaml:"check_interval" env-default:"1m"`    // Интервал проверки подписок
+	RetryBaseDelay time.Duration `yaml:"retry_base_delay" env-default:"1h"`  // Задержка повтора после отказа, удваивается с каждой попыткой
+	RetryMaxDelay  time.Duration `yaml:"retry_max_delay" env-default:"24h"`  // Максимальная задержка повтора
+	MaxAttempts    int           `yaml:"max_attempts" env-default:"5"`       // Попыток платежа за период, после них период пропускается
+	CatchUpWindow  time.Duration `yaml:"catch_up_window" env-default:"168h"` // Пропущенные за время простоя платежи старше этого срока не выполняются
+}
+
+// VaultKey - ключ шифрования номеров карт
+type VaultKey struct {
+	Version int    `yaml:"version"` // Версия ключа, сохраняется вместе с зашифрованным номером
+	Key     string `yaml:"key"`     // 32 байта (AES-256) в base64
+}
+
+// Vault - хранилище номеров карт, в DatabaseService вместо номера передается токен
+type Vault struct {
+	Store          string     `yaml:"store"`                           // JSON файл зашифрованных номеров, без него номера хранятся в памяти
+	KeysFile       string     `yaml:"keys_file" env:"VAULT_KEYS_FILE"` // YAML файл с active_key, keys и fingerprint_key, в окружении prod обязателен
+	ActiveKey      int        `yaml:"active_key"`                      // Версия ключа, которым шифруются новые номера
+	Keys           []VaultKey `yaml:"keys"`                            // Ключи шифрования, без ключей используются случайные ключи до перезапуска
+	FingerprintKey string     `yaml:"fingerprint_key"`                 // Ключ HMAC отпечатка номера карты, 32 байта в base64, не меняется при ротации
+}
+
+// Pagination - постраничная выдача списков
+type Pagination struct {
+	DefaultLimit int           `yaml:"default_limit" env-default:"50"` // Размер страницы без параметра limit
+	MaxLimit     int           `yaml:"max_limit" env-default:"500"`    // Наибольшее значение limit
+	CacheSize    int           `yaml:"cache_size" env-default:"64"`    // Результатов в кэше списков, 0 - без кэша
+	CacheTTL     time.Duration `yaml:"cache_ttl" env-default:"10s"`    // Время хранения результата, 0 - без кэша
 }
 
 type Config struct {
-	Env        string           `yaml:"env" env-default:"local"`
-	APIServer  ServerConfig     `yaml:"api_server"`
-	GRPCServer GRPCServerConfig `yaml:"grpc_server"`
-	Swagger    bool             `yaml:"swagger"`
-	Jwt        Jwt              `yaml:"jwt"`
+	Env           string           `yaml:"env" env-default:"local"`
+	APIServer     ServerConfig     `yaml:"api_server"`
+	GRPCServer    GRPCServerConfig `yaml:"grpc_server"`
+	Swagger       bool             `yaml:"swagger"`
+	Jwt           Jwt              `yaml:"jwt"`
+	LoginGuard    LoginGuard       `yaml:"login_guard"`
+	Notifier      Notifier         `yaml:"notifier"`
+	Codes         OneTimeCodes     `yaml:"one_time_codes"`
+	Verifications Verifications    `yaml:"verifications"`
+	OtpLogin      OtpLogin         `yaml:"otp_login"`
+	Mfa           Mfa              `yaml:"mfa"`
+	ApiKeys       ApiKeys          `yaml:"api_keys"`
+	Idempotency   Idempotency      `yaml:"idempotency"`
+	PaymentSaga   PaymentSaga      `yaml:"payment_saga"`
+	Payment       Payment          `yaml:"payment"`
+	Subscriptions Subscriptions    `yaml:"subscriptions"`
+	Vault         Vault            `yaml:"vault"`
+	Pagination    Pagination       `yaml:"pagination"`
 }
 
 func MustLoad() *Config {
@@ -53,9 +197,61 @@ func MustLoadByPath(configPath string) *Config {
 		panic(any(fmt.Sprintf("Ошибка чтения файла конфигурации: %v", err)))
 	}
 
+	if cfg.Vault.KeysFile != "" {
+		if err := loadVaultKeys(&cfg.Vault); err != nil {
+			panic(any(fmt.Sprintf("Ошибка чтения ключей хранилища карт: %v", err)))
+		}
+	}
+
 	return cfg
 }
 
+// FileStores - настроенные файловые хранилища (pkg/filestore) в виде "параметр (путь)"
+func (cfg *Config) FileStores() []string {
+	stores := []struct {
+		name string
+		path string
+	}{
+		{"verifications.store", cfg.Verifications.Store},
+		{"mfa.store", cfg.Mfa.Store},
+		{"api_keys.store", cfg.ApiKeys.Store},
+		{"payment_saga.journal", cfg.PaymentSaga.Journal},
+		{"payment.ledger", cfg.Payment.Ledger},
+		{"subscriptions.store", cfg.Subscriptions.Store},
+		{"vault.store", cfg.Vault.Store},
+	}
+
+	var configured []string
+	for _, store := range stores {
+		if store.path != "" {
+			configured = append(configured, fmt.Sprintf("%s (%s)", store.name, store.path))
+		}
+	}
+
+	return configured
+}
+
+// loadVaultKeys - читает ключи хранилища карт из файла vault.KeysFile. Ключи - секрет, поэтому хранятся отдельно
+// от конфигурации (например, в смонтированном файле с ограниченным доступом)
+func loadVaultKeys(vault *Vault) error {
+	if len(vault.Keys) > 0 || vault.FingerprintKey != "" {
+		return fmt.Errorf("ключи указаны и в конфигурации, и в файле %s", vault.KeysFile)
+	}
+
+	keys := struct {
+		ActiveKey      int        `yaml:"active_key"`
+		Keys           []VaultKey `yaml:"keys"`
+		FingerprintKey string     `yaml:"fingerprint_key"`
+	}{}
+	if err := cleanenv.ReadConfig(vault.KeysFile, &keys); err != nil {
+		return err
+	}
+
+	vault.ActiveKey, vault.Keys, vault.FingerprintKey = keys.ActiveKey, keys.Keys, keys.FingerprintKey
+
+	return nil
+}
+
 // fetchConfigPath - парсинг пути к конфигурации из флага или переменной окружения
 func fetchConfigPath() string {
 	var res string
@@ -0,0 +1,118 @@
+package filestore
+
+import (
+	"apiGateway/pkg/utilities"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+// Load - загружает JSON файл path в value, отсутствующий файл не считается ошибкой и value не меняется
+func Load(path string, value any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, value)
+}
+
+// Save - атомарно перезаписывает JSON файл path значением value, при сбое записи прежнее содержимое сохраняется
+func Save(path string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return utilities.WriteFileAtomic(path, data, 0600)
+}
+
+// Map - записи в памяти процесса с копией в JSON файле: файл загружается при создании и перезаписывается целиком
+// при каждом изменении, изменение, которое не удалось записать в файл, отменяется. Файл не разделяется между
+// экземплярами шлюза, поэтому Map подходит только для одного экземпляра
+type Map[K comparable, V any] struct {
+	mu      sync.Mutex
+	path    string
+	records map[K]V
+}
+
+// Open - создает Map в файле path, существующий файл загружается
+func Open[K comparable, V any](path string) (*Map[K, V], error) {
+	m := &Map[K, V]{path: path, records: make(map[K]V)}
+
+	if err := Load(path, &m.records); err != nil {
+		return nil, err
+	}
+	if m.records == nil {
+		m.records = make(map[K]V)
+	}
+
+	return m, nil
+}
+
+// Get - возвращает запись по ключу
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.records[key]
+
+	return value, ok
+}
+
+// Put - сохраняет запись
+func (m *Map[K, V]) Put(key K, value V) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous, existed := m.records[key]
+	m.records[key] = value
+
+	if err := Save(m.path, m.records); err != nil {
+		if existed {
+			m.records[key] = previous
+		} else {
+			delete(m.records, key)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete - удаляет запись, отсутствующая запись не считается ошибкой
+func (m *Map[K, V]) Delete(key K) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous, existed := m.records[key]
+	if !existed {
+		return nil
+	}
+
+	delete(m.records, key)
+
+	if err := Save(m.path, m.records); err != nil {
+		m.records[key] = previous
+		return err
+	}
+
+	return nil
+}
+
+// All - копия всех записей
+func (m *Map[K, V]) All() map[K]V {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records := make(map[K]V, len(m.records))
+	for key, value := range m.records {
+		records[key] = value
+	}
+
+	return records
+}
@@ -0,0 +1,48 @@
+package filestore
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.json")
+
+	m, err := Open[uint64, string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Put(1, "one"); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Put(2, "two"); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Изменения сохраняются в файле
+	reloaded, err := Open[uint64, string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records := reloaded.All(); len(records) != 1 || records[2] != "two" {
+		t.Errorf("записи после загрузки: %v", records)
+	}
+}
+
+func TestMapWriteFailed(t *testing.T) {
+	// Каталога нет, запись в файл завершается ошибкой
+	m, err := Open[string, int](filepath.Join(t.TempDir(), "missing", "records.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = m.Put("key", 1); err == nil {
+		t.Fatal("Put() без каталога: err = nil")
+	}
+	if _, ok := m.Get("key"); ok {
+		t.Error("запись, которую не удалось сохранить, осталась в памяти")
+	}
+}
@@ -0,0 +1,104 @@
+package idempotency
+
+import (
+	"apiGateway/pkg/config"
+	"apiGateway/pkg/logger"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInProgress = errors.New("запрос с этим ключом идемпотентности еще выполняется")
+	ErrMismatch   = errors.New("ключ идемпотентности уже использован для другого запроса")
+)
+
+// Keeper - обработка запросов с ключом идемпотентности: первый запрос выполняется, его ответ сохраняется
+// на TTL и возвращается на повторы с тем же ключом
+type Keeper struct {
+	policy config.Idempotency
+	store  Store
+	now    func() time.Time
+}
+
+// NewKeeper - создает Keeper поверх хранилища store
+func NewKeeper(policy config.Idempotency, store Store) *Keeper {
+	return &Keeper{policy: policy, store: store, now: time.Now}
+}
+
+// Begin - начинает обработку запроса с телом body. Возвращает сохраненный ответ, если запрос уже выполнен
+// (replay = true), ErrInProgress, если запрос с этим ключом еще выполняется, ErrMismatch, если ключ использован
+// для запроса с другим телом. При replay = false и err = nil запрос нужно выполнить и вызвать Complete или Release
+func (k *Keeper) Begin(ctx context.Context, key string, body []byte) (response Response, replay bool, err error) {
+	fingerprint := Fingerprint(body)
+
+	record, started, err := k.store.Begin(ctx, key, fingerprint, k.now(), k.policy.PendingTTL)
+	if err != nil {
+		return Response{}, false, err
+	}
+
+	switch {
+	case started:
+		return Response{}, false, nil
+	case record.Fingerprint != fingerprint:
+		return Response{}, false, ErrMismatch
+	case !record.Completed:
+		return Response{}, false, ErrInProgress
+	default:
+		return record.Response, true, nil
+	}
+}
+
+// Complete - сохраняет ответ на запрос
+func (k *Keeper) Complete(ctx context.Context, key string, response Response) error {
+	return k.store.Complete(ctx, key, response, k.now(), k.policy.TTL)
+}
+
+// Release - освобождает ключ без сохранения ответа, повтор с тем же ключом будет выполнен заново
+func (k *Keeper) Release(ctx context.Context, key string) error {
+	return k.store.Release(ctx, key)
+}
+
+// Hold - продлевает блокировку ключа выполняющимся запросом каждые PendingTTL/2, пока не вызвана stop, поэтому
+// запрос, который выполняется дольше PendingTTL, не будет выполнен повторно
+func (k *Keeper) Hold(ctx context.Context, key string) (stop func()) {
+	if k.policy.PendingTTL <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(k.policy.PendingTTL / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := k.store.Extend(ctx, key, k.now(), k.policy.PendingTTL); err != nil {
+					logger.Error("Ошибка при продлении ключа идемпотентности: %v", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// Fingerprint - хеш тела запроса
+func Fingerprint(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Response - сохраненный ответ на запрос
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Record - запись по ключу идемпотентности
+type Record struct {
+	Fingerprint string    // Хеш тела первого запроса, повтор с другим телом отклоняется
+	Completed   bool      // Запрос обработан, Response содержит ответ; false - запрос еще выполняется
+	Response    Response  // Ответ на первый запрос
+	CreatedAt   time.Time // Время первого запроса
+}
+
+// Store - хранилище ключей идемпотентности. Для одного экземпляра шлюза достаточно MemoryStore,
+// при нескольких экземплярах нужна общая реализация (например, Redis: SET NX + EXPIRE)
+type Store interface {
+	// Begin - атомарно создает запись в состоянии обработки, если ключа нет или его запись истекла, и возвращает
+	// started = true. Иначе возвращает существующую запись. Запись в состоянии обработки истекает через ttl
+	Begin(ctx context.Context, key string, fingerprint string, now time.Time, ttl time.Duration) (Record, bool, error)
+	// Complete - сохраняет ответ, запись хранится ttl
+	Complete(ctx context.Context, key string, response Response, now time.Time, ttl time.Duration) error
+	// Release - удаляет запись, запрос с тем же ключом будет выполнен заново
+	Release(ctx context.Context, key string) error
+	// Extend - продлевает запись в состоянии обработки на ttl, завершенная или удаленная запись не изменяется
+	Extend(ctx context.Context, key string, now time.Time, ttl time.Duration) error
+}
+
+// sweepInterval - как часто MemoryStore удаляет истекшие записи
+const sweepInterval = time.Minute
+
+// memoryRecord - запись MemoryStore со сроком хранения
+type memoryRecord struct {
+	Record
+	expires time.Time
+}
+
+// MemoryStore - ключи идемпотентности в памяти процесса
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]memoryRecord
+	lastSweep time.Time
+}
+
+// NewMemoryStore - создает хранилище ключей идемпотентности в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]memoryRecord)}
+}
+
+// Begin - создает запись в состоянии обработки или возвращает существующую
+func (s *MemoryStore) Begin(_ context.Context, key string, fingerprint string, now time.Time,
+	ttl time.Duration) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && now.Before(record.expires) {
+		return record.Record, false, nil
+	}
+
+	record := memoryRecord{
+		Record:  Record{Fingerprint: fingerprint, CreatedAt: now},
+		expires: now.Add(ttl),
+	}
+	s.records[key] = record
+
+	return record.Record, true, nil
+}
+
+// Complete - сохраняет ответ
+func (s *MemoryStore) Complete(_ context.Context, key string, response Response, now time.Time,
+	ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[key]
+	record.Completed = true
+	record.Response = response
+	record.expires = now.Add(ttl)
+	s.records[key] = record
+
+	return nil
+}
+
+// Release - удаляет запись
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+// Extend - продлевает запись в состоянии обработки
+func (s *MemoryStore) Extend(_ context.Context, key string, now time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok && !record.Completed {
+		record.expires = now.Add(ttl)
+		s.records[key] = record
+	}
+
+	return nil
+}
+
+// sweep - удаляет истекшие записи не чаще sweepInterval, вызывается под блокировкой
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !now.Before(record.expires) {
+			delete(s.records, key)
+		}
+	}
+}
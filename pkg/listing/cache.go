@@ -0,0 +1,90 @@
+package listing
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache - результаты запросов списков (отфильтрованные и отсортированные элементы) на время ttl. Хранится не больше
+// size результатов, при переполнении удаляется давно не использованный
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // Начало списка - последний использованный результат
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+// cacheEntry - результат запроса в кэше
+type cacheEntry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+}
+
+// NewCache - создает кэш на size результатов, при size или ttl равном 0 результаты не кэшируются
+func NewCache(size int, ttl time.Duration) *Cache {
+	return &Cache{size: size, ttl: ttl, order: list.New(), entries: make(map[string]*list.Element), now: time.Now}
+}
+
+// Get - результат по ключу, ok = false, если результата нет или срок его хранения истек
+func (c *Cache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+// Put - сохраняет результат
+func (c *Cache) Put(key string, value any) {
+	if c.size <= 0 || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, expiresAt: c.now().Add(c.ttl)})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Load - отфильтрованные и отсортированные элементы запроса из кэша, при промахе элементы загружаются fetch
+func Load[T any](cache *Cache, res Resource[T], query Query, fetch func() ([]T, error)) ([]T, error) {
+	key := res.Key(query)
+	if cached, ok := cache.Get(key); ok {
+		return cached.([]T), nil
+	}
+
+	items, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := res.Apply(query, items)
+	cache.Put(key, sorted)
+
+	return sorted, nil
+}
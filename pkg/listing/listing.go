@@ -0,0 +1,259 @@
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Error - неверный параметр списка
+type Error struct {
+	Param   string // limit, cursor, sort или filter
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Param + ": " + e.Message
+}
+
+// Field - поле ресурса, по которому можно сортировать или фильтровать
+type Field[T any] struct {
+	Sort   bool
+	Filter bool
+	// Value - значение поля элемента: строка, bool или целое число (в том числе money.Amount)
+	Value func(T) any
+}
+
+// Resource - список элементов T: допустимые поля, сортировка по умолчанию и ID, который делает порядок однозначным
+type Resource[T any] struct {
+	Name        string
+	Fields      map[string]Field[T]
+	DefaultSort string // Поле сортировки по умолчанию, "-" перед именем - по убыванию
+	Id          func(T) uint64
+}
+
+// Limits - размер страницы
+type Limits struct {
+	Default int // Размер страницы без параметра limit
+	Max     int // Наибольшее значение limit
+}
+
+// Condition - условие фильтра: значение поля равно value
+type Condition struct {
+	Field string
+	Value string
+}
+
+// Query - проверенные параметры списка
+type Query struct {
+	Limit  int
+	Sort   string      // Поле сортировки, "-" перед именем - по убыванию
+	Filter []Condition // Условия, упорядоченные по имени поля
+	after  *cursor     // Позиция, после которой начинается страница
+}
+
+// cursor - последний элемент предыдущей страницы. Курсор действителен только с теми же sort и filter
+type cursor struct {
+	Sort   string `json:"s"`
+	Filter string `json:"f"`
+	Key    string `json:"k"` // Ключ сортировки элемента
+	Id     uint64 `json:"i"`
+}
+
+// Parse - разбирает и проверяет параметры limit, cursor, sort и filter. Фильтр задается как filter=поле:значение,
+// несколько условий перечисляются через запятую или повтором параметра
+func (res Resource[T]) Parse(values url.Values, limits Limits) (Query, error) {
+	query := Query{Limit: limits.Default, Sort: res.DefaultSort}
+
+	if str := values.Get("limit"); str != "" {
+		limit, err := strconv.Atoi(str)
+		if err != nil || limit < 1 || limit > limits.Max {
+			return Query{}, &Error{Param: "limit", Message: fmt.Sprintf("должен быть числом от 1 до %d", limits.Max)}
+		}
+		query.Limit = limit
+	}
+
+	if str := values.Get("sort"); str != "" {
+		field, ok := res.Fields[strings.TrimPrefix(str, "-")]
+		if !ok || !field.Sort {
+			return Query{}, &Error{Param: "sort", Message: "сортировка возможна по полям " + res.fields(true)}
+		}
+		query.Sort = str
+	}
+
+	seen := make(map[string]bool)
+	for _, param := range values["filter"] {
+		for _, condition := range strings.Split(param, ",") {
+			name, value, ok := strings.Cut(condition, ":")
+			field, known := res.Fields[name]
+			switch {
+			case !ok || value == "":
+				return Query{}, &Error{Param: "filter", Message: "условие должно быть в формате поле:значение"}
+			case !known || !field.Filter:
+				return Query{}, &Error{Param: "filter", Message: "фильтр возможен по полям " + res.fields(false)}
+			case seen[name]:
+				return Query{}, &Error{Param: "filter", Message: "поле " + name + " указано дважды"}
+			}
+			seen[name] = true
+			query.Filter = append(query.Filter, Condition{Field: name, Value: value})
+		}
+	}
+	sort.Slice(query.Filter, func(i, j int) bool { return query.Filter[i].Field < query.Filter[j].Field })
+
+	if str := values.Get("cursor"); str != "" {
+		after, err := decodeCursor(str)
+		if err != nil {
+			return Query{}, &Error{Param: "cursor", Message: "неверный курсор"}
+		}
+		if after.Sort != query.Sort || after.Filter != query.filter() {
+			return Query{}, &Error{Param: "cursor", Message: "курсор получен с другими параметрами sort или filter"}
+		}
+		query.after = &after
+	}
+
+	return query, nil
+}
+
+// Key - ключ результата запроса в кэше: ресурс, сортировка и фильтр без позиции страницы
+func (res Resource[T]) Key(query Query) string {
+	return res.Name + "?" + query.Sort + "&" + query.filter()
+}
+
+// Apply - элементы, подходящие под фильтр, в порядке сортировки. Исходный срез не изменяется
+func (res Resource[T]) Apply(query Query, items []T) []T {
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if res.match(query.Filter, item) {
+			result = append(result, item)
+		}
+	}
+
+	sortKey, desc := res.sortKey(query.Sort)
+	sort.SliceStable(result, func(i, j int) bool {
+		return less(sortKey(result[i]), res.Id(result[i]), sortKey(result[j]), res.Id(result[j]), desc)
+	})
+
+	return result
+}
+
+// Page - страница отсортированных элементов после курсора и курсор следующей страницы, пустой на последней странице
+func (res Resource[T]) Page(query Query, sorted []T) ([]T, string) {
+	sortKey, desc := res.sortKey(query.Sort)
+
+	start := 0
+	if query.after != nil {
+		start = sort.Search(len(sorted), func(i int) bool {
+			return less(query.after.Key, query.after.Id, sortKey(sorted[i]), res.Id(sorted[i]), desc)
+		})
+	}
+
+	end := min(start+query.Limit, len(sorted))
+	page := sorted[start:end]
+	if end == len(sorted) || len(page) == 0 {
+		return page, ""
+	}
+
+	last := page[len(page)-1]
+	next := encodeCursor(cursor{Sort: query.Sort, Filter: query.filter(), Key: sortKey(last), Id: res.Id(last)})
+
+	return page, next
+}
+
+// match - элемент подходит под все условия фильтра, строки сравниваются без учета регистра
+func (res Resource[T]) match(conditions []Condition, item T) bool {
+	for _, condition := range conditions {
+		if !strings.EqualFold(fmt.Sprint(res.Fields[condition.Field].Value(item)), condition.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortKey - функция ключа сортировки и направление для параметра sort
+func (res Resource[T]) sortKey(param string) (func(T) string, bool) {
+	name := strings.TrimPrefix(param, "-")
+	field := res.Fields[name]
+
+	return func(item T) string { return key(field.Value(item)) }, name != param
+}
+
+// fields - имена полей сортировки (sortable = true) или фильтра через запятую
+func (res Resource[T]) fields(sortable bool) string {
+	names := make([]string, 0, len(res.Fields))
+	for name, field := range res.Fields {
+		if (sortable && field.Sort) || (!sortable && field.Filter) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// filter - условия фильтра строкой для курсора и ключа кэша
+func (q Query) filter() string {
+	conditions := make([]string, 0, len(q.Filter))
+	for _, condition := range q.Filter {
+		conditions = append(conditions, url.QueryEscape(condition.Field)+":"+url.QueryEscape(condition.Value))
+	}
+
+	return strings.Join(conditions, ",")
+}
+
+// less - порядок элементов: по ключу сортировки в заданном направлении, при равных ключах по возрастанию ID
+func less(keyA string, idA uint64, keyB string, idB uint64, desc bool) bool {
+	if keyA != keyB {
+		return (keyA < keyB) != desc
+	}
+
+	return idA < idB
+}
+
+// key - значение поля строкой, порядок строк совпадает с порядком значений. Целые числа дополняются нулями
+// до одной длины, у знаковых инвертируется старший бит, чтобы отрицательные числа шли раньше положительных
+func key(value any) string {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		if v.Bool() {
+			return "1"
+		}
+		return "0"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("%020d", uint64(v.Int())^(1<<63))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%020d", v.Uint())
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// encodeCursor - непрозрачная строка курсора
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor - курсор из строки
+func decodeCursor(str string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return cursor{}, err
+	}
+
+	var c cursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return cursor{}, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,182 @@
+package listing
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type item struct {
+	id     uint64
+	name   string
+	amount int64
+}
+
+var items = Resource[item]{
+	Name: "items",
+	Fields: map[string]Field[item]{
+		"id":     {Sort: true, Value: func(i item) any { return i.id }},
+		"name":   {Sort: true, Filter: true, Value: func(i item) any { return i.name }},
+		"amount": {Sort: true, Value: func(i item) any { return i.amount }},
+	},
+	DefaultSort: "id",
+	Id:          func(i item) uint64 { return i.id },
+}
+
+var limits = Limits{Default: 2, Max: 10}
+
+func ids(list []item) []uint64 {
+	result := make([]uint64, 0, len(list))
+	for _, i := range list {
+		result = append(result, i.id)
+	}
+	return result
+}
+
+func equal(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPages(t *testing.T) {
+	all := []item{{5, "b", -10}, {1, "a", 300}, {3, "b", 20}, {2, "c", 20}, {4, "a", 0}}
+
+	tests := []struct {
+		query string
+		want  []uint64
+	}{
+		{query: "", want: []uint64{1, 2, 3, 4, 5}},
+		{query: "sort=-id", want: []uint64{5, 4, 3, 2, 1}},
+		{query: "sort=amount", want: []uint64{5, 4, 2, 3, 1}},
+		{query: "sort=-amount", want: []uint64{1, 2, 3, 4, 5}},
+		{query: "sort=name&limit=3", want: []uint64{1, 4, 3, 5, 2}},
+		{query: "filter=name:B", want: []uint64{3, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+
+			var got []uint64
+			for page := 0; page < 10; page++ {
+				query, err := items.Parse(values, limits)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				list, next := items.Page(query, items.Apply(query, all))
+				if len(list) > query.Limit {
+					t.Errorf("страница из %d элементов, limit %d", len(list), query.Limit)
+				}
+				got = append(got, ids(list)...)
+
+				if next == "" {
+					break
+				}
+				values.Set("cursor", next)
+			}
+
+			if !equal(got, tt.want) {
+				t.Errorf("элементы %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorAfterInsert(t *testing.T) {
+	values := url.Values{}
+	query, _ := items.Parse(values, limits)
+
+	_, next := items.Page(query, items.Apply(query, []item{{1, "a", 0}, {2, "b", 0}, {4, "d", 0}}))
+
+	// Элемент, добавленный перед курсором, не сдвигает следующую страницу
+	values.Set("cursor", next)
+	query, err := items.Parse(values, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, _ := items.Page(query, items.Apply(query, []item{{0, "z", 0}, {1, "a", 0}, {2, "b", 0}, {3, "c", 0},
+		{4, "d", 0}}))
+	if got := ids(list); !equal(got, []uint64{3, 4}) {
+		t.Errorf("вторая страница %v", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	values := url.Values{"sort": {"-name"}}
+	query, _ := items.Parse(values, limits)
+	_, next := items.Page(query, items.Apply(query, []item{{1, "a", 0}, {2, "b", 0}, {3, "c", 0}}))
+
+	for query, param := range map[string]string{
+		"limit=0":              "limit",
+		"limit=11":             "limit",
+		"limit=a":              "limit",
+		"sort=password":        "sort",
+		"filter=amount:1":      "filter",
+		"filter=name":          "filter",
+		"filter=name:a,name:b": "filter",
+		"cursor=%%%":           "cursor",
+		"cursor=" + next:       "cursor",
+		"sort=-name&filter=name:a&cursor=" + next: "cursor",
+	} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			values = url.Values{"cursor": {"%%%"}}
+		}
+
+		_, err = items.Parse(values, limits)
+		var listErr *Error
+		if !errors.As(err, &listErr) || listErr.Param != param {
+			t.Errorf("%s: err = %v, want ошибку %s", query, err, param)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache(2, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	fetches := 0
+	fetch := func() ([]item, error) {
+		fetches++
+		return []item{{2, "b", 0}, {1, "a", 0}}, nil
+	}
+
+	load := func(query string) {
+		values, _ := url.ParseQuery(query)
+		parsed, err := items.Parse(values, limits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = Load(cache, items, parsed, fetch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	load("")
+	load("limit=1")
+	if fetches != 1 {
+		t.Errorf("размер страницы не должен влиять на кэш: загрузок %d", fetches)
+	}
+
+	load("sort=-id")
+	load("sort=name")
+	load("")
+	if fetches != 4 {
+		t.Errorf("кэш на 2 результата: загрузок %d, want 4", fetches)
+	}
+
+	now = now.Add(time.Minute)
+	load("")
+	if fetches != 5 {
+		t.Errorf("истекший результат: загрузок %d, want 5", fetches)
+	}
+}
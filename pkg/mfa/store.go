@@ -0,0 +1,123 @@
+package mfa
+
+import (
+	"apiGateway/pkg/filestore"
+	"context"
+	"sync"
+	"time"
+)
+
+// Enrollment - настройка TOTP пользователя
+type Enrollment struct {
+	// Secret - секрет TOTP в base32. В хранилище не сохраняется, кроме записей, созданных до шифрования секретов:
+	// они перешифровываются при следующем сохранении
+	Secret     string    `json:"secret,omitempty"`
+	KeyVersion int       `json:"keyVersion,omitempty"` // Версия ключа, которым зашифрован секрет
+	Ciphertext string    `json:"ciphertext,omitempty"` // Зашифрованный секрет
+	Confirmed  bool      `json:"confirmed"`            // Настройка подтверждена кодом из приложения
+	LastStep   int64     `json:"lastStep"`             // Номер интервала последнего принятого кода, коды не принимаются повторно
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Store - хранилище настроек TOTP пользователей
+type Store interface {
+	// Get - возвращает настройку пользователя, ok = false, если TOTP не настраивался
+	Get(ctx context.Context, userId uint64) (enrollment Enrollment, ok bool, err error)
+	// Put - сохраняет настройку пользователя
+	Put(ctx context.Context, userId uint64, enrollment Enrollment) error
+	// Delete - удаляет настройку пользователя
+	Delete(ctx context.Context, userId uint64) error
+	// List - возвращает настройки всех пользователей
+	List(ctx context.Context) (map[uint64]Enrollment, error)
+}
+
+// MemoryStore - настройки TOTP в памяти процесса, теряются при перезапуске (только для разработки и тестов)
+type MemoryStore struct {
+	mu          sync.Mutex
+	enrollments map[uint64]Enrollment
+}
+
+// NewMemoryStore - создает хранилище настроек TOTP в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{enrollments: make(map[uint64]Enrollment)}
+}
+
+// Get - возвращает настройку пользователя
+func (s *MemoryStore) Get(_ context.Context, userId uint64) (Enrollment, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enrollment, ok := s.enrollments[userId]
+
+	return enrollment, ok, nil
+}
+
+// Put - сохраняет настройку пользователя
+func (s *MemoryStore) Put(_ context.Context, userId uint64, enrollment Enrollment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enrollments[userId] = enrollment
+
+	return nil
+}
+
+// Delete - удаляет настройку пользователя
+func (s *MemoryStore) Delete(_ context.Context, userId uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.enrollments, userId)
+
+	return nil
+}
+
+// List - возвращает настройки всех пользователей
+func (s *MemoryStore) List(_ context.Context) (map[uint64]Enrollment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enrollments := make(map[uint64]Enrollment, len(s.enrollments))
+	for userId, enrollment := range s.enrollments {
+		enrollments[userId] = enrollment
+	}
+
+	return enrollments, nil
+}
+
+// FileStore - настройки TOTP в JSON файле, файл перезаписывается целиком при каждом изменении
+type FileStore struct {
+	enrollments *filestore.Map[uint64, Enrollment]
+}
+
+// NewFileStore - создает хранилище настроек TOTP в файле path, существующий файл загружается
+func NewFileStore(path string) (*FileStore, error) {
+	enrollments, err := filestore.Open[uint64, Enrollment](path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{enrollments: enrollments}, nil
+}
+
+// Get - возвращает настройку пользователя
+func (s *FileStore) Get(_ context.Context, userId uint64) (Enrollment, bool, error) {
+	enrollment, ok := s.enrollments.Get(userId)
+
+	return enrollment, ok, nil
+}
+
+// Put - сохраняет настройку пользователя
+func (s *FileStore) Put(_ context.Context, userId uint64, enrollment Enrollment) error {
+	return s.enrollments.Put(userId, enrollment)
+}
+
+// Delete - удаляет настройку пользователя
+func (s *FileStore) Delete(_ context.Context, userId uint64) error {
+	return s.enrollments.Delete(userId)
+}
+
+// List - возвращает настройки всех пользователей
+func (s *FileStore) List(_ context.Context) (map[uint64]Enrollment, error) {
+	return s.enrollments.All(), nil
+}
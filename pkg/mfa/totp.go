@@ -0,0 +1,228 @@
+package mfa
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"sync"
+	"time"
+)
+
+// Ошибки двухфакторной аутентификации
+var (
+	ErrNotEnrolled    = errors.New("двухфакторная аутентификация не настроена")
+	ErrAlreadyEnabled = errors.New("двухфакторная аутентификация уже включена")
+	ErrInvalidCode    = errors.New("неверный код")
+)
+
+const (
+	period = 30 // Длительность интервала TOTP в секундах
+	skew   = 1  // Допустимое расхождение часов в интервалах
+)
+
+// Cipher - шифрование секретов TOTP версионированными ключами (ключи хранилища карт)
+type Cipher interface {
+	// Seal - шифрует plaintext активным ключом, возвращает версию ключа и шифротекст
+	Seal(aad, plaintext string) (int, string, error)
+	// Open - расшифровывает шифротекст ключом version
+	Open(aad string, version int, ciphertext string) (string, error)
+	// Active - версия активного ключа
+	Active() int
+}
+
+// Authenticator - настройка и проверка кодов TOTP (RFC 6238). Каждый код принимается только один раз,
+// секреты хранятся зашифрованными
+type Authenticator struct {
+	mu     sync.Mutex
+	issuer string
+	store  Store
+	cipher Cipher
+	now    func() time.Time
+}
+
+// NewAuthenticator - создает Authenticator, issuer отображается в приложении-аутентификаторе
+func NewAuthenticator(issuer string, store Store, cipher Cipher) *Authenticator {
+	return &Authenticator{issuer: issuer, store: store, cipher: cipher, now: time.Now}
+}
+
+// Enabled - у пользователя включена (подтверждена) двухфакторная аутентификация
+func (a *Authenticator) Enabled(ctx context.Context, userId uint64) (bool, error) {
+	enrollment, ok, err := a.store.Get(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	return ok && enrollment.Confirmed, nil
+}
+
+// Setup - создает новый секрет TOTP и возвращает URI otpauth:// для приложения-аутентификатора.
+// Неподтвержденный секрет заменяется, включенную аутентификацию нужно сначала отключить
+func (a *Authenticator) Setup(ctx context.Context, userId uint64, account string) (secret, uri string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	enrollment, ok, err := a.store.Get(ctx, userId)
+	if err != nil {
+		return "", "", err
+	}
+
+	if ok && enrollment.Confirmed {
+		return "", "", ErrAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: a.issuer, AccountName: account, Period: period})
+	if err != nil {
+		return "", "", err
+	}
+
+	err = a.save(ctx, userId, Enrollment{Secret: key.Secret(), CreatedAt: a.now()})
+	if err != nil {
+		return "", "", err
+	}
+
+	return key.Secret(), key.URL(), nil
+}
+
+// Confirm - включает двухфакторную аутентификацию, если код соответствует неподтвержденному секрету
+func (a *Authenticator) Confirm(ctx context.Context, userId uint64, code string) error {
+	return a.check(ctx, userId, code, false)
+}
+
+// Verify - проверяет код для включенной двухфакторной аутентификации
+func (a *Authenticator) Verify(ctx context.Context, userId uint64, code string) error {
+	return a.check(ctx, userId, code, true)
+}
+
+// Disable - отключает двухфакторную аутентификацию, требуется действующий код
+func (a *Authenticator) Disable(ctx context.Context, userId uint64, code string) error {
+	if err := a.Verify(ctx, userId, code); err != nil {
+		return err
+	}
+
+	return a.store.Delete(ctx, userId)
+}
+
+// check - проверяет код и запоминает его интервал. confirmed - ожидаемое состояние настройки
+func (a *Authenticator) check(ctx context.Context, userId uint64, code string, confirmed bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	enrollment, ok, err := a.load(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrNotEnrolled
+	}
+
+	if enrollment.Confirmed != confirmed {
+		if confirmed {
+			return ErrNotEnrolled
+		}
+		return ErrAlreadyEnabled
+	}
+
+	step, err := a.match(enrollment, code)
+	if err != nil {
+		return err
+	}
+
+	enrollment.Confirmed = true
+	enrollment.LastStep = step
+
+	return a.save(ctx, userId, enrollment)
+}
+
+// Reencrypt - перешифровывает активным ключом секреты, зашифрованные предыдущими ключами или сохраненные
+// в открытом виде. Возвращает количество перешифрованных секретов
+func (a *Authenticator) Reencrypt(ctx context.Context) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	enrollments, err := a.store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for userId := range enrollments {
+		if enrollments[userId].Ciphertext != "" && enrollments[userId].KeyVersion == a.cipher.Active() {
+			continue
+		}
+
+		enrollment, _, err := a.load(ctx, userId)
+		if err != nil {
+			return count, fmt.Errorf("пользователь %d: %w", userId, err)
+		}
+
+		if err = a.save(ctx, userId, enrollment); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// load - возвращает настройку пользователя с расшифрованным секретом
+func (a *Authenticator) load(ctx context.Context, userId uint64) (Enrollment, bool, error) {
+	enrollment, ok, err := a.store.Get(ctx, userId)
+	if err != nil || !ok || enrollment.Ciphertext == "" {
+		return enrollment, ok, err
+	}
+
+	enrollment.Secret, err = a.cipher.Open(secretAAD(userId), enrollment.KeyVersion, enrollment.Ciphertext)
+	if err != nil {
+		return Enrollment{}, false, err
+	}
+
+	return enrollment, true, nil
+}
+
+// save - шифрует секрет активным ключом и сохраняет настройку пользователя без открытого секрета
+func (a *Authenticator) save(ctx context.Context, userId uint64, enrollment Enrollment) error {
+	var err error
+	enrollment.KeyVersion, enrollment.Ciphertext, err = a.cipher.Seal(secretAAD(userId), enrollment.Secret)
+	if err != nil {
+		return err
+	}
+	enrollment.Secret = ""
+
+	return a.store.Put(ctx, userId, enrollment)
+}
+
+// secretAAD - дополнительные данные шифрования секрета, привязывают шифротекст к пользователю
+func secretAAD(userId uint64) string {
+	return fmt.Sprintf("mfa:%d", userId)
+}
+
+// match - ищет интервал, которому соответствует код, с учетом расхождения часов. Интервалы не позже
+// последнего принятого кода не проверяются
+func (a *Authenticator) match(enrollment Enrollment, code string) (int64, error) {
+	current := a.now().Unix() / period
+
+	for step := current - skew; step <= current+skew; step++ {
+		if step <= enrollment.LastStep {
+			continue
+		}
+
+		expected, err := totp.GenerateCodeCustom(enrollment.Secret, time.Unix(step*period, 0), totp.ValidateOpts{
+			Period:    period,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+
+	return 0, ErrInvalidCode
+}
@@ -0,0 +1,145 @@
+package mfa
+
+import (
+	"apiGateway/pkg/vault"
+	"context"
+	"errors"
+	"github.com/pquerna/otp/totp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestKeys(t *testing.T) *vault.Keys {
+	t.Helper()
+
+	keys, err := vault.NewEphemeralKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keys
+}
+
+func TestAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	path := filepath.Join(t.TempDir(), "mfa.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := newTestKeys(t)
+	auth := NewAuthenticator("apiGateway", store, keys)
+	auth.now = func() time.Time { return now }
+
+	secret, uri, err := auth.Setup(ctx, 1, "+79991234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri == "" {
+		t.Error("Setup() вернул пустой URI")
+	}
+
+	code := func() string {
+		c, err := totp.GenerateCode(secret, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if err = auth.Verify(ctx, 1, code()); !errors.Is(err, ErrNotEnrolled) {
+		t.Errorf("Verify() до подтверждения: err = %v, want ErrNotEnrolled", err)
+	}
+
+	if err = auth.Confirm(ctx, 1, code()); err != nil {
+		t.Fatalf("Confirm(): %v", err)
+	}
+
+	// Код, уже принятый при подтверждении, повторно не принимается
+	if err = auth.Verify(ctx, 1, code()); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("повторный код: err = %v, want ErrInvalidCode", err)
+	}
+
+	now = now.Add(30 * time.Second)
+	if err = auth.Verify(ctx, 1, code()); err != nil {
+		t.Errorf("Verify() следующего интервала: %v", err)
+	}
+
+	if _, _, err = auth.Setup(ctx, 1, "+79991234567"); !errors.Is(err, ErrAlreadyEnabled) {
+		t.Errorf("Setup() при включенной 2FA: err = %v, want ErrAlreadyEnabled", err)
+	}
+
+	// Секрет не хранится в файле в открытом виде
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Error("секрет TOTP сохранен в файле в открытом виде")
+	}
+
+	// Настройки сохраняются в файле
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enabled, _ := NewAuthenticator("apiGateway", reloaded, keys).Enabled(ctx, 1); !enabled {
+		t.Error("после перезагрузки хранилища 2FA не включена")
+	}
+
+	now = now.Add(30 * time.Second)
+	if err = auth.Disable(ctx, 1, "000000"); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Disable() с неверным кодом: err = %v, want ErrInvalidCode", err)
+	}
+	if err = auth.Disable(ctx, 1, code()); err != nil {
+		t.Errorf("Disable(): %v", err)
+	}
+	if enabled, _ := auth.Enabled(ctx, 1); enabled {
+		t.Error("2FA включена после Disable()")
+	}
+}
+
+func TestReencryptPlaintextSecret(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Запись, сохраненная до шифрования секретов
+	store := NewMemoryStore()
+	if err := store.Put(ctx, 1, Enrollment{Secret: "JBSWY3DPEHPK3PXP", Confirmed: true, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := NewAuthenticator("apiGateway", store, newTestKeys(t))
+	auth.now = func() time.Time { return now }
+
+	count, err := auth.Reencrypt(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("Reencrypt() = %d, want 1", count)
+	}
+
+	enrollment, _, _ := store.Get(ctx, 1)
+	if enrollment.Secret != "" || enrollment.Ciphertext == "" {
+		t.Errorf("секрет не зашифрован: %+v", enrollment)
+	}
+
+	code, err := totp.GenerateCode("JBSWY3DPEHPK3PXP", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = auth.Verify(ctx, 1, code); err != nil {
+		t.Errorf("Verify() после перешифрования: %v", err)
+	}
+
+	if count, err = auth.Reencrypt(ctx); err != nil || count != 0 {
+		t.Errorf("повторный Reencrypt() = %d, %v, want 0", count, err)
+	}
+}
@@ -0,0 +1,192 @@
+package money
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrFormat   = errors.New("сумма должна быть десятичным числом, знаков после точки не больше, чем в валюте")
+	ErrOverflow = errors.New("сумма слишком большая")
+)
+
+// Amount - сумма в минимальных единицах валюты шлюза (для RUB - копейках). В JSON записывается строкой с числом знаков
+// после точки валюты шлюза ("100.50"), читается из строки или числа без потери точности
+type Amount int64
+
+// defaultExponent - число знаков после точки у большинства валют ISO 4217
+const defaultExponent = 2
+
+// exponents - валюты ISO 4217, число знаков после точки которых отличается от defaultExponent
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0,
+	"UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// exponent - число знаков после точки валюты шлюза, задается SetCurrency
+var exponent = defaultExponent
+
+// Exponent - число знаков после точки валюты currency (ISO 4217)
+func Exponent(currency string) int {
+	if e, ok := exponents[strings.ToUpper(currency)]; ok {
+		return e
+	}
+
+	return defaultExponent
+}
+
+// SetCurrency - задает валюту шлюза, в минимальных единицах которой считаются суммы Amount. Вызывается при запуске
+// до обработки запросов
+func SetCurrency(currency string) {
+	exponent = Exponent(currency)
+}
+
+// scale - количество минимальных единиц в единице валюты с e знаками после точки
+func scale(e int) int64 {
+	s := int64(1)
+	for i := 0; i < e; i++ {
+		s *= 10
+	}
+
+	return s
+}
+
+// Parse - разбирает десятичную сумму в валюте шлюза, см. ParseIn
+func Parse(str string) (Amount, error) {
+	return ParseIn(str, exponent)
+}
+
+// ParseIn - разбирает десятичную сумму вида 100, 100.5 или -100.50 в валюте с e знаками после точки. Экспонента,
+// знак "+", пробелы и больше e знаков после точки не допускаются
+func ParseIn(str string, e int) (Amount, error) {
+	negative := strings.HasPrefix(str, "-")
+	units, fraction, hasFraction := strings.Cut(strings.TrimPrefix(str, "-"), ".")
+
+	if !digits(units) || (hasFraction && (!digits(fraction) || len(fraction) > e)) {
+		return 0, fmt.Errorf("%w: %q", ErrFormat, str)
+	}
+
+	whole, err := strconv.ParseInt(units, 10, 64)
+	if err != nil || whole > math.MaxInt64/scale(e) {
+		return 0, fmt.Errorf("%w: %q", ErrOverflow, str)
+	}
+
+	amount := whole * scale(e)
+	if hasFraction {
+		minor, _ := strconv.ParseInt(fraction, 10, 64)
+		minor *= scale(e - len(fraction))
+		if amount > math.MaxInt64-minor {
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, str)
+		}
+		amount += minor
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return Amount(amount), nil
+}
+
+// digits - непустая строка из цифр 0-9
+func digits(str string) bool {
+	if str == "" {
+		return false
+	}
+
+	for _, c := range str {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String - сумма с числом знаков после точки валюты шлюза
+func (a Amount) String() string {
+	return a.Format(exponent)
+}
+
+// Format - сумма с e знаками после точки
+func (a Amount) Format(e int) string {
+	sign := ""
+	value := uint64(a)
+	if a < 0 {
+		sign = "-"
+		value = uint64(-a)
+	}
+
+	if e == 0 {
+		return fmt.Sprintf("%s%d", sign, value)
+	}
+
+	s := uint64(scale(e))
+	return fmt.Sprintf("%s%d.%0*d", sign, value/s, e, value%s)
+}
+
+// MarshalJSON - сумма строкой, чтобы клиенты не читали ее в число с плавающей точкой
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON - сумма из строки "100.50" или числа 100.50, null оставляет сумму без изменений
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	str := string(data)
+	if strings.HasPrefix(str, `"`) {
+		var err error
+		if str, err = strconv.Unquote(str); err != nil {
+			return fmt.Errorf("%w: %s", ErrFormat, data)
+		}
+	}
+
+	amount, err := Parse(str)
+	if err != nil {
+		return err
+	}
+	*a = amount
+
+	return nil
+}
+
+// FromFloat32 - сумма из поля float32 DatabaseService, округляется до минимальных единиц валюты шлюза
+func FromFloat32(value float32) Amount {
+	return Amount(math.Round(float64(value) * float64(scale(exponent))))
+}
+
+// Float32 - сумма для поля float32 DatabaseService
+func (a Amount) Float32() float32 {
+	return float32(float64(a) / float64(scale(exponent)))
+}
+
+// Exact32 - сумма передается в DatabaseService без потери минимальных единиц. float32 хранит 24 значащих бита,
+// поэтому для RUB копейки сохраняются примерно до 130 000, большие суммы DatabaseService округляет
+func (a Amount) Exact32() bool {
+	return FromFloat32(a.Float32()) == a
+}
+
+// Money - сумма в валюте ISO 4217
+type Money struct {
+	Amount   Amount `json:"amount" swaggertype:"string" example:"100.50"`
+	Currency string `json:"currency" example:"RUB"`
+}
+
+// New - сумма amount в валюте currency
+func New(amount Amount, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// String - сумма с кодом валюты, например "100.50 RUB"
+func (m Money) String() string {
+	return m.Amount.Format(Exponent(m.Currency)) + " " + m.Currency
+}
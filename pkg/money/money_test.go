@@ -0,0 +1,128 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    Amount
+		wantErr error
+	}{
+		{str: "100", want: 10000},
+		{str: "100.5", want: 10050},
+		{str: "100.05", want: 10005},
+		{str: "0.01", want: 1},
+		{str: "-12.30", want: -1230},
+		{str: "100.505", wantErr: ErrFormat},
+		{str: "1e2", wantErr: ErrFormat},
+		{str: "+1", wantErr: ErrFormat},
+		{str: ".5", wantErr: ErrFormat},
+		{str: "5.", wantErr: ErrFormat},
+		{str: " 5", wantErr: ErrFormat},
+		{str: "", wantErr: ErrFormat},
+		{str: "92233720368547758.08", wantErr: ErrOverflow},
+		{str: "100000000000000000000", wantErr: ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			got, err := Parse(tt.str)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	var request struct {
+		A Amount `json:"a"`
+		B Amount `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":0.1,"b":"0.2"}`), &request); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := request.A + request.B
+	if sum != 30 {
+		t.Errorf("0.1 + 0.2 = %d копеек, want 30", sum)
+	}
+
+	data, err := json.Marshal(New(sum, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"amount":"0.30","currency":"RUB"}` {
+		t.Errorf("json.Marshal() = %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"a":0.125}`), &request); !errors.Is(err, ErrFormat) {
+		t.Errorf("сумма с тремя знаками после точки: error = %v, want %v", err, ErrFormat)
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	if got := FromFloat32(Amount(30).Float32()); got != 30 {
+		t.Errorf("FromFloat32(0.3) = %d, want 30", got)
+	}
+
+	if !Amount(12_999_999).Exact32() {
+		t.Error("129 999.99 должно передаваться без потери копеек")
+	}
+	if Amount(26_000_001).Exact32() {
+		t.Error("260 000.01 не помещается в float32")
+	}
+	if Amount(1_000_000_001).Exact32() {
+		t.Error("10 000 000.01 не помещается в float32")
+	}
+}
+
+func TestCurrencyExponent(t *testing.T) {
+	tests := []struct {
+		currency string
+		str      string
+		want     Amount
+		wantErr  error
+	}{
+		{currency: "RUB", str: "100.05", want: 10005},
+		{currency: "JPY", str: "100", want: 100},
+		{currency: "JPY", str: "100.5", wantErr: ErrFormat},
+		{currency: "KWD", str: "1.5", want: 1500},
+		{currency: "KWD", str: "1.005", want: 1005},
+		{currency: "kwd", str: "1.0005", wantErr: ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency+" "+tt.str, func(t *testing.T) {
+			got, err := ParseIn(tt.str, Exponent(tt.currency))
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("ParseIn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseIn() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if got := New(1005, "KWD").String(); got != "1.005 KWD" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := New(100, "JPY").String(); got != "100 JPY" {
+		t.Errorf("String() = %q", got)
+	}
+
+	// Суммы Amount считаются в валюте шлюза
+	SetCurrency("JPY")
+	defer SetCurrency("RUB")
+	if got, err := Parse("100"); err != nil || got != 100 || got.String() != "100" {
+		t.Errorf("Parse() в JPY = %d (%s), %v", got, got, err)
+	}
+	if got := FromFloat32(1500); got != 1500 {
+		t.Errorf("FromFloat32() в JPY = %d, want 1500", got)
+	}
+}
@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake - запоминает отправленные сообщения вместо доставки, используется в тестах
+type Fake struct {
+	mu       sync.Mutex
+	messages []Message
+	Err      error // Ошибка, которую возвращает Send
+}
+
+// Send - запоминает сообщение
+func (f *Fake) Send(_ context.Context, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+
+	f.messages = append(f.messages, msg)
+
+	return nil
+}
+
+// Messages - отправленные сообщения в порядке отправки
+func (f *Fake) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Message(nil), f.messages...)
+}
+
+// Last - последнее сообщение для получателя to
+func (f *Fake) Last(to string) (Message, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := len(f.messages) - 1; i >= 0; i-- {
+		if f.messages[i].To == to {
+			return f.messages[i], true
+		}
+	}
+
+	return Message{}, false
+}
@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"apiGateway/pkg/config"
+	"apiGateway/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Channel - канал доставки сообщения
+type Channel string
+
+const (
+	ChannelSMS   Channel = "sms"   // SMS на номер телефона
+	ChannelEmail Channel = "email" // Письмо на email
+)
+
+// Message - сообщение пользователю
+type Message struct {
+	Channel Channel `json:"channel"`
+	To      string  `json:"to"`
+	Subject string  `json:"subject,omitempty"`
+	Text    string  `json:"text"`
+}
+
+// Notifier - доставка сообщений пользователю (одноразовые коды, уведомления)
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New - создает Notifier по конфигурации: log - вывод в лог, file - запись в файл (для локальной разработки)
+func New(cfg config.Notifier) (Notifier, error) {
+	switch cfg.Type {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "file":
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("для notifier типа file не указан path")
+		}
+		return NewFileNotifier(cfg.Path), nil
+	default:
+		return nil, fmt.Errorf("неизвестный тип notifier %q", cfg.Type)
+	}
+}
+
+// LogNotifier - выводит сообщения в лог вместо отправки
+type LogNotifier struct{}
+
+// Send - выводит сообщение в лог
+func (LogNotifier) Send(_ context.Context, msg Message) error {
+	logger.Info("Сообщение [%s] для %s: %s", msg.Channel, msg.To, msg.Text)
+	return nil
+}
+
+// FileNotifier - дописывает сообщения в файл построчно в формате JSON
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileNotifier - создает FileNotifier, файл создается при первой отправке
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Send - дописывает сообщение в файл
+func (n *FileNotifier) Send(_ context.Context, msg Message) error {
+	line, err := json.Marshal(struct {
+		Message
+		SentAt time.Time `json:"sentAt"`
+	}{Message: msg, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+
+	return err
+}
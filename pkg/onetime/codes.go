@@ -0,0 +1,132 @@
+package onetime
+
+import (
+	"apiGateway/pkg/config"
+	"apiGateway/pkg/utilities"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Ошибки проверки одноразовых кодов
+var (
+	ErrCodeInvalid      = errors.New("неверный или истекший код")
+	ErrTooManyAttempts  = errors.New("превышено количество попыток ввода кода")
+	ErrTooSoon          = errors.New("код уже отправлен, повторная отправка пока недоступна")
+	errCodeLengthLimits = errors.New("длина кода должна быть от 4 до 10 цифр")
+)
+
+// Codes - выпуск и проверка коротких одноразовых цифровых кодов. Код привязан к назначению (purpose)
+// и субъекту (телефон, email, ID пользователя), выпуск нового кода отменяет предыдущий
+type Codes struct {
+	policy config.OneTimeCodes
+	store  Store
+	now    func() time.Time
+}
+
+// NewCodes - создает Codes с политикой policy
+func NewCodes(policy config.OneTimeCodes, store Store) (*Codes, error) {
+	if policy.Length < 4 || policy.Length > 10 {
+		return nil, errCodeLengthLimits
+	}
+
+	return &Codes{policy: policy, store: store, now: time.Now}, nil
+}
+
+// TTL - срок действия выпускаемых кодов
+func (c *Codes) TTL() time.Duration {
+	return c.policy.TTL
+}
+
+// Issue - выпускает новый код. Если предыдущий код выпущен менее ResendInterval назад, возвращается ErrTooSoon
+// и время, через которое можно запросить новый код
+func (c *Codes) Issue(ctx context.Context, purpose, subject string) (string, time.Duration, error) {
+	key := c.key(purpose, subject)
+	now := c.now()
+
+	previous, ok, err := c.store.Get(ctx, key, now)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if ok {
+		if wait := previous.IssuedAt.Add(c.policy.ResendInterval).Sub(now); wait > 0 {
+			return "", wait, ErrTooSoon
+		}
+	}
+
+	code, err := c.generate()
+	if err != nil {
+		return "", 0, err
+	}
+
+	err = c.store.Put(ctx, key, Entry{
+		Hash:     c.hash(key, code),
+		IssuedAt: now,
+		Expires:  now.Add(c.policy.TTL),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	return code, 0, nil
+}
+
+// Verify - проверяет код, при успешной проверке код удаляется. После MaxAttempts неудачных попыток
+// код аннулируется и возвращается ErrTooManyAttempts
+func (c *Codes) Verify(ctx context.Context, purpose, subject, code string) error {
+	key := c.key(purpose, subject)
+
+	entry, ok, err := c.store.Get(ctx, key, c.now())
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrCodeInvalid
+	}
+
+	attempts, err := c.store.Attempt(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if attempts > c.policy.MaxAttempts {
+		if err = c.store.Delete(ctx, key); err != nil {
+			return err
+		}
+		return ErrTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(entry.Hash), []byte(c.hash(key, code))) != 1 {
+		return ErrCodeInvalid
+	}
+
+	return c.store.Delete(ctx, key)
+}
+
+// key - ключ кода в хранилище
+func (c *Codes) key(purpose, subject string) string {
+	return fmt.Sprintf("%s:%s", purpose, subject)
+}
+
+// hash - хэш кода, привязанный к ключу
+func (c *Codes) hash(key, code string) string {
+	return utilities.SHA256(fmt.Sprintf("%s:%s", key, code))
+}
+
+// generate - криптостойкий цифровой код длиной Length
+func (c *Codes) generate() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.policy.Length)), nil)
+
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", c.policy.Length, n), nil
+}
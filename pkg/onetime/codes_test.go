@@ -0,0 +1,88 @@
+package onetime
+
+import (
+	"apiGateway/pkg/config"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestCodes(t *testing.T, now *time.Time) *Codes {
+	t.Helper()
+
+	codes, err := NewCodes(config.OneTimeCodes{
+		Length:         6,
+		TTL:            10 * time.Minute,
+		MaxAttempts:    3,
+		ResendInterval: time.Minute,
+	}, NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes.now = func() time.Time { return *now }
+
+	return codes
+}
+
+func TestCodes(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	codes := newTestCodes(t, &now)
+
+	code, _, err := codes.Issue(ctx, "password_reset", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 6 {
+		t.Errorf("длина кода = %d, want 6", len(code))
+	}
+
+	if _, wait, err := codes.Issue(ctx, "password_reset", "1"); !errors.Is(err, ErrTooSoon) || wait != time.Minute {
+		t.Errorf("повторный выпуск: wait = %s, err = %v, want 1m, ErrTooSoon", wait, err)
+	}
+
+	if err = codes.Verify(ctx, "verify_phone", "1", code); !errors.Is(err, ErrCodeInvalid) {
+		t.Errorf("код другого назначения: err = %v, want ErrCodeInvalid", err)
+	}
+
+	if err = codes.Verify(ctx, "password_reset", "1", code); err != nil {
+		t.Errorf("верный код: err = %v", err)
+	}
+
+	if err = codes.Verify(ctx, "password_reset", "1", code); !errors.Is(err, ErrCodeInvalid) {
+		t.Errorf("повторное использование кода: err = %v, want ErrCodeInvalid", err)
+	}
+}
+
+func TestCodesExpiredAndAttempts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	codes := newTestCodes(t, &now)
+
+	code, _, err := codes.Issue(ctx, "password_reset", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(11 * time.Minute)
+	if err = codes.Verify(ctx, "password_reset", "1", code); !errors.Is(err, ErrCodeInvalid) {
+		t.Errorf("истекший код: err = %v, want ErrCodeInvalid", err)
+	}
+
+	code, _, err = codes.Issue(ctx, "password_reset", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err = codes.Verify(ctx, "password_reset", "1", "wrong"); !errors.Is(err, ErrCodeInvalid) {
+			t.Errorf("попытка %d: err = %v, want ErrCodeInvalid", i+1, err)
+		}
+	}
+
+	// После MaxAttempts неудачных попыток код аннулируется, даже верный код не принимается
+	if err = codes.Verify(ctx, "password_reset", "1", code); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("после исчерпания попыток: err = %v, want ErrTooManyAttempts", err)
+	}
+}
@@ -0,0 +1,94 @@
+package onetime
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Entry - выпущенный одноразовый код, сам код не хранится, только его хэш
+type Entry struct {
+	Hash     string    // Хэш кода
+	IssuedAt time.Time // Время выпуска, используется для ограничения повторной отправки
+	Expires  time.Time // Срок действия кода
+	Attempts int       // Количество попыток ввода кода
+}
+
+// Store - хранилище одноразовых кодов. MemoryStore подходит для одного экземпляра шлюза,
+// при нескольких экземплярах нужна общая реализация
+type Store interface {
+	// Put - сохраняет код по ключу, заменяя ранее выпущенный
+	Put(ctx context.Context, key string, entry Entry) error
+	// Get - возвращает код по ключу, ok = false для неизвестного или истекшего кода
+	Get(ctx context.Context, key string, now time.Time) (entry Entry, ok bool, err error)
+	// Attempt - атомарно увеличивает количество попыток ввода кода и возвращает новое значение
+	Attempt(ctx context.Context, key string) (int, error)
+	// Delete - удаляет код по ключу
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryStore - хранилище одноразовых кодов в памяти процесса
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore - создает хранилище одноразовых кодов в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+// Put - сохраняет код по ключу, заодно удаляет истекшие коды
+func (s *MemoryStore) Put(_ context.Context, key string, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, e := range s.entries {
+		if !entry.IssuedAt.Before(e.Expires) {
+			delete(s.entries, k)
+		}
+	}
+
+	s.entries[key] = entry
+
+	return nil
+}
+
+// Get - возвращает код по ключу
+func (s *MemoryStore) Get(_ context.Context, key string, now time.Time) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.Expires) {
+		return Entry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+// Attempt - увеличивает количество попыток ввода кода
+func (s *MemoryStore) Attempt(_ context.Context, key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return 0, nil
+	}
+
+	entry.Attempts++
+	s.entries[key] = entry
+
+	return entry.Attempts, nil
+}
+
+// Delete - удаляет код по ключу
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
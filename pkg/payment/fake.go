@@ -0,0 +1,186 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Номера карт, на которые Fake отвечает отказом или асинхронным подтверждением. Остальные карты одобряются
+const (
+	CardDeclined          = "4000000000000002" // Отказ банка
+	CardInsufficientFunds = "4000000000009995" // Недостаточно средств
+	CardTimeout           = "4000000000000119" // Провайдер не отвечает, транзакция не создается
+	CardPending           = "4000000000003220" // Транзакция ожидает подтверждения, результат задается Settle
+)
+
+// Fake - детерминированный платежный провайдер в памяти процесса для локальной разработки и тестов.
+// Деньги не списываются, ID транзакций выдаются последовательно: fake_1, fake_2, ...
+type Fake struct {
+	mu           sync.Mutex
+	transactions map[string]Transaction
+	last         int
+	lastEvent    int
+	now          func() time.Time
+}
+
+// NewFake - создает Fake
+func NewFake() *Fake {
+	return &Fake{transactions: make(map[string]Transaction), now: time.Now}
+}
+
+// Name - имя провайдера
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// Authorize - блокирует сумму, результат определяется номером карты
+func (f *Fake) Authorize(ctx context.Context, request AuthorizeRequest) (Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return Transaction{}, ErrTimeout
+	}
+
+	if request.Amount <= 0 {
+		return Transaction{}, ErrInvalidAmount
+	}
+
+	switch request.Card.Number {
+	case "", CardDeclined:
+		return Transaction{}, ErrDeclined
+	case CardInsufficientFunds:
+		return Transaction{}, ErrInsufficientFunds
+	case CardTimeout:
+		return Transaction{}, ErrTimeout
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.last++
+	transaction := Transaction{
+		Id:        fmt.Sprintf("fake_%d", f.last),
+		Status:    StatusAuthorized,
+		Amount:    request.Amount,
+		Currency:  request.Currency,
+		CreatedAt: f.now().UTC(),
+	}
+	if request.Card.Number == CardPending {
+		transaction.Status = StatusPending
+	}
+	f.transactions[transaction.Id] = transaction
+
+	return transaction, nil
+}
+
+// Settle - завершает транзакцию в состоянии StatusPending: succeeded = true - средства списаны полностью,
+// иначе платеж отклонен. Возвращает уведомление, которое реальный провайдер отправил бы на webhook
+func (f *Fake) Settle(transactionId string, succeeded bool) (Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[transactionId]
+	if !ok {
+		return Event{}, ErrNotFound
+	}
+	if transaction.Status != StatusPending {
+		return Event{}, ErrInvalidState
+	}
+
+	f.lastEvent++
+	event := Event{Id: fmt.Sprintf("evt_%d", f.lastEvent), TransactionId: transactionId}
+
+	if succeeded {
+		transaction.Status = StatusCaptured
+		transaction.Captured = transaction.Amount
+		event.Type = EventSucceeded
+	} else {
+		transaction.Status = StatusFailed
+		event.Type = EventFailed
+		event.Reason = ErrDeclined.Error()
+	}
+	f.transactions[transactionId] = transaction
+
+	return event, nil
+}
+
+// Capture - списывает заблокированную сумму
+func (f *Fake) Capture(_ context.Context, transactionId string, amount int64) (Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[transactionId]
+	if !ok {
+		return Transaction{}, ErrNotFound
+	}
+
+	if transaction.Status == StatusCaptured && (amount == 0 || amount == transaction.Captured) {
+		return transaction, nil
+	}
+	if transaction.Status != StatusAuthorized {
+		return Transaction{}, ErrInvalidState
+	}
+
+	if amount == 0 {
+		amount = transaction.Amount
+	}
+	if amount < 0 || amount > transaction.Amount {
+		return Transaction{}, ErrInvalidAmount
+	}
+
+	transaction.Status = StatusCaptured
+	transaction.Captured = amount
+	f.transactions[transactionId] = transaction
+
+	return transaction, nil
+}
+
+// Refund - возвращает списанную сумму или снимает блокировку
+func (f *Fake) Refund(_ context.Context, transactionId string, amount int64) (Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[transactionId]
+	if !ok {
+		return Transaction{}, ErrNotFound
+	}
+
+	switch transaction.Status {
+	case StatusAuthorized, StatusPending:
+		if amount != 0 && amount != transaction.Amount {
+			return Transaction{}, ErrInvalidAmount
+		}
+		transaction.Status = StatusRefunded
+	case StatusCaptured:
+		available := transaction.Captured - transaction.Refunded
+		if amount == 0 {
+			amount = available
+		}
+		if amount <= 0 || amount > available {
+			return Transaction{}, ErrInvalidAmount
+		}
+		transaction.Refunded += amount
+		if transaction.Refunded == transaction.Captured {
+			transaction.Status = StatusRefunded
+		}
+	default:
+		return Transaction{}, ErrInvalidState
+	}
+
+	f.transactions[transactionId] = transaction
+
+	return transaction, nil
+}
+
+// Status - текущее состояние транзакции
+func (f *Fake) Status(_ context.Context, transactionId string) (Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[transactionId]
+	if !ok {
+		return Transaction{}, ErrNotFound
+	}
+
+	return transaction, nil
+}
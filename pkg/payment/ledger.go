@@ -0,0 +1,272 @@
+package payment
+
+import (
+	"apiGateway/pkg/filestore"
+	"apiGateway/pkg/money"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrPaymentNotFound = errors.New("платеж не найден")
+	ErrRefundNotFound  = errors.New("возврат не найден")
+)
+
+// PaymentStatus - состояние платежа в шлюзе
+type PaymentStatus string
+
+const (
+	PaymentPending    PaymentStatus = "pending"    // Платеж выполняется или ожидает подтверждения провайдера
+	PaymentProcessing PaymentStatus = "processing" // Провайдер подтвердил списание, записывается пожертвование
+	PaymentSucceeded  PaymentStatus = "succeeded"  // Пожертвование записано
+	PaymentFailed     PaymentStatus = "failed"     // Платеж отклонен или отменен, деньги не списаны или возвращены
+	PaymentRefunded   PaymentStatus = "refunded"   // Сумма платежа полностью возвращена
+)
+
+// RefundStatus - состояние возврата
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending"   // Возврат выполняется
+	RefundSucceeded RefundStatus = "succeeded" // Деньги возвращены на карту
+	RefundFailed    RefundStatus = "failed"    // Возврат отменен, деньги не возвращены
+)
+
+// transitions - допустимые переходы между состояниями платежа
+var transitions = map[PaymentStatus][]PaymentStatus{
+	PaymentPending:    {PaymentProcessing, PaymentSucceeded, PaymentFailed},
+	PaymentProcessing: {PaymentSucceeded, PaymentFailed},
+	PaymentSucceeded:  {PaymentRefunded},
+}
+
+// CanTransition - переход платежа из состояния from в состояние to допустим
+func CanTransition(from, to PaymentStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Payment - платеж в реестре шлюза
+type Payment struct {
+	Id            string        `json:"id"`
+	Status        PaymentStatus `json:"status"`
+	Provider      string        `json:"provider"`
+	TransactionId string        `json:"transactionId,omitempty"`
+	UserId        uint64        `json:"userId"`
+	WardId        uint64        `json:"wardId"`
+	CardId        uint64        `json:"cardId"`
+	Title         string        `json:"title"`
+	money.Money                 // Сумма и валюта платежа
+	DonationId    uint64        `json:"donationId,omitempty"` // Пожертвование, записанное после успешного платежа
+	FailureReason string        `json:"failureReason,omitempty"`
+	Refunded      money.Amount  `json:"refunded,omitempty"` // Сумма выполненных возвратов
+	Refunds       []Refund      `json:"refunds,omitempty"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	UpdatedAt     time.Time     `json:"updatedAt"`
+}
+
+// Refund - полный или частичный возврат платежа
+type Refund struct {
+	Id            string       `json:"id"`
+	Amount        money.Amount `json:"amount"`
+	Status        RefundStatus `json:"status"`
+	Reason        string       `json:"reason,omitempty"`
+	CreatedBy     uint64       `json:"createdBy"` // Пользователь, запросивший возврат
+	FailureReason string       `json:"failureReason,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt"`
+}
+
+// Refund - возврат платежа по ID
+func (p *Payment) Refund(id string) (*Refund, error) {
+	for i := range p.Refunds {
+		if p.Refunds[i].Id == id {
+			return &p.Refunds[i], nil
+		}
+	}
+
+	return nil, ErrRefundNotFound
+}
+
+// Transition - переводит платеж в состояние to, ошибка, если переход недопустим
+func (p *Payment) Transition(to PaymentStatus) error {
+	if !CanTransition(p.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidState, p.Status, to)
+	}
+	p.Status = to
+
+	return nil
+}
+
+// NewId - случайный ID платежа или возврата
+func NewId() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// Ledger - реестр платежей шлюза
+type Ledger interface {
+	// Create - сохраняет новый платеж
+	Create(ctx context.Context, payment Payment) error
+	// Get - возвращает платеж по ID
+	Get(ctx context.Context, id string) (Payment, error)
+	// FindByTransaction - возвращает платеж по ID транзакции провайдера
+	FindByTransaction(ctx context.Context, provider, transactionId string) (Payment, error)
+	// FindByDonation - возвращает платеж, которым оплачено пожертвование donationId
+	FindByDonation(ctx context.Context, donationId uint64) (Payment, error)
+	// Update - атомарно изменяет платеж функцией change, при ошибке change платеж не меняется
+	Update(ctx context.Context, id string, change func(payment *Payment) error) (Payment, error)
+}
+
+// MemoryLedger - реестр платежей в памяти процесса, теряется при перезапуске (только для разработки и тестов)
+type MemoryLedger struct {
+	mu        sync.Mutex
+	payments  map[string]Payment
+	donations map[uint64]string // ID пожертвования -> ID платежа
+	now       func() time.Time
+	flush     func(payments map[string]Payment) error
+}
+
+// NewMemoryLedger - создает реестр платежей в памяти процесса
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{payments: make(map[string]Payment), donations: make(map[uint64]string), now: time.Now}
+}
+
+// NewFileLedger - создает реестр платежей в JSON файле path, файл перезаписывается целиком при каждом изменении
+func NewFileLedger(path string) (*MemoryLedger, error) {
+	ledger := NewMemoryLedger()
+	ledger.flush = func(payments map[string]Payment) error {
+		return filestore.Save(path, payments)
+	}
+
+	if err := filestore.Load(path, &ledger.payments); err != nil {
+		return nil, err
+	}
+
+	for id, payment := range ledger.payments {
+		if payment.DonationId != 0 {
+			ledger.donations[payment.DonationId] = id
+		}
+	}
+
+	return ledger, nil
+}
+
+// Create - сохраняет новый платеж
+func (l *MemoryLedger) Create(_ context.Context, payment Payment) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.payments[payment.Id]; ok {
+		return fmt.Errorf("платеж %s уже существует", payment.Id)
+	}
+
+	now := l.now().UTC()
+	payment.CreatedAt = now
+	payment.UpdatedAt = now
+
+	return l.save(payment, Payment{}, false)
+}
+
+// Get - возвращает платеж по ID
+func (l *MemoryLedger) Get(_ context.Context, id string) (Payment, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	payment, ok := l.payments[id]
+	if !ok {
+		return Payment{}, ErrPaymentNotFound
+	}
+
+	return payment, nil
+}
+
+// FindByTransaction - возвращает платеж по ID транзакции провайдера
+func (l *MemoryLedger) FindByTransaction(_ context.Context, provider, transactionId string) (Payment, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, payment := range l.payments {
+		if payment.Provider == provider && payment.TransactionId == transactionId && transactionId != "" {
+			return payment, nil
+		}
+	}
+
+	return Payment{}, ErrPaymentNotFound
+}
+
+// FindByDonation - возвращает платеж, которым оплачено пожертвование
+func (l *MemoryLedger) FindByDonation(_ context.Context, donationId uint64) (Payment, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if donationId != 0 {
+		if id, ok := l.donations[donationId]; ok {
+			return l.payments[id], nil
+		}
+	}
+
+	return Payment{}, ErrPaymentNotFound
+}
+
+// Update - атомарно изменяет платеж
+func (l *MemoryLedger) Update(_ context.Context, id string, change func(payment *Payment) error) (Payment, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	previous, ok := l.payments[id]
+	if !ok {
+		return Payment{}, ErrPaymentNotFound
+	}
+
+	payment := previous
+	// Возвраты копируются, чтобы отклоненное изменение не затронуло сохраненный платеж
+	payment.Refunds = slices.Clone(previous.Refunds)
+	if err := change(&payment); err != nil {
+		return previous, err
+	}
+	payment.Id = id
+	payment.UpdatedAt = l.now().UTC()
+
+	if err := l.save(payment, previous, true); err != nil {
+		return previous, err
+	}
+
+	return payment, nil
+}
+
+// save - сохраняет платеж и файл реестра, при ошибке записи файла возвращает прежнее состояние.
+// Вызывается под блокировкой
+func (l *MemoryLedger) save(payment, previous Payment, existed bool) error {
+	l.payments[payment.Id] = payment
+
+	if l.flush != nil {
+		if err := l.flush(l.payments); err != nil {
+			if existed {
+				l.payments[payment.Id] = previous
+			} else {
+				delete(l.payments, payment.Id)
+			}
+			return err
+		}
+	}
+
+	if payment.DonationId != 0 {
+		l.donations[payment.DonationId] = payment.Id
+	}
+
+	return nil
+}
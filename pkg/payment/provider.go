@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"apiGateway/pkg/config"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrDeclined          = errors.New("платеж отклонен банком")
+	ErrInsufficientFunds = errors.New("недостаточно средств на карте")
+	ErrTimeout           = errors.New("платежная система не ответила")
+	ErrNotFound          = errors.New("транзакция не найдена")
+	ErrInvalidState      = errors.New("операция недоступна в текущем состоянии транзакции")
+	ErrInvalidAmount     = errors.New("неверная сумма операции")
+)
+
+// Status - состояние транзакции у платежного провайдера
+type Status string
+
+const (
+	StatusPending    Status = "pending"    // Ожидается подтверждение, результат придет уведомлением (webhook)
+	StatusAuthorized Status = "authorized" // Средства заблокированы на карте
+	StatusCaptured   Status = "captured"   // Средства списаны
+	StatusRefunded   Status = "refunded"   // Средства полностью возвращены или блокировка снята
+	StatusFailed     Status = "failed"     // Платеж отклонен после подтверждения
+)
+
+// Card - данные карты для оплаты, провайдеру передаются без сохранения
+type Card struct {
+	Number string
+	Holder string
+	Expiry string // Срок действия в формате MM/YY
+	Cvv    string
+}
+
+// AuthorizeRequest - блокировка средств на карте
+type AuthorizeRequest struct {
+	Card        Card
+	Amount      int64  // Сумма в минимальных единицах валюты (копейках)
+	Currency    string // Код валюты ISO 4217
+	Description string
+	Reference   string // Идентификатор платежа на стороне шлюза
+}
+
+// Transaction - транзакция у платежного провайдера
+type Transaction struct {
+	Id        string
+	Status    Status
+	Amount    int64 // Заблокированная сумма
+	Captured  int64 // Списанная сумма
+	Refunded  int64 // Возвращенная сумма
+	Currency  string
+	CreatedAt time.Time
+}
+
+// Provider - платежный провайдер (эквайер)
+type Provider interface {
+	// Name - имя провайдера, сохраняется вместе с ID транзакции
+	Name() string
+	// Authorize - блокирует сумму на карте. Транзакция в состоянии StatusPending подтверждается асинхронно:
+	// провайдер сам списывает средства и присылает уведомление EventSucceeded или EventFailed
+	Authorize(ctx context.Context, request AuthorizeRequest) (Transaction, error)
+	// Capture - списывает заблокированную сумму, amount = 0 - всю сумму
+	Capture(ctx context.Context, transactionId string, amount int64) (Transaction, error)
+	// Refund - возвращает списанную сумму или снимает блокировку, если средства еще не списаны, amount = 0 - всю сумму
+	Refund(ctx context.Context, transactionId string, amount int64) (Transaction, error)
+	// Status - текущее состояние транзакции
+	Status(ctx context.Context, transactionId string) (Transaction, error)
+}
+
+// New - создает платежного провайдера по конфигурации
+func New(cfg config.Payment) (Provider, error) {
+	switch cfg.Provider {
+	case "", "fake":
+		return NewFake(), nil
+	default:
+		return nil, fmt.Errorf("неизвестный платежный провайдер %q", cfg.Provider)
+	}
+}
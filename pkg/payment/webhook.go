@@ -0,0 +1,118 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader - заголовок уведомления с подписью в формате "t=<unix время>,v1=<hex HMAC-SHA256>"
+const SignatureHeader = "X-Payment-Signature"
+
+var (
+	ErrSignature = errors.New("неверная подпись уведомления")
+	ErrTimestamp = errors.New("время подписи уведомления вне допустимого интервала")
+	ErrEvent     = errors.New("неверный формат уведомления")
+)
+
+// EventType - тип уведомления провайдера
+type EventType string
+
+const (
+	EventSucceeded EventType = "payment.succeeded" // Средства списаны
+	EventFailed    EventType = "payment.failed"    // Платеж отклонен
+)
+
+// Event - уведомление провайдера об изменении состояния транзакции
+type Event struct {
+	Id            string    `json:"id"` // Уникальный ID уведомления, повторная доставка приходит с тем же ID
+	Type          EventType `json:"type"`
+	TransactionId string    `json:"transactionId"`
+	Reason        string    `json:"reason,omitempty"` // Причина отказа для payment.failed
+}
+
+// Sign - подпись уведомления body секретом secret на момент timestamp в формате заголовка SignatureHeader
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, signature(secret, unix, body))
+}
+
+// VerifySignature - проверяет подпись header тела body: подпись должна быть вычислена секретом secret,
+// а время подписи отличаться от now не больше чем на tolerance, иначе перехваченное уведомление можно
+// повторить позже
+func VerifySignature(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix string
+	var signatures []string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrSignature
+	}
+
+	expected := signature(secret, unix, body)
+	valid := false
+	// Провайдер при смене секрета может прислать несколько подписей v1
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrSignature
+	}
+
+	if diff := now.Sub(time.Unix(seconds, 0)); diff > tolerance || diff < -tolerance {
+		return ErrTimestamp
+	}
+
+	return nil
+}
+
+// ParseEvent - разбирает тело уведомления
+func ParseEvent(body []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return Event{}, fmt.Errorf("%w: %v", ErrEvent, err)
+	}
+
+	if event.Id == "" || event.TransactionId == "" {
+		return Event{}, fmt.Errorf("%w: не указан id или transactionId", ErrEvent)
+	}
+
+	switch event.Type {
+	case EventSucceeded, EventFailed:
+	default:
+		return Event{}, fmt.Errorf("%w: неизвестный тип %q", ErrEvent, event.Type)
+	}
+
+	return event, nil
+}
+
+// signature - hex HMAC-SHA256 строки "<unix>.<body>"
+func signature(secret, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
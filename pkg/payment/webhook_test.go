@@ -0,0 +1,39 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"id":"evt_1","type":"payment.succeeded","transactionId":"fake_1"}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign("secret", now, body)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		wantErr error
+	}{
+		{name: "Валидная подпись", secret: "secret", header: header, body: body, now: now},
+		{name: "Подпись при смене секрета", secret: "secret", header: header + ",v1=00", body: body, now: now},
+		{name: "Другой секрет", secret: "other", header: header, body: body, now: now, wantErr: ErrSignature},
+		{name: "Измененное тело", secret: "secret", header: header, body: []byte(`{}`), now: now,
+			wantErr: ErrSignature},
+		{name: "Без подписи", secret: "secret", header: "", body: body, now: now, wantErr: ErrSignature},
+		{name: "Устаревшая подпись", secret: "secret", header: header, body: body, now: now.Add(10 * time.Minute),
+			wantErr: ErrTimestamp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.header, tt.body, tt.now, 5*time.Minute)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("VerifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
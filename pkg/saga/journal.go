@@ -0,0 +1,128 @@
+package saga
+
+import (
+	"apiGateway/pkg/filestore"
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// State - состояние саги в журнале
+type State string
+
+const (
+	StateRunning      State = "running"      // Шаги выполняются
+	StateCompensating State = "compensating" // Шаг завершился ошибкой, выполненные шаги откатываются
+	StateBlocked      State = "blocked"      // Шаг прерван, его нельзя ни повторить, ни отменить: нужна ручная сверка
+)
+
+// Record - запись журнала о незавершенной саге. Завершенные и откаченные саги из журнала удаляются
+type Record struct {
+	Id        string          `json:"id"`
+	Name      string          `json:"name"`              // Имя саги, по нему восстановление находит свои записи
+	State     State           `json:"state"`             // Состояние саги
+	Step      int             `json:"step"`              // Количество выполненных шагов, при откате - еще не откаченных
+	InDoubt   bool            `json:"inDoubt,omitempty"` // Шаг Step начат, результат неизвестен
+	Data      json.RawMessage `json:"data"`              // Данные саги после последнего выполненного шага
+	Error     string          `json:"error,omitempty"`   // Ошибка, из-за которой сага откатывается
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// Journal - постоянный журнал незавершенных саг
+type Journal interface {
+	// Save - сохраняет запись
+	Save(ctx context.Context, record Record) error
+	// Delete - удаляет запись
+	Delete(ctx context.Context, id string) error
+	// List - возвращает все записи в порядке создания
+	List(ctx context.Context) ([]Record, error)
+}
+
+// MemoryJournal - журнал в памяти процесса, теряется при перезапуске (только для разработки и тестов)
+type MemoryJournal struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryJournal - создает журнал в памяти процесса
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{records: make(map[string]Record)}
+}
+
+// Save - сохраняет запись
+func (j *MemoryJournal) Save(_ context.Context, record Record) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.records[record.Id] = record
+
+	return nil
+}
+
+// Delete - удаляет запись
+func (j *MemoryJournal) Delete(_ context.Context, id string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	delete(j.records, id)
+
+	return nil
+}
+
+// List - возвращает все записи
+func (j *MemoryJournal) List(_ context.Context) ([]Record, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return sortedRecords(j.records), nil
+}
+
+// FileJournal - журнал в JSON файле, файл перезаписывается целиком при каждом изменении
+type FileJournal struct {
+	records *filestore.Map[string, Record]
+}
+
+// NewFileJournal - создает журнал в файле path, существующий файл загружается
+func NewFileJournal(path string) (*FileJournal, error) {
+	records, err := filestore.Open[string, Record](path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileJournal{records: records}, nil
+}
+
+// Save - сохраняет запись
+func (j *FileJournal) Save(_ context.Context, record Record) error {
+	return j.records.Put(record.Id, record)
+}
+
+// Delete - удаляет запись
+func (j *FileJournal) Delete(_ context.Context, id string) error {
+	return j.records.Delete(id)
+}
+
+// List - возвращает все записи
+func (j *FileJournal) List(_ context.Context) ([]Record, error) {
+	return sortedRecords(j.records.All()), nil
+}
+
+// sortedRecords - записи в порядке создания
+func sortedRecords(records map[string]Record) []Record {
+	list := make([]Record, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+
+	sort.Slice(list, func(i, k int) bool {
+		if list[i].CreatedAt.Equal(list[k].CreatedAt) {
+			return list[i].Id < list[k].Id
+		}
+		return list[i].CreatedAt.Before(list[k].CreatedAt)
+	})
+
+	return list
+}
@@ -0,0 +1,296 @@
+package saga
+
+import (
+	"apiGateway/pkg/logger"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCompensationFailed - откат саги не завершен, запись осталась в журнале и будет повторена при восстановлении
+	ErrCompensationFailed = errors.New("откат саги не завершен")
+	// ErrRecoveryPending - шаг после необратимого шага не выполнен, сага осталась в журнале и будет продолжена
+	// при восстановлении
+	ErrRecoveryPending = errors.New("сага не завершена и будет продолжена при восстановлении")
+)
+
+// Step - шаг саги над данными T
+type Step[T any] struct {
+	Name string
+	// Action - действие шага, может изменять данные (например, сохранить ID созданной записи для компенсации)
+	Action func(ctx context.Context, data *T) error
+	// Compensate - отменяет выполненное действие, nil - шаг не требует отмены
+	Compensate func(ctx context.Context, data *T) error
+	// Retry - действие идемпотентно: шаг, прерванный во время выполнения, при восстановлении повторяется
+	Retry bool
+	// CompensateInDoubt - откат шага безопасен, даже если действие не выполнялось или выполнено частично: шаг,
+	// прерванный во время выполнения, при восстановлении откатывается вместе с выполненными шагами
+	CompensateInDoubt bool
+	// Interactive - действию нужны данные запроса, которые не сохраняются в журнал (например, CVV): сага,
+	// прерванная перед этим шагом, при восстановлении не продолжается, а откатывается
+	Interactive bool
+	// Pivot - действие шага нельзя отменить: после его выполнения сага не откатывается, а следующие шаги
+	// повторяются до успеха (при восстановлении), поэтому они должны быть идемпотентными. Сага, прерванная во время
+	// самого шага, не откатывается и не повторяется, а блокируется до ручной сверки
+	Pivot bool
+}
+
+// Saga - последовательность шагов с компенсацией: при ошибке шага выполненные шаги отменяются в обратном порядке.
+// Перед каждым шагом и после него состояние сохраняется в журнал, что позволяет после перезапуска продолжить
+// прерванную сагу или откатить ее
+type Saga[T any] struct {
+	name    string
+	steps   []Step[T]
+	pivot   int // Количество шагов до последнего необратимого включительно, после них сага только продолжается
+	journal Journal
+	now     func() time.Time
+
+	mu     sync.Mutex
+	active map[string]struct{} // Саги, выполняющиеся в этом процессе, восстановление их не трогает
+}
+
+// New - создает сагу name из шагов steps с журналом journal
+func New[T any](name string, journal Journal, steps ...Step[T]) *Saga[T] {
+	s := &Saga[T]{
+		name:    name,
+		steps:   steps,
+		journal: journal,
+		now:     time.Now,
+		active:  make(map[string]struct{}),
+	}
+
+	for i, step := range steps {
+		if step.Pivot {
+			s.pivot = i + 1
+		}
+	}
+
+	return s
+}
+
+// Run - выполняет сагу над данными data. При ошибке шага выполненные шаги отменяются и возвращается ошибка шага,
+// если отменить шаги не удалось, к ней добавляется ErrCompensationFailed. Ошибка шага после необратимого шага
+// не откатывает сагу, к ней добавляется ErrRecoveryPending
+func (s *Saga[T]) Run(ctx context.Context, data *T) error {
+	id, err := newId()
+	if err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	record := Record{Id: id, Name: s.name, State: StateRunning, CreatedAt: now, UpdatedAt: now}
+
+	s.acquire(id)
+	defer s.release(id)
+
+	return s.forward(ctx, record, data)
+}
+
+// Recover - продолжает или откатывает саги из журнала, прерванные перезапуском или незавершенным откатом:
+// сага, прерванная между шагами, продолжается со следующего шага (перед шагом Interactive - откатывается);
+// незавершенный откат повторяется. Шаг, прерванный во время выполнения, повторяется, если он идемпотентный (Retry)
+// или следует за необратимым шагом, и откатывается вместе с выполненными шагами, если его откат безопасен
+// (CompensateInDoubt). Иначе сага блокируется (StateBlocked) до ручной сверки, а не откатывается частично
+func (s *Saga[T]) Recover(ctx context.Context) error {
+	records, err := s.journal.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, record := range records {
+		if record.Name != s.name || !s.acquire(record.Id) {
+			continue
+		}
+
+		err = s.recoverRecord(ctx, record)
+		s.release(record.Id)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("сага %s %s: %w", s.name, record.Id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// recoverRecord - восстанавливает одну сагу из журнала
+func (s *Saga[T]) recoverRecord(ctx context.Context, record Record) error {
+	data := new(T)
+	if err := json.Unmarshal(record.Data, data); err != nil {
+		return err
+	}
+
+	switch {
+	case record.State == StateBlocked:
+		return nil
+	case record.State == StateCompensating:
+		logger.Warn("Повтор отката саги %s %s: %s", s.name, record.Id, record.Error)
+		return s.compensate(ctx, record, data)
+	case record.InDoubt && s.pivot > 0 && record.Step >= s.pivot:
+		logger.Warn("Сага %s %s прервана во время шага %q после необратимого шага, шаг повторяется",
+			s.name, record.Id, s.stepName(record.Step))
+		return s.forward(ctx, record, data)
+	case record.InDoubt && s.steps[record.Step].Pivot:
+		// Необратимый шаг мог быть выполнен: откат вернул бы предыдущие шаги, а повтор мог бы выполнить его дважды
+		logger.Error("Сага %s %s прервана во время необратимого шага %q, сага заблокирована до ручной сверки: %s",
+			s.name, record.Id, s.stepName(record.Step), string(record.Data))
+		record.State = StateBlocked
+		record.Error = fmt.Sprintf("сага прервана во время необратимого шага %q, требуется ручная сверка",
+			s.stepName(record.Step))
+		return s.save(ctx, &record, data)
+	case record.InDoubt && s.steps[record.Step].Retry:
+		logger.Warn("Сага %s %s прервана во время шага %q, шаг повторяется", s.name, record.Id,
+			s.stepName(record.Step))
+		return s.forward(ctx, record, data)
+	case record.InDoubt && s.steps[record.Step].CompensateInDoubt:
+		logger.Warn("Сага %s %s прервана во время шага %q, сага откатывается вместе с шагом", s.name, record.Id,
+			s.stepName(record.Step))
+		record.State = StateCompensating
+		record.InDoubt = false
+		record.Error = fmt.Sprintf("сага прервана во время шага %q", s.stepName(record.Step))
+		record.Step++
+		return s.compensate(ctx, record, data)
+	case record.InDoubt:
+		logger.Error("Сага %s %s прервана во время шага %q, шаг мог быть выполнен, сага заблокирована до ручной "+
+			"сверки: %s", s.name, record.Id, s.stepName(record.Step), string(record.Data))
+		record.State = StateBlocked
+		record.Error = fmt.Sprintf("сага прервана во время шага %q, требуется ручная сверка", s.stepName(record.Step))
+		return s.save(ctx, &record, data)
+	case record.Step < len(s.steps) && s.steps[record.Step].Interactive:
+		logger.Warn("Сага %s %s прервана перед шагом %q, которому нужны данные запроса, сага откатывается",
+			s.name, record.Id, s.stepName(record.Step))
+		record.State = StateCompensating
+		record.Error = fmt.Sprintf("сага прервана перед шагом %q", s.stepName(record.Step))
+		return s.compensate(ctx, record, data)
+	default:
+		logger.Info("Продолжение саги %s %s с шага %q", s.name, record.Id, s.stepName(record.Step))
+		return s.forward(ctx, record, data)
+	}
+}
+
+// forward - выполняет шаги начиная с record.Step
+func (s *Saga[T]) forward(ctx context.Context, record Record, data *T) error {
+	for record.Step < len(s.steps) {
+		step := s.steps[record.Step]
+
+		record.InDoubt = true
+		if err := s.save(ctx, &record, data); err != nil {
+			return err
+		}
+
+		if err := step.Action(ctx, data); err != nil {
+			record.InDoubt = false
+			record.Error = fmt.Sprintf("шаг %q: %v", step.Name, err)
+
+			// Необратимый шаг выполнен: сага остается в журнале, шаг повторяется при восстановлении
+			if s.pivot > 0 && record.Step >= s.pivot {
+				logger.Warn("Шаг %q саги %s %s не выполнен и будет повторен: %v", step.Name, s.name, record.Id, err)
+				if serr := s.save(ctx, &record, data); serr != nil {
+					return errors.Join(err, serr)
+				}
+				return errors.Join(err, ErrRecoveryPending)
+			}
+
+			record.State = StateCompensating
+
+			if cerr := s.compensate(ctx, record, data); cerr != nil {
+				return errors.Join(err, cerr)
+			}
+			return err
+		}
+
+		record.Step++
+		record.InDoubt = false
+		if err := s.save(ctx, &record, data); err != nil {
+			return err
+		}
+	}
+
+	return s.journal.Delete(ctx, record.Id)
+}
+
+// compensate - отменяет выполненные шаги record.Step-1 ... 0 в обратном порядке и удаляет запись из журнала
+func (s *Saga[T]) compensate(ctx context.Context, record Record, data *T) error {
+	if err := s.save(ctx, &record, data); err != nil {
+		return fmt.Errorf("%w: %v", ErrCompensationFailed, err)
+	}
+
+	for record.Step > 0 {
+		step := s.steps[record.Step-1]
+
+		if step.Compensate != nil {
+			if err := step.Compensate(ctx, data); err != nil {
+				logger.Error("Ошибка при отмене шага %q саги %s %s: %v", step.Name, s.name, record.Id, err)
+				return fmt.Errorf("%w: шаг %q: %v", ErrCompensationFailed, step.Name, err)
+			}
+		}
+
+		record.Step--
+		if err := s.save(ctx, &record, data); err != nil {
+			return fmt.Errorf("%w: %v", ErrCompensationFailed, err)
+		}
+	}
+
+	return s.journal.Delete(ctx, record.Id)
+}
+
+// save - сохраняет запись с текущими данными саги в журнал
+func (s *Saga[T]) save(ctx context.Context, record *Record, data *T) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	record.Data = raw
+	record.UpdatedAt = s.now().UTC()
+
+	return s.journal.Save(ctx, *record)
+}
+
+// stepName - имя шага по номеру
+func (s *Saga[T]) stepName(step int) string {
+	if step < 0 || step >= len(s.steps) {
+		return fmt.Sprint(step)
+	}
+
+	return s.steps[step].Name
+}
+
+// acquire - отмечает сагу как выполняющуюся, false - сага уже выполняется
+func (s *Saga[T]) acquire(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.active[id]; ok {
+		return false
+	}
+	s.active[id] = struct{}{}
+
+	return true
+}
+
+// release - снимает отметку выполнения саги
+func (s *Saga[T]) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.active, id)
+}
+
+// newId - случайный ID саги
+func newId() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,295 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// order - данные тестовой саги: журнал выполненных действий
+type order struct {
+	Log []string `json:"log"`
+}
+
+// testSteps - шаги reserve -> charge -> notify, ошибки шагов задаются через fail
+func testSteps(fail map[string]error) []Step[order] {
+	step := func(name string) Step[order] {
+		return Step[order]{
+			Name: name,
+			Action: func(_ context.Context, data *order) error {
+				if err := fail[name]; err != nil {
+					return err
+				}
+				data.Log = append(data.Log, name)
+				return nil
+			},
+			Compensate: func(_ context.Context, data *order) error {
+				if err := fail["undo "+name]; err != nil {
+					return err
+				}
+				data.Log = append(data.Log, "undo "+name)
+				return nil
+			},
+		}
+	}
+
+	return []Step[order]{step("reserve"), step("charge"), step("notify")}
+}
+
+func TestSagaCompensation(t *testing.T) {
+	ctx := context.Background()
+	errCharge := errors.New("charge failed")
+	errUndo := errors.New("undo failed")
+
+	path := filepath.Join(t.TempDir(), "saga.json")
+	journal, err := NewFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fail := map[string]error{}
+	s := New("order", journal, testSteps(fail)...)
+
+	data := &order{}
+	if err = s.Run(ctx, data); err != nil {
+		t.Fatalf("Run(): %v", err)
+	}
+	if records, _ := journal.List(ctx); len(records) != 0 {
+		t.Errorf("после успешной саги в журнале %d записей", len(records))
+	}
+
+	fail["charge"] = errCharge
+	data = &order{}
+	if err = s.Run(ctx, data); !errors.Is(err, errCharge) || errors.Is(err, ErrCompensationFailed) {
+		t.Fatalf("Run() с ошибкой шага: %v", err)
+	}
+	if want := []string{"reserve", "undo reserve"}; !slices.Equal(data.Log, want) {
+		t.Errorf("действия = %v, want %v", data.Log, want)
+	}
+
+	// Откат не удался: запись остается в журнале и откатывается при восстановлении
+	fail["undo reserve"] = errUndo
+	if err = s.Run(ctx, &order{}); !errors.Is(err, errCharge) || !errors.Is(err, ErrCompensationFailed) {
+		t.Fatalf("Run() с ошибкой отката: %v", err)
+	}
+
+	reloaded, err := NewFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, _ := reloaded.List(ctx)
+	if len(records) != 1 || records[0].State != StateCompensating || records[0].Step != 1 {
+		t.Fatalf("журнал после ошибки отката: %+v", records)
+	}
+
+	delete(fail, "undo reserve")
+	restarted := New("order", reloaded, testSteps(fail)...)
+	if err = restarted.Recover(ctx); err != nil {
+		t.Fatalf("Recover(): %v", err)
+	}
+	if records, _ = reloaded.List(ctx); len(records) != 0 {
+		t.Errorf("после восстановления в журнале %d записей", len(records))
+	}
+}
+
+func TestSagaRecover(t *testing.T) {
+	ctx := context.Background()
+	journal := NewMemoryJournal()
+
+	// Сага прервана между шагами reserve и charge - продолжается
+	_ = journal.Save(ctx, Record{Id: "resume", Name: "order", State: StateRunning, Step: 1,
+		Data: []byte(`{"log":["reserve"]}`)})
+	// Сага прервана во время шага charge с безопасным откатом - откатываются charge и выполненный reserve
+	_ = journal.Save(ctx, Record{Id: "rollback", Name: "order", State: StateRunning, Step: 1, InDoubt: true,
+		Data: []byte(`{"log":["reserve"]}`)})
+	// Запись другой саги не трогается
+	_ = journal.Save(ctx, Record{Id: "other", Name: "refund", State: StateRunning, Data: []byte(`{}`)})
+
+	var finished []string
+	steps := testSteps(map[string]error{})
+	steps[2].Action = func(_ context.Context, data *order) error {
+		finished = append(finished, data.Log...)
+		return nil
+	}
+	steps[1].CompensateInDoubt = true
+	steps[1].Compensate = func(_ context.Context, data *order) error {
+		finished = append(finished, "undo charge")
+		return nil
+	}
+	steps[0].Compensate = func(_ context.Context, data *order) error {
+		finished = append(finished, "undo reserve")
+		return nil
+	}
+
+	if err := New("order", journal, steps...).Recover(ctx); err != nil {
+		t.Fatalf("Recover(): %v", err)
+	}
+
+	if want := []string{"reserve", "charge", "undo charge", "undo reserve"}; !slices.Equal(finished, want) {
+		t.Errorf("действия = %v, want %v", finished, want)
+	}
+
+	records, _ := journal.List(ctx)
+	if len(records) != 1 || records[0].Id != "other" {
+		t.Errorf("журнал после восстановления: %+v", records)
+	}
+}
+
+func TestSagaPivot(t *testing.T) {
+	ctx := context.Background()
+	errNotify := errors.New("notify failed")
+	journal := NewMemoryJournal()
+
+	fail := map[string]error{"notify": errNotify}
+	steps := testSteps(fail)
+	steps[1].Pivot = true
+	s := New("order", journal, steps...)
+
+	// Шаг после необратимого charge не выполнен: выполненные шаги не откатываются, сага остается в журнале
+	data := &order{}
+	if err := s.Run(ctx, data); !errors.Is(err, errNotify) || !errors.Is(err, ErrRecoveryPending) {
+		t.Fatalf("Run(): %v", err)
+	}
+	if want := []string{"reserve", "charge"}; !slices.Equal(data.Log, want) {
+		t.Errorf("действия = %v, want %v", data.Log, want)
+	}
+
+	records, _ := journal.List(ctx)
+	if len(records) != 1 || records[0].State != StateRunning || records[0].Step != 2 || records[0].InDoubt {
+		t.Fatalf("журнал после ошибки шага: %+v", records)
+	}
+
+	// Сага, прерванная во время шага после необратимого, тоже продолжается, а не откатывается
+	_ = journal.Save(ctx, Record{Id: "interrupted", Name: "order", State: StateRunning, Step: 2, InDoubt: true,
+		Data: []byte(`{"log":["reserve","charge"]}`)})
+
+	var finished []string
+	delete(fail, "notify")
+	steps[2].Action = func(_ context.Context, data *order) error {
+		finished = append(finished, "notify")
+		return nil
+	}
+	steps[0].Compensate = func(_ context.Context, data *order) error {
+		t.Error("выполненный шаг откатывается после необратимого шага")
+		return nil
+	}
+
+	if err := New("order", journal, steps...).Recover(ctx); err != nil {
+		t.Fatalf("Recover(): %v", err)
+	}
+	if want := []string{"notify", "notify"}; !slices.Equal(finished, want) {
+		t.Errorf("действия при восстановлении = %v, want %v", finished, want)
+	}
+	if records, _ = journal.List(ctx); len(records) != 0 {
+		t.Errorf("после восстановления в журнале %d записей", len(records))
+	}
+}
+
+func TestSagaRecoverInteractive(t *testing.T) {
+	ctx := context.Background()
+	journal := NewMemoryJournal()
+
+	// Сага прервана перед шагом charge, которому нужны данные запроса, - выполненный reserve откатывается
+	_ = journal.Save(ctx, Record{Id: "interactive", Name: "order", State: StateRunning, Step: 1,
+		Data: []byte(`{"log":["reserve"]}`)})
+
+	var finished []string
+	steps := testSteps(map[string]error{})
+	steps[1].Interactive = true
+	steps[1].Action = func(_ context.Context, data *order) error {
+		finished = append(finished, "charge")
+		return nil
+	}
+	steps[0].Compensate = func(_ context.Context, data *order) error {
+		finished = append(finished, "undo reserve")
+		return nil
+	}
+
+	if err := New("order", journal, steps...).Recover(ctx); err != nil {
+		t.Fatalf("Recover(): %v", err)
+	}
+	if want := []string{"undo reserve"}; !slices.Equal(finished, want) {
+		t.Errorf("действия = %v, want %v", finished, want)
+	}
+	if records, _ := journal.List(ctx); len(records) != 0 {
+		t.Errorf("после восстановления в журнале %d записей", len(records))
+	}
+}
+
+func TestSagaRecoverInDoubt(t *testing.T) {
+	ctx := context.Background()
+	journal := NewMemoryJournal()
+
+	// Прерван идемпотентный charge - шаг повторяется
+	_ = journal.Save(ctx, Record{Id: "retry", Name: "order", State: StateRunning, Step: 1, InDoubt: true,
+		Data: []byte(`{"log":["reserve"]}`)})
+	// Прерван notify, который нельзя ни повторить, ни отменить, - сага блокируется без частичного отката
+	_ = journal.Save(ctx, Record{Id: "blocked", Name: "order", State: StateRunning, Step: 2, InDoubt: true,
+		Data: []byte(`{"log":["reserve","charge"]}`)})
+
+	var finished []string
+	steps := testSteps(map[string]error{})
+	steps[1].Retry = true
+	steps[2].Action = func(_ context.Context, data *order) error {
+		finished = append(finished, data.Log...)
+		return nil
+	}
+	for i := range steps {
+		steps[i].Compensate = func(_ context.Context, data *order) error {
+			t.Errorf("шаг откатывается: %v", data.Log)
+			return nil
+		}
+	}
+
+	s := New("order", journal, steps...)
+	for i := 0; i < 2; i++ {
+		if err := s.Recover(ctx); err != nil {
+			t.Fatalf("Recover(): %v", err)
+		}
+	}
+
+	if want := []string{"reserve", "charge"}; !slices.Equal(finished, want) {
+		t.Errorf("действия = %v, want %v", finished, want)
+	}
+
+	records, _ := journal.List(ctx)
+	if len(records) != 1 || records[0].Id != "blocked" || records[0].State != StateBlocked || records[0].Step != 2 {
+		t.Errorf("журнал после восстановления: %+v", records)
+	}
+}
+
+func TestSagaRecoverInDoubtPivot(t *testing.T) {
+	ctx := context.Background()
+	journal := NewMemoryJournal()
+
+	// Прерван необратимый charge: даже идемпотентный и откатываемый шаг не повторяется и не откатывается
+	_ = journal.Save(ctx, Record{Id: "pivot", Name: "order", State: StateRunning, Step: 1, InDoubt: true,
+		Data: []byte(`{"log":["reserve"]}`)})
+
+	steps := testSteps(map[string]error{})
+	steps[1].Pivot = true
+	steps[1].Retry = true
+	steps[1].CompensateInDoubt = true
+	for i := range steps {
+		steps[i].Action = func(_ context.Context, data *order) error {
+			t.Errorf("шаг повторяется: %v", data.Log)
+			return nil
+		}
+		steps[i].Compensate = func(_ context.Context, data *order) error {
+			t.Errorf("шаг откатывается: %v", data.Log)
+			return nil
+		}
+	}
+
+	s := New("order", journal, steps...)
+	if err := s.Recover(ctx); err != nil {
+		t.Fatalf("Recover(): %v", err)
+	}
+
+	records, _ := journal.List(ctx)
+	if len(records) != 1 || records[0].State != StateBlocked || records[0].Step != 1 {
+		t.Errorf("журнал после восстановления: %+v", records)
+	}
+}
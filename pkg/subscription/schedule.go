@@ -0,0 +1,27 @@
+package subscription
+
+import "time"
+
+// NextRun - первое плановое время платежа в день месяца day строго после after. Платежи выполняются в 00:00 UTC,
+// если в месяце меньше day дней, платеж выполняется в последний день месяца
+func NextRun(after time.Time, day int) time.Time {
+	after = after.UTC()
+
+	for offset := 0; ; offset++ {
+		run := runAt(after.Year(), after.Month()+time.Month(offset), day)
+		if run.After(after) {
+			return run
+		}
+	}
+}
+
+// runAt - плановое время платежа в месяце month года year
+func runAt(year int, month time.Month, day int) time.Time {
+	// Нулевой день следующего месяца - последний день месяца month, time.Date нормализует переполнение месяца
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > last {
+		day = last
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,202 @@
+package subscription
+
+import (
+	"apiGateway/pkg/config"
+	"apiGateway/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPause - платеж невозможен, пока пользователь не изменит подписку (подопечный собрал нужную сумму,
+// карта удалена). Функция Charge оборачивает эту ошибку, планировщик приостанавливает подписку
+var ErrPause = errors.New("подписка приостановлена")
+
+// errChanged - подписка изменена пользователем во время платежа, результат платежа не записывается в расписание
+var errChanged = errors.New("подписка изменена")
+
+// Charge - выполняет платеж подписки за период period, attempt - номер попытки начиная с 1.
+// Возвращает ID платежа в реестре
+type Charge func(ctx context.Context, subscription Subscription, period time.Time, attempt int) (string, error)
+
+// Scheduler - планировщик ежемесячных платежей: выполняет платежи подписок, срок которых наступил, после отказа
+// повторяет платеж с экспоненциальной задержкой, после простоя выполняет пропущенные платежи не старше
+// catch_up_window
+type Scheduler struct {
+	policy config.Subscriptions
+	store  Store
+	charge Charge
+	now    func() time.Time
+}
+
+// NewScheduler - создает планировщик подписок из store, платежи выполняются функцией charge
+func NewScheduler(policy config.Subscriptions, store Store, charge Charge) *Scheduler {
+	return &Scheduler{policy: policy, store: store, charge: charge, now: time.Now}
+}
+
+// SetClock - заменяет часы планировщика, используется в тестах и в режиме dry_run
+func (s *Scheduler) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Run - проверяет подписки при запуске и затем каждые check_interval, завершается при отмене ctx
+func (s *Scheduler) Run(ctx context.Context) {
+	interval := s.policy.CheckInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Tick(ctx); err != nil {
+			logger.Error("Ошибка при выполнении платежей по подпискам: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick - выполняет платежи всех подписок, срок которых наступил
+func (s *Scheduler) Tick(ctx context.Context) error {
+	subscriptions, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, subscription := range subscriptions {
+		if err := s.process(ctx, subscription); err != nil {
+			errs = append(errs, fmt.Errorf("подписка %s: %w", subscription.Id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// process - выполняет платежи подписки за все наступившие периоды
+func (s *Scheduler) process(ctx context.Context, subscription Subscription) error {
+	for subscription.Status == StatusActive && !s.now().Before(due(subscription)) {
+		period := subscription.NextRun
+		apply := s.run(ctx, subscription)
+
+		var err error
+		subscription, err = s.store.Update(ctx, subscription.Id, func(current *Subscription) error {
+			if !current.NextRun.Equal(period) {
+				return errChanged
+			}
+			apply(current)
+			return nil
+		})
+		if errors.Is(err, errChanged) || errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// run - выполняет платеж за период subscription.NextRun и возвращает изменение подписки по его результату
+func (s *Scheduler) run(ctx context.Context, subscription Subscription) func(*Subscription) {
+	now := s.now()
+	period := subscription.NextRun
+
+	if s.policy.CatchUpWindow > 0 && now.Sub(period) > s.policy.CatchUpWindow {
+		logger.Warn("Платеж подписки %s за %s пропущен: шлюз не работал дольше catch_up_window", subscription.Id,
+			period.Format(time.DateOnly))
+		return func(current *Subscription) {
+			current.LastError = fmt.Sprintf("платеж за %s пропущен", period.Format(time.DateOnly))
+			advance(current)
+		}
+	}
+
+	if s.policy.DryRun {
+		logger.Info("[dry_run] Платеж подписки %s за %s: пользователь %d, подопечный %d, сумма %s", subscription.Id,
+			period.Format(time.DateOnly), subscription.UserId, subscription.WardId, subscription.Amount)
+		return func(current *Subscription) {
+			current.LastRunAt = now
+			current.LastError = ""
+			advance(current)
+		}
+	}
+
+	paymentId, err := s.charge(ctx, subscription, period, subscription.Attempts+1)
+	switch {
+	case err == nil:
+		return func(current *Subscription) {
+			current.LastRunAt = now
+			current.LastPaymentId = paymentId
+			current.LastError = ""
+			advance(current)
+		}
+	case errors.Is(err, ErrPause):
+		logger.Warn("Подписка %s приостановлена: %v", subscription.Id, err)
+		return func(current *Subscription) {
+			current.Status = StatusPaused
+			current.PauseReason = err.Error()
+			current.Attempts = 0
+			current.RetryAt = time.Time{}
+		}
+	case subscription.Attempts+1 >= s.policy.MaxAttempts:
+		logger.Error("Платеж подписки %s за %s не выполнен после %d попыток: %v", subscription.Id,
+			period.Format(time.DateOnly), subscription.Attempts+1, err)
+		return func(current *Subscription) {
+			current.LastError = err.Error()
+			advance(current)
+		}
+	default:
+		retryAt := now.Add(s.backoff(subscription.Attempts + 1))
+		logger.Warn("Платеж подписки %s не выполнен, повтор в %s: %v", subscription.Id, retryAt.Format(time.RFC3339),
+			err)
+		return func(current *Subscription) {
+			current.Attempts++
+			current.RetryAt = retryAt
+			current.LastError = err.Error()
+		}
+	}
+}
+
+// backoff - задержка повтора после attempts неудачных попыток
+func (s *Scheduler) backoff(attempts int) time.Duration {
+	limit := s.policy.RetryMaxDelay
+	if limit <= 0 {
+		limit = 24 * time.Hour
+	}
+
+	delay := s.policy.RetryBaseDelay
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+
+	if delay > limit {
+		return limit
+	}
+
+	return delay
+}
+
+// due - время, когда подписке нужен следующий платеж: время повтора или плановое время
+func due(subscription Subscription) time.Time {
+	if !subscription.RetryAt.IsZero() {
+		return subscription.RetryAt
+	}
+
+	return subscription.NextRun
+}
+
+// advance - переводит подписку на следующий период
+func advance(subscription *Subscription) {
+	subscription.NextRun = NextRun(subscription.NextRun, subscription.Day)
+	subscription.Attempts = 0
+	subscription.RetryAt = time.Time{}
+}
@@ -0,0 +1,112 @@
+package subscription
+
+import (
+	"apiGateway/pkg/config"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNextRun(t *testing.T) {
+	tests := []struct {
+		after time.Time
+		day   int
+		want  time.Time
+	}{
+		{after: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), day: 15, want: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{after: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), day: 15, want: time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{after: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), day: 31, want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{after: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), day: 31, want: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{after: time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC), day: 5, want: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := NextRun(tt.after, tt.day); !got.Equal(tt.want) {
+			t.Errorf("NextRun(%s, %d) = %s, want %s", tt.after, tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	store := NewMemoryStore()
+	var charged []string
+	var declines int
+	charge := func(_ context.Context, s Subscription, period time.Time, attempt int) (string, error) {
+		if s.Id == "full" {
+			return "", fmt.Errorf("%w: подопечный собрал нужную сумму", ErrPause)
+		}
+		if declines > 0 {
+			declines--
+			return "", errors.New("платеж отклонен банком")
+		}
+		charged = append(charged, fmt.Sprintf("%s %s #%d", s.Id, period.Format(time.DateOnly), attempt))
+		return "p", nil
+	}
+
+	scheduler := NewScheduler(config.Subscriptions{
+		RetryBaseDelay: time.Hour,
+		RetryMaxDelay:  4 * time.Hour,
+		MaxAttempts:    3,
+		CatchUpWindow:  10 * 24 * time.Hour,
+	}, store, charge)
+	scheduler.SetClock(func() time.Time { return now })
+
+	// Шлюз не работал с 1 января: январский и февральский платежи старше catch_up_window, выполняется только мартовский
+	for _, s := range []Subscription{
+		{Id: "monthly", Day: 1, Status: StatusActive, NextRun: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Id: "full", Day: 1, Status: StatusActive, NextRun: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		if err := store.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := scheduler.Tick(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"monthly 2024-03-01 #1"}
+	if fmt.Sprint(charged) != fmt.Sprint(want) {
+		t.Errorf("платежи: %v, want %v", charged, want)
+	}
+
+	monthly, _ := store.Get(ctx, "monthly")
+	if !monthly.NextRun.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("следующий платеж: %s, want 2024-04-01", monthly.NextRun)
+	}
+	if full, _ := store.Get(ctx, "full"); full.Status != StatusPaused {
+		t.Errorf("статус подписки подопечного, собравшего сумму: %s, want %s", full.Status, StatusPaused)
+	}
+
+	// Отказ банка: повторы через 1h и 2h, третья попытка успешна
+	declines = 2
+	now = time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	for _, wait := range []time.Duration{0, time.Hour, 2 * time.Hour} {
+		now = now.Add(wait)
+		if err := scheduler.Tick(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want = append(want, "monthly 2024-04-01 #3")
+	if fmt.Sprint(charged) != fmt.Sprint(want) {
+		t.Errorf("платежи: %v, want %v", charged, want)
+	}
+
+	// Режим dry_run не выполняет платежи, но продвигает расписание
+	scheduler.policy.DryRun = true
+	now = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	if err := scheduler.Tick(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(charged) != len(want) {
+		t.Errorf("dry_run выполнил платеж: %v", charged)
+	}
+	if monthly, _ = store.Get(ctx, "monthly"); !monthly.NextRun.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("следующий платеж после dry_run: %s, want 2024-06-01", monthly.NextRun)
+	}
+}
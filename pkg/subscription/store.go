@@ -0,0 +1,192 @@
+package subscription
+
+import (
+	"apiGateway/pkg/filestore"
+	"apiGateway/pkg/money"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotFound - подписка не найдена
+var ErrNotFound = errors.New("подписка не найдена")
+
+// Status - состояние подписки
+type Status string
+
+const (
+	StatusActive Status = "active" // Платежи выполняются по расписанию
+	StatusPaused Status = "paused" // Платежи приостановлены пользователем или планировщиком
+)
+
+// Subscription - ежемесячное пожертвование подопечному
+type Subscription struct {
+	Id            string       `json:"id"`
+	UserId        uint64       `json:"userId"`
+	WardId        uint64       `json:"wardId"`
+	CardId        uint64       `json:"cardId"`
+	Amount        money.Amount `json:"amount"`
+	Day           int          `json:"day"` // День месяца, в коротких месяцах платеж выполняется в последний день
+	Status        Status       `json:"status"`
+	PauseReason   string       `json:"pauseReason,omitempty"`
+	NextRun       time.Time    `json:"nextRun"`                 // Плановое время следующего платежа
+	Attempts      int          `json:"attempts,omitempty"`      // Неудачные попытки платежа за период NextRun
+	RetryAt       time.Time    `json:"retryAt,omitempty"`       // Время повтора после неудачной попытки
+	LastRunAt     time.Time    `json:"lastRunAt,omitempty"`     // Время последнего успешного платежа
+	LastPaymentId string       `json:"lastPaymentId,omitempty"` // ID последнего успешного платежа в реестре
+	LastError     string       `json:"lastError,omitempty"`     // Ошибка последней попытки
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+}
+
+// Store - хранилище подписок
+type Store interface {
+	// Get - возвращает подписку по ID
+	Get(ctx context.Context, id string) (Subscription, error)
+	// Create - сохраняет новую подписку
+	Create(ctx context.Context, subscription Subscription) error
+	// Update - атомарно изменяет подписку функцией change, при ошибке change подписка не меняется
+	Update(ctx context.Context, id string, change func(subscription *Subscription) error) (Subscription, error)
+	// Delete - удаляет подписку
+	Delete(ctx context.Context, id string) error
+	// List - возвращает все подписки в порядке создания
+	List(ctx context.Context) ([]Subscription, error)
+}
+
+// MemoryStore - подписки в памяти процесса, теряются при перезапуске (только для разработки и тестов)
+type MemoryStore struct {
+	mu            sync.Mutex
+	subscriptions map[string]Subscription
+	now           func() time.Time
+	flush         func(subscriptions map[string]Subscription) error
+}
+
+// NewMemoryStore - создает хранилище подписок в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{subscriptions: make(map[string]Subscription), now: time.Now}
+}
+
+// NewFileStore - создает хранилище подписок в JSON файле path, файл перезаписывается целиком при каждом изменении
+func NewFileStore(path string) (*MemoryStore, error) {
+	store := NewMemoryStore()
+	store.flush = func(subscriptions map[string]Subscription) error {
+		return filestore.Save(path, subscriptions)
+	}
+
+	if err := filestore.Load(path, &store.subscriptions); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Get - возвращает подписку по ID
+func (s *MemoryStore) Get(_ context.Context, id string) (Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, ok := s.subscriptions[id]
+	if !ok {
+		return Subscription{}, ErrNotFound
+	}
+
+	return subscription, nil
+}
+
+// Create - сохраняет новую подписку
+func (s *MemoryStore) Create(_ context.Context, subscription Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	s.subscriptions[subscription.Id] = subscription
+
+	if err := s.save(); err != nil {
+		delete(s.subscriptions, subscription.Id)
+		return err
+	}
+
+	return nil
+}
+
+// Update - атомарно изменяет подписку
+func (s *MemoryStore) Update(_ context.Context, id string,
+	change func(subscription *Subscription) error) (Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.subscriptions[id]
+	if !ok {
+		return Subscription{}, ErrNotFound
+	}
+
+	subscription := previous
+	if err := change(&subscription); err != nil {
+		return previous, err
+	}
+	subscription.Id = id
+	subscription.UpdatedAt = s.now().UTC()
+
+	s.subscriptions[id] = subscription
+
+	if err := s.save(); err != nil {
+		s.subscriptions[id] = previous
+		return previous, err
+	}
+
+	return subscription, nil
+}
+
+// Delete - удаляет подписку
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.subscriptions[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	delete(s.subscriptions, id)
+
+	if err := s.save(); err != nil {
+		s.subscriptions[id] = previous
+		return err
+	}
+
+	return nil
+}
+
+// List - возвращает все подписки
+func (s *MemoryStore) List(_ context.Context) ([]Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Subscription, 0, len(s.subscriptions))
+	for _, subscription := range s.subscriptions {
+		list = append(list, subscription)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].Id < list[j].Id
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list, nil
+}
+
+// save - сохраняет файл хранилища, вызывается под блокировкой
+func (s *MemoryStore) save() error {
+	if s.flush == nil {
+		return nil
+	}
+
+	return s.flush(s.subscriptions)
+}
@@ -0,0 +1,111 @@
+package throttle
+
+import (
+	"apiGateway/pkg/config"
+	"context"
+	"time"
+)
+
+// Guard - ограничение неудачных попыток по ключу (телефон, IP): после FreeAttempts неудач каждая следующая
+// попытка возможна только через экспоненциально растущую задержку, после MaxFailures неудач ключ блокируется
+// на Lockout. Счетчик сбрасывается через Window после последней неудачи или при успешной попытке
+type Guard struct {
+	prefix string
+	policy config.Attempts
+	store  Store
+	now    func() time.Time
+}
+
+// NewGuard - создает Guard, ключи в хранилище получают префикс prefix. Политика с нулевым Window отключает проверку
+func NewGuard(prefix string, policy config.Attempts, store Store) *Guard {
+	return &Guard{prefix: prefix, policy: policy, store: store, now: time.Now}
+}
+
+// Check - возвращает время, через которое разрешена следующая попытка, 0 - попытка разрешена сейчас
+func (g *Guard) Check(ctx context.Context, key string) (time.Duration, error) {
+	if g.disabled() {
+		return 0, nil
+	}
+
+	entry, err := g.store.Get(ctx, g.prefix+key, g.now())
+	if err != nil {
+		return 0, err
+	}
+
+	return g.retryAfter(entry), nil
+}
+
+// Fail - учитывает неудачную попытку и возвращает время до следующей разрешенной попытки
+func (g *Guard) Fail(ctx context.Context, key string) (time.Duration, error) {
+	if g.disabled() {
+		return 0, nil
+	}
+
+	ttl := g.policy.Window
+	if g.policy.Lockout > ttl {
+		ttl = g.policy.Lockout
+	}
+
+	entry, err := g.store.Fail(ctx, g.prefix+key, g.now(), ttl)
+	if err != nil {
+		return 0, err
+	}
+
+	return g.retryAfter(entry), nil
+}
+
+// Reset - сбрасывает счетчик неудачных попыток после успешной попытки
+func (g *Guard) Reset(ctx context.Context, key string) error {
+	if g.disabled() {
+		return nil
+	}
+
+	return g.store.Reset(ctx, g.prefix+key)
+}
+
+// locked - ключ заблокирован после MaxFailures неудачных попыток
+func (g *Guard) locked(failures int) bool {
+	return g.policy.MaxFailures > 0 && failures >= g.policy.MaxFailures
+}
+
+// disabled - проверка отключена в конфигурации
+func (g *Guard) disabled() bool {
+	return g.policy.Window <= 0
+}
+
+// retryAfter - время до следующей разрешенной попытки для счетчика entry
+func (g *Guard) retryAfter(entry Entry) time.Duration {
+	wait := entry.LastFailure.Add(g.delay(entry.Failures)).Sub(g.now())
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
+
+// delay - задержка после failures неудачных попыток подряд, без MaxDelay задержка ограничена сутками
+func (g *Guard) delay(failures int) time.Duration {
+	if g.locked(failures) {
+		return g.policy.Lockout
+	}
+
+	if failures <= g.policy.FreeAttempts || g.policy.BaseDelay <= 0 {
+		return 0
+	}
+
+	limit := g.policy.MaxDelay
+	if limit <= 0 {
+		limit = 24 * time.Hour
+	}
+
+	delay := g.policy.BaseDelay
+	for i := g.policy.FreeAttempts + 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+
+	if delay > limit {
+		return limit
+	}
+
+	return delay
+}
@@ -0,0 +1,75 @@
+package throttle
+
+import (
+	"apiGateway/pkg/config"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGuard(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	guard := NewGuard("phone:", config.Attempts{
+		FreeAttempts: 2,
+		BaseDelay:    time.Second,
+		MaxDelay:     4 * time.Second,
+		MaxFailures:  6,
+		Lockout:      time.Hour,
+		Window:       time.Hour,
+	}, NewMemoryStore())
+	guard.now = func() time.Time { return now }
+
+	// Задержка после каждой неудачной попытки: без задержки, экспоненциальный рост, потолок, блокировка
+	want := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, time.Hour}
+	for i, wantWait := range want {
+		wait, err := guard.Fail(ctx, "79990000000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wait != wantWait {
+			t.Errorf("неудача %d: задержка = %s, want %s", i+1, wait, wantWait)
+		}
+	}
+
+	if wait, _ := guard.Check(ctx, "79990000001"); wait != 0 {
+		t.Errorf("задержка для другого телефона = %s, want 0", wait)
+	}
+
+	now = now.Add(30 * time.Minute)
+	if wait, _ := guard.Check(ctx, "79990000000"); wait != 30*time.Minute {
+		t.Errorf("оставшееся время блокировки = %s, want 30m", wait)
+	}
+
+	if err := guard.Reset(ctx, "79990000000"); err != nil {
+		t.Fatal(err)
+	}
+	if wait, _ := guard.Check(ctx, "79990000000"); wait != 0 {
+		t.Errorf("задержка после сброса = %s, want 0", wait)
+	}
+}
+
+func TestGuardWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	guard := NewGuard("ip:", config.Attempts{FreeAttempts: 1, BaseDelay: time.Minute, Window: time.Hour},
+		NewMemoryStore())
+	guard.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := guard.Fail(ctx, "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if wait, _ := guard.Check(ctx, "10.0.0.1"); wait != time.Minute {
+		t.Errorf("задержка после второй неудачи = %s, want 1m", wait)
+	}
+
+	// Счетчик истекает через Window после последней неудачи
+	now = now.Add(2 * time.Hour)
+	if wait, _ := guard.Fail(ctx, "10.0.0.1"); wait != 0 {
+		t.Errorf("задержка после истечения окна = %s, want 0", wait)
+	}
+}
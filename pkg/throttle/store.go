@@ -0,0 +1,102 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Entry - счетчик неудачных попыток по ключу
+type Entry struct {
+	Failures    int       // Количество неудачных попыток подряд
+	LastFailure time.Time // Время последней неудачной попытки
+}
+
+// Store - хранилище счетчиков неудачных попыток. Для одного экземпляра шлюза достаточно MemoryStore,
+// при нескольких экземплярах нужна общая реализация (например, Redis: INCR + EXPIRE)
+type Store interface {
+	// Get - возвращает счетчик по ключу на момент now, для неизвестного или истекшего ключа возвращается пустой Entry
+	Get(ctx context.Context, key string, now time.Time) (Entry, error)
+	// Fail - атомарно увеличивает счетчик, запись удаляется через ttl после последней неудачной попытки
+	Fail(ctx context.Context, key string, now time.Time, ttl time.Duration) (Entry, error)
+	// Reset - удаляет счетчик по ключу
+	Reset(ctx context.Context, key string) error
+}
+
+// sweepInterval - как часто MemoryStore удаляет истекшие записи
+const sweepInterval = time.Minute
+
+// memoryEntry - запись MemoryStore со сроком хранения
+type memoryEntry struct {
+	Entry
+	expires time.Time
+}
+
+// MemoryStore - хранилище счетчиков в памяти процесса
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryStore - создает хранилище счетчиков в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Get - возвращает счетчик по ключу
+func (s *MemoryStore) Get(_ context.Context, key string, now time.Time) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		return Entry{}, nil
+	}
+
+	return entry.Entry, nil
+}
+
+// Fail - увеличивает счетчик неудачных попыток по ключу
+func (s *MemoryStore) Fail(_ context.Context, key string, now time.Time, ttl time.Duration) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		entry = memoryEntry{}
+	}
+
+	entry.Failures++
+	entry.LastFailure = now
+	entry.expires = now.Add(ttl)
+	s.entries[key] = entry
+
+	return entry.Entry, nil
+}
+
+// Reset - удаляет счетчик по ключу
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
+
+// sweep - удаляет истекшие записи не чаще sweepInterval, вызывается под блокировкой
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
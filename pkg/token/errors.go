@@ -0,0 +1,29 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Ошибки проверки токенов JWT, проверяются через errors.Is
+var (
+	ErrTokenExpired   = errors.New("срок действия токена истек")
+	ErrTokenSignature = errors.New("неверная подпись токена")
+	ErrTokenAudience  = errors.New("токен выпущен для другой аудитории")
+	ErrTokenInvalid   = errors.New("неверный токен")
+)
+
+// classify - приводит ошибку библиотеки jwt к одной из ошибок пакета, исходная ошибка сохраняется в тексте
+func classify(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return fmt.Errorf("%w: %v", ErrTokenExpired, err)
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return fmt.Errorf("%w: %v", ErrTokenSignature, err)
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return fmt.Errorf("%w: %v", ErrTokenAudience, err)
+	default:
+		return fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+}
@@ -0,0 +1,173 @@
+package token
+
+import (
+	"apiGateway/pkg/config"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+)
+
+// signingKey - ключ подписи/проверки токенов JWT
+type signingKey struct {
+	kid     string            // Идентификатор ключа, передается в заголовке kid
+	method  jwt.SigningMethod // Алгоритм подписи, определяется типом ключа
+	private crypto.Signer     // Закрытый ключ, есть только у ключей, которыми можно подписывать
+	public  crypto.PublicKey  // Открытый ключ для проверки подписи
+}
+
+// JWK - открытый ключ в формате JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // Модуль RSA ключа
+	E   string `json:"e,omitempty"`   // Экспонента RSA ключа
+	Crv string `json:"crv,omitempty"` // Кривая OKP ключа
+	X   string `json:"x,omitempty"`   // Открытый ключ Ed25519
+}
+
+// JWKS - набор открытых ключей для проверки токенов сторонними сервисами
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// loadKeys - загружает ключи из PEM файлов конфигурации. Ключ signing_key должен содержать закрытый ключ,
+// остальные ключи используются только для проверки подписи (ротация без простоя)
+func loadKeys(cfg *config.Config) (map[string]*signingKey, *signingKey, error) {
+	keys := make(map[string]*signingKey, len(cfg.Jwt.Keys))
+
+	for _, keyCfg := range cfg.Jwt.Keys {
+		if keyCfg.Kid == "" {
+			return nil, nil, errors.New("у ключа JWT не указан kid")
+		}
+
+		if _, ok := keys[keyCfg.Kid]; ok {
+			return nil, nil, fmt.Errorf("ключ JWT %q указан несколько раз", keyCfg.Kid)
+		}
+
+		key, err := loadKey(keyCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ключ JWT %q: %v", keyCfg.Kid, err)
+		}
+
+		keys[key.kid] = key
+	}
+
+	signing, ok := keys[cfg.Jwt.SigningKey]
+	if !ok {
+		return nil, nil, fmt.Errorf("ключ подписи JWT %q не найден среди ключей", cfg.Jwt.SigningKey)
+	}
+
+	if signing.private == nil {
+		return nil, nil, fmt.Errorf("для ключа подписи JWT %q не указан закрытый ключ", signing.kid)
+	}
+
+	return keys, signing, nil
+}
+
+// loadKey - загружает ключ из PEM файлов, открытый ключ вычисляется из закрытого, если он не указан отдельно
+func loadKey(keyCfg config.JwtKey) (*signingKey, error) {
+	key := &signingKey{kid: keyCfg.Kid}
+
+	if keyCfg.PrivateKey != "" {
+		block, err := readPEM(keyCfg.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		private, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, err
+		}
+
+		key.private = private
+		key.public = private.Public()
+	}
+
+	if keyCfg.PublicKey != "" {
+		block, err := readPEM(keyCfg.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при разборе открытого ключа: %v", err)
+		}
+
+		key.public = public
+	}
+
+	switch key.public.(type) {
+	case *rsa.PublicKey:
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	case nil:
+		return nil, errors.New("не указан ни закрытый, ни открытый ключ")
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип ключа %T, допустимы RSA и Ed25519", key.public)
+	}
+
+	return key, nil
+}
+
+// readPEM - читает первый PEM блок из файла
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("файл %s не содержит PEM блок", path)
+	}
+
+	return block, nil
+}
+
+// parsePrivateKey - разбирает закрытый ключ в формате PKCS#8 или PKCS#1 (RSA)
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("неподдерживаемый тип закрытого ключа %T", key)
+		}
+		return signer, nil
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при разборе закрытого ключа: %v", err)
+	}
+
+	return key, nil
+}
+
+// jwk - открытый ключ в формате JWK
+func (k *signingKey) jwk() JWK {
+	jwk := JWK{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+
+	return jwk
+}
@@ -4,23 +4,49 @@ import (
 	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
 	"apiGateway/pkg/config"
 	"apiGateway/pkg/logger"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
+	"sort"
 	"time"
 )
 
+const (
+	accessTokenType  = "access"  // Тип токена доступа
+	refreshTokenType = "refresh" // Тип refresh токена
+	mfaTokenType     = "mfa"     // Тип токена второго шага входа
+)
+
 // tokenClaims - структура токена JWT
 type tokenClaims struct {
-	jwt.StandardClaims
-	UserId uint64 `json:"userId"`
-	Role   string `json:"role"`
+	jwt.RegisteredClaims
+	UserId    uint64 `json:"userId"`
+	Role      string `json:"role"`
+	SessionId uint64 `json:"sessionId"`
+	Mfa       bool   `json:"mfa,omitempty"` // Вход выполнен с подтверждением вторым фактором
+	Type      string `json:"typ"`
+}
+
+// RefreshClaims - структура refresh токена, ID токена (jti) меняется при каждой ротации
+type RefreshClaims struct {
+	jwt.RegisteredClaims
+	SessionId uint64 `json:"sessionId"`
+	Type      string `json:"typ"`
+}
+
+// MfaClaims - токен второго шага входа: пароль проверен, ожидается код TOTP. Subject - ID пользователя
+type MfaClaims struct {
+	jwt.RegisteredClaims
+	Type string `json:"typ"`
 }
 
 // IUser - интерфейс для доступа к полям субъекта токена JWT
 type IUser interface {
 	GetUserId() uint64
 	GetRole() string
+	GetSessionId() uint64
 }
 
 // GetUserId - возвращает ID пользователя из токена JWT
@@ -33,25 +59,138 @@ func (t *tokenClaims) GetRole() string {
 	return t.Role
 }
 
-// CreateToken - создание токена JWT
-func CreateToken(user *DatabaseServicev1.CreateUserResponse, cfg *config.Config) (string, error) {
-	parsedValue, err := time.ParseDuration(cfg.Jwt.Expires)
+// GetSessionId - возвращает ID сессии, в рамках которой выпущен токен JWT
+func (t *tokenClaims) GetSessionId() uint64 {
+	return t.SessionId
+}
+
+// GetMfa - вход выполнен с подтверждением вторым фактором
+func (t *tokenClaims) GetMfa() bool {
+	return t.Mfa
+}
+
+// Issuer - выпуск и проверка токенов JWT. Токены подписываются активным ключом (RS256 или EdDSA),
+// проверяются любым из ключей конфигурации по заголовку kid. Без ключей в конфигурации используется
+// HS256 с общим секретом (только для локальной разработки)
+type Issuer struct {
+	cfg     *config.Config
+	keys    map[string]*signingKey
+	signing *signingKey
+	parser  *jwt.Parser
+}
+
+// NewIssuer - создает Issuer и загружает ключи подписи из конфигурации. Подпись HS256 общим секретом допускается
+// только вне окружения prod
+func NewIssuer(cfg *config.Config) (*Issuer, error) {
+	if len(cfg.Jwt.Keys) == 0 {
+		if cfg.Env == config.EnvProd {
+			return nil, errors.New("в окружении prod не настроены ключи подписи jwt.keys, HS256 с общим секретом " +
+				"не допускается")
+		}
+
+		logger.Warn("Ключи JWT не настроены, токены подписываются HS256 общим секретом")
+
+		hmac := &signingKey{method: jwt.SigningMethodHS256}
+		keys := map[string]*signingKey{"": hmac}
+		return &Issuer{cfg: cfg, keys: keys, signing: hmac, parser: newParser(cfg, keys)}, nil
+	}
+
+	keys, signing, err := loadKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Issuer{cfg: cfg, keys: keys, signing: signing, parser: newParser(cfg, keys)}, nil
+}
+
+// newParser - парсер токенов: допускаются только алгоритмы загруженных ключей, обязательны exp и iat,
+// iss и aud сверяются с конфигурацией, exp/nbf/iat проверяются с допуском leeway
+func newParser(cfg *config.Config, keys map[string]*signingKey) *jwt.Parser {
+	methods := make([]string, 0, len(keys))
+	for _, key := range keys {
+		methods = append(methods, key.method.Alg())
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(cfg.Jwt.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+
+	if cfg.Jwt.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Jwt.Issuer))
+	}
+
+	if cfg.Jwt.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Jwt.Audience))
+	}
+
+	return jwt.NewParser(options...)
+}
+
+// registeredClaims - стандартные поля токена: издатель, аудитория, срок действия и идентификатор (jti)
+func (i *Issuer) registeredClaims(userId uint64, tokenId string, ttl time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+
+	claims := jwt.RegisteredClaims{
+		ID:        tokenId,
+		Issuer:    i.cfg.Jwt.Issuer,
+		Subject:   fmt.Sprint(userId),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+
+	if i.cfg.Jwt.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{i.cfg.Jwt.Audience}
+	}
+
+	return claims
+}
+
+// JWKS - открытые ключи для проверки подписи токенов, отсортированы по kid
+func (i *Issuer) JWKS() *JWKS {
+	jwks := &JWKS{Keys: make([]JWK, 0, len(i.keys))}
+
+	for _, key := range i.keys {
+		if key.method == jwt.SigningMethodHS256 {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, key.jwk())
+	}
+
+	sort.Slice(jwks.Keys, func(a, b int) bool {
+		return jwks.Keys[a].Kid < jwks.Keys[b].Kid
+	})
+
+	return jwks
+}
+
+// CreateToken - создание токена JWT, mfa - вход подтвержден вторым фактором
+func (i *Issuer) CreateToken(user *DatabaseServicev1.CreateUserResponse, sessionId uint64, mfa bool) (string, error) {
+	parsedValue, err := time.ParseDuration(i.cfg.Jwt.Expires)
 	if err != nil {
 		logger.Error("Ошибка при парсинге времени жизни токена: %v", err)
 		return "", err
 	}
 
-	standartClaims := jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(parsedValue).Unix(),
-		IssuedAt:  time.Now().Unix(),
+	tokenId, err := NewTokenId()
+	if err != nil {
+		logger.Error("Ошибка при генерации идентификатора токена: %v", err)
+		return "", err
 	}
+
 	claims := &tokenClaims{
-		StandardClaims: standartClaims,
-		UserId:         user.GetId(),
-		Role:           user.Role,
+		RegisteredClaims: i.registeredClaims(user.GetId(), tokenId, parsedValue),
+		UserId:           user.GetId(),
+		Role:             user.Role,
+		SessionId:        sessionId,
+		Mfa:              mfa,
+		Type:             accessTokenType,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(cfg.Jwt.Secret))
+
+	signedToken, err := i.sign(claims)
 	if err != nil {
 		logger.Error("Ошибка при подписи токена: %v", err)
 		return "", err
@@ -60,22 +199,152 @@ func CreateToken(user *DatabaseServicev1.CreateUserResponse, cfg *config.Config)
 	return signedToken, nil
 }
 
-// ParseToken - парсит токен из строки
-func ParseToken(accessToken string, cfg *config.Config) (*tokenClaims, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New(fmt.Sprint("Неверная подпись"))
-		}
-		return []byte(cfg.Jwt.Secret), nil
-	})
+// ParseToken - парсит токен из строки, ошибки проверки приводятся к ErrTokenExpired, ErrTokenSignature,
+// ErrTokenAudience или ErrTokenInvalid
+func (i *Issuer) ParseToken(accessToken string) (*tokenClaims, error) {
+	token, err := i.parser.ParseWithClaims(accessToken, &tokenClaims{}, i.verificationKey)
 	if err != nil {
-		return nil, err
+		return nil, classify(err)
 	}
 
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return nil, errors.New("token claims are not of type *tokenClaims")
+		return nil, fmt.Errorf("%w: token claims are not of type *tokenClaims", ErrTokenInvalid)
+	}
+
+	if claims.Type != accessTokenType || claims.ID == "" {
+		return nil, fmt.Errorf("%w: token is not an access token", ErrTokenInvalid)
 	}
 
 	return claims, nil
 }
+
+// NewTokenId - генерирует криптостойкий идентификатор для refresh токена (jti)
+func NewTokenId() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// CreateRefreshToken - создание refresh токена для сессии sessionId с идентификатором tokenId
+func (i *Issuer) CreateRefreshToken(userId, sessionId uint64, tokenId string) (string, error) {
+	parsedValue, err := time.ParseDuration(i.cfg.Jwt.RefreshExpires)
+	if err != nil {
+		logger.Error("Ошибка при парсинге времени жизни refresh токена: %v", err)
+		return "", err
+	}
+
+	claims := &RefreshClaims{
+		RegisteredClaims: i.registeredClaims(userId, tokenId, parsedValue),
+		SessionId:        sessionId,
+		Type:             refreshTokenType,
+	}
+
+	signedToken, err := i.sign(claims)
+	if err != nil {
+		logger.Error("Ошибка при подписи refresh токена: %v", err)
+		return "", err
+	}
+
+	return signedToken, nil
+}
+
+// ParseRefreshToken - парсит refresh токен из строки, ошибки проверки такие же, как у ParseToken
+func (i *Issuer) ParseRefreshToken(refreshToken string) (*RefreshClaims, error) {
+	token, err := i.parser.ParseWithClaims(refreshToken, &RefreshClaims{}, i.verificationKey)
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	claims, ok := token.Claims.(*RefreshClaims)
+	if !ok {
+		return nil, fmt.Errorf("%w: token claims are not of type *RefreshClaims", ErrTokenInvalid)
+	}
+
+	if claims.Type != refreshTokenType || claims.ID == "" || claims.SessionId == 0 {
+		return nil, fmt.Errorf("%w: token is not a refresh token", ErrTokenInvalid)
+	}
+
+	return claims, nil
+}
+
+// CreateMfaToken - создание токена второго шага входа для пользователя userId
+func (i *Issuer) CreateMfaToken(userId uint64) (string, error) {
+	tokenId, err := NewTokenId()
+	if err != nil {
+		logger.Error("Ошибка при генерации идентификатора токена: %v", err)
+		return "", err
+	}
+
+	ttl := i.cfg.Mfa.PendingTTL
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+
+	signedToken, err := i.sign(&MfaClaims{
+		RegisteredClaims: i.registeredClaims(userId, tokenId, ttl),
+		Type:             mfaTokenType,
+	})
+	if err != nil {
+		logger.Error("Ошибка при подписи токена второго шага входа: %v", err)
+		return "", err
+	}
+
+	return signedToken, nil
+}
+
+// ParseMfaToken - парсит токен второго шага входа из строки, ошибки проверки такие же, как у ParseToken
+func (i *Issuer) ParseMfaToken(mfaToken string) (*MfaClaims, error) {
+	token, err := i.parser.ParseWithClaims(mfaToken, &MfaClaims{}, i.verificationKey)
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	claims, ok := token.Claims.(*MfaClaims)
+	if !ok {
+		return nil, fmt.Errorf("%w: token claims are not of type *MfaClaims", ErrTokenInvalid)
+	}
+
+	if claims.Type != mfaTokenType || claims.ID == "" || claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token is not an mfa token", ErrTokenInvalid)
+	}
+
+	return claims, nil
+}
+
+// sign - подписывает токен активным ключом, в заголовок kid записывается идентификатор ключа
+func (i *Issuer) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(i.signing.method, claims)
+
+	if i.signing.method == jwt.SigningMethodHS256 {
+		return token.SignedString([]byte(i.cfg.Jwt.Secret))
+	}
+
+	token.Header["kid"] = i.signing.kid
+
+	return token.SignedString(i.signing.private)
+}
+
+// verificationKey - выбирает ключ проверки подписи по заголовку kid, алгоритм токена должен совпадать с алгоритмом ключа
+func (i *Issuer) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := i.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: неизвестный ключ подписи %q", jwt.ErrTokenSignatureInvalid, kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("%w: алгоритм %s не соответствует ключу %q", jwt.ErrTokenSignatureInvalid,
+			token.Method.Alg(), kid)
+	}
+
+	if key.method == jwt.SigningMethodHS256 {
+		return []byte(i.cfg.Jwt.Secret), nil
+	}
+
+	return key.public, nil
+}
@@ -0,0 +1,175 @@
+package token
+
+import (
+	DatabaseServicev1 "apiGateway/iternal/DatabaseService"
+	"apiGateway/pkg/config"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeKey - сохраняет закрытый ключ в PEM файл (PKCS#8) и возвращает путь к нему
+func writeKey(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestIssuerRotation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []config.JwtKey{
+		{Kid: "rsa-1", PrivateKey: writeKey(t, rsaKey)},
+		{Kid: "ed-2", PrivateKey: writeKey(t, edKey)},
+	}
+	user := &DatabaseServicev1.CreateUserResponse{Id: 7, Role: "user"}
+
+	oldCfg := &config.Config{Jwt: config.Jwt{Expires: "1h", SigningKey: "rsa-1", Keys: keys}}
+	oldIssuer, err := NewIssuer(oldCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldToken, err := oldIssuer.CreateToken(user, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newCfg := &config.Config{Jwt: config.Jwt{Expires: "1h", SigningKey: "ed-2", Keys: keys}}
+	newIssuer, err := NewIssuer(newCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newToken, err := newIssuer.CreateToken(user, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, str := range map[string]string{"rsa-1": oldToken, "ed-2": newToken} {
+		claims, err := newIssuer.ParseToken(str)
+		if err != nil {
+			t.Fatalf("токен, подписанный ключом %s, не прошел проверку: %v", name, err)
+		}
+		if claims.GetUserId() != user.Id {
+			t.Errorf("ключ %s: userId = %d, want %d", name, claims.GetUserId(), user.Id)
+		}
+	}
+
+	if got := len(newIssuer.JWKS().Keys); got != 2 {
+		t.Errorf("JWKS() содержит %d ключей, want 2", got)
+	}
+
+	// После удаления старого ключа из конфигурации выпущенные им токены не принимаются
+	retired, err := NewIssuer(&config.Config{Jwt: config.Jwt{Expires: "1h", SigningKey: "ed-2", Keys: keys[1:]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := retired.ParseToken(oldToken); err == nil {
+		t.Error("токен, подписанный удаленным ключом, прошел проверку")
+	}
+}
+
+func TestIssuerProd(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// В prod токены не подписываются общим секретом
+	if _, err = NewIssuer(&config.Config{Env: config.EnvProd, Jwt: config.Jwt{Secret: "secret"}}); err == nil {
+		t.Error("NewIssuer() без ключей в prod не вернул ошибку")
+	}
+
+	keys := []config.JwtKey{{Kid: "ed-1", PrivateKey: writeKey(t, edKey)}}
+	_, err = NewIssuer(&config.Config{Env: config.EnvProd, Jwt: config.Jwt{SigningKey: "ed-1", Keys: keys}})
+	if err != nil {
+		t.Errorf("NewIssuer() с ключами в prod: %v", err)
+	}
+}
+
+func TestParseTokenErrors(t *testing.T) {
+	user := &DatabaseServicev1.CreateUserResponse{Id: 7, Role: "user"}
+	jwtCfg := config.Jwt{Secret: "secret", Expires: "1h", Issuer: "apiGateway", Audience: "apiGateway"}
+
+	parser, err := NewIssuer(&config.Config{Jwt: jwtCfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		prepare func(cfg *config.Jwt)
+		wantErr error
+	}{
+		{
+			name:    "Валидный токен",
+			prepare: func(cfg *config.Jwt) {},
+		},
+		{
+			name:    "Истекший токен",
+			prepare: func(cfg *config.Jwt) { cfg.Expires = "-1h" },
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "Токен подписан другим секретом",
+			prepare: func(cfg *config.Jwt) { cfg.Secret = "other" },
+			wantErr: ErrTokenSignature,
+		},
+		{
+			name:    "Токен выпущен для другой аудитории",
+			prepare: func(cfg *config.Jwt) { cfg.Audience = "other" },
+			wantErr: ErrTokenAudience,
+		},
+		{
+			name:    "Токен другого издателя",
+			prepare: func(cfg *config.Jwt) { cfg.Issuer = "other" },
+			wantErr: ErrTokenInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := jwtCfg
+			tt.prepare(&cfg)
+
+			issuer, err := NewIssuer(&config.Config{Jwt: cfg})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			str, err := issuer.CreateToken(user, 1, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = parser.ParseToken(str)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("ParseToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package vault
+
+import (
+	"apiGateway/pkg/config"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// keySize - размер ключа AES-256 и ключа отпечатка в байтах
+const keySize = 32
+
+var ErrUnknownKey = errors.New("ключ шифрования хранилища карт не найден")
+
+// Keys - версионированные ключи шифрования номеров карт и ключ отпечатка. Новые номера шифруются активным ключом,
+// остальные ключи нужны для чтения номеров, зашифрованных до ротации
+type Keys struct {
+	active      int
+	ciphers     map[int]cipher.AEAD
+	fingerprint []byte
+}
+
+// NewKeys - ключи из конфигурации, ключи и ключ отпечатка указываются в base64
+func NewKeys(cfg config.Vault) (*Keys, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, fmt.Errorf("не указаны ключи шифрования")
+	}
+
+	keys := &Keys{active: cfg.ActiveKey, ciphers: make(map[int]cipher.AEAD, len(cfg.Keys))}
+
+	for _, key := range cfg.Keys {
+		if _, ok := keys.ciphers[key.Version]; ok {
+			return nil, fmt.Errorf("ключ версии %d указан дважды", key.Version)
+		}
+
+		raw, err := decodeKey(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("ключ версии %d: %w", key.Version, err)
+		}
+
+		if keys.ciphers[key.Version], err = newAEAD(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := keys.ciphers[cfg.ActiveKey]; !ok {
+		return nil, fmt.Errorf("активный ключ версии %d отсутствует в списке ключей", cfg.ActiveKey)
+	}
+
+	var err error
+	if keys.fingerprint, err = decodeKey(cfg.FingerprintKey); err != nil {
+		return nil, fmt.Errorf("ключ отпечатка: %w", err)
+	}
+
+	return keys, nil
+}
+
+// NewEphemeralKeys - случайные ключи, которые теряются при перезапуске (только для разработки и тестов)
+func NewEphemeralKeys() (*Keys, error) {
+	raw := make([]byte, 2*keySize)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(raw[:keySize])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keys{active: 1, ciphers: map[int]cipher.AEAD{1: aead}, fingerprint: raw[keySize:]}, nil
+}
+
+// decodeKey - ключ из base64, длина ключа должна быть keySize байт
+func decodeKey(str string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("ключ должен быть в base64: %w", err)
+	}
+	if len(raw) != keySize {
+		return nil, fmt.Errorf("длина ключа %d байт, нужно %d", len(raw), keySize)
+	}
+
+	return raw, nil
+}
+
+// newAEAD - AES-256-GCM с ключом key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Seal - шифрует plaintext активным ключом, aad используется как дополнительные данные, чтобы зашифрованное
+// значение нельзя было переставить в другую запись (для номера карты - токен). Возвращает версию ключа
+// и nonce || ciphertext в base64
+func (k *Keys) Seal(aad, plaintext string) (int, string, error) {
+	aead := k.ciphers[k.active]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return 0, "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(aad))
+
+	return k.active, base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open - расшифровывает значение, зашифрованное ключом version с дополнительными данными aad
+func (k *Keys) Open(aad string, version int, ciphertext string) (string, error) {
+	aead, ok := k.ciphers[version]
+	if !ok {
+		return "", fmt.Errorf("%w: версия %d", ErrUnknownKey, version)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("зашифрованное значение повреждено")
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("зашифрованное значение повреждено: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// Active - версия активного ключа
+func (k *Keys) Active() int {
+	return k.active
+}
@@ -0,0 +1,118 @@
+package vault
+
+import (
+	"apiGateway/pkg/filestore"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry - зашифрованный номер карты
+type Entry struct {
+	Token       string    `json:"token"`
+	KeyVersion  int       `json:"keyVersion"`  // Версия ключа, которым зашифрован номер
+	Ciphertext  string    `json:"ciphertext"`  // nonce и номер карты, зашифрованный AES-256-GCM, в base64
+	Fingerprint string    `json:"fingerprint"` // HMAC-SHA256 номера карты, по нему находятся одинаковые карты
+	Last4       string    `json:"last4"`       // Последние 4 цифры номера для отображения
+	Brand       string    `json:"brand"`       // Платежная система, пустая строка для неизвестной системы
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Store - хранилище зашифрованных номеров карт
+type Store interface {
+	// Get - возвращает запись по токену, ok = false, если запись не найдена
+	Get(ctx context.Context, token string) (entry Entry, ok bool, err error)
+	// Put - сохраняет запись
+	Put(ctx context.Context, entry Entry) error
+	// List - возвращает все записи в порядке создания
+	List(ctx context.Context) ([]Entry, error)
+}
+
+// MemoryStore - номера карт в памяти процесса, теряются при перезапуске (только для разработки и тестов)
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore - создает хранилище номеров карт в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+// Get - возвращает запись по токену
+func (s *MemoryStore) Get(_ context.Context, token string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[token]
+
+	return entry, ok, nil
+}
+
+// Put - сохраняет запись
+func (s *MemoryStore) Put(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.Token] = entry
+
+	return nil
+}
+
+// List - возвращает все записи
+func (s *MemoryStore) List(_ context.Context) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return sortedEntries(s.entries), nil
+}
+
+// FileStore - номера карт в JSON файле, файл перезаписывается целиком при каждом изменении
+type FileStore struct {
+	entries *filestore.Map[string, Entry]
+}
+
+// NewFileStore - создает хранилище номеров карт в файле path, существующий файл загружается
+func NewFileStore(path string) (*FileStore, error) {
+	entries, err := filestore.Open[string, Entry](path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{entries: entries}, nil
+}
+
+// Get - возвращает запись по токену
+func (s *FileStore) Get(_ context.Context, token string) (Entry, bool, error) {
+	entry, ok := s.entries.Get(token)
+
+	return entry, ok, nil
+}
+
+// Put - сохраняет запись
+func (s *FileStore) Put(_ context.Context, entry Entry) error {
+	return s.entries.Put(entry.Token, entry)
+}
+
+// List - возвращает все записи
+func (s *FileStore) List(_ context.Context) ([]Entry, error) {
+	return sortedEntries(s.entries.All()), nil
+}
+
+// sortedEntries - записи в порядке создания
+func sortedEntries(entries map[string]Entry) []Entry {
+	list := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].Token < list[j].Token
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list
+}
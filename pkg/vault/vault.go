@@ -0,0 +1,155 @@
+package vault
+
+import (
+	"apiGateway/pkg/bankcard"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenPrefix - префикс токена карты, по нему токен отличается от номера карты, сохраненного до появления хранилища
+const TokenPrefix = "tok_"
+
+var (
+	ErrNotFound    = errors.New("токен карты не найден")
+	ErrInvalidCard = errors.New("номер карты должен содержать от 12 до 19 цифр")
+)
+
+// Vault - хранилище номеров карт. В DatabaseService вместо номера карты передается непрозрачный токен,
+// номер хранится в шлюзе зашифрованным и расшифровывается только для платежного провайдера. CVV не хранится
+type Vault struct {
+	store Store
+	keys  *Keys
+	now   func() time.Time
+}
+
+// New - создает хранилище номеров карт поверх store
+func New(store Store, keys *Keys) *Vault {
+	return &Vault{store: store, keys: keys, now: time.Now}
+}
+
+// Keys - ключи шифрования хранилища, ими же шифруются другие секреты шлюза (например, секреты TOTP)
+func (v *Vault) Keys() *Keys {
+	return v.keys
+}
+
+// IsToken - строка является токеном карты, а не номером
+func IsToken(str string) bool {
+	return strings.HasPrefix(str, TokenPrefix)
+}
+
+// Tokenize - шифрует номер карты и возвращает запись с токеном, отпечатком, последними 4 цифрами и платежной системой
+func (v *Vault) Tokenize(ctx context.Context, number string) (Entry, error) {
+	pan := Digits(number)
+	if len(pan) < 12 || len(pan) > 19 {
+		return Entry{}, ErrInvalidCard
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		Token:       TokenPrefix + hex.EncodeToString(random),
+		Fingerprint: v.Fingerprint(pan),
+		Last4:       pan[len(pan)-4:],
+		Brand:       string(bankcard.Detect(pan)),
+		CreatedAt:   v.now().UTC(),
+	}
+
+	var err error
+	if entry.KeyVersion, entry.Ciphertext, err = v.keys.Seal(entry.Token, pan); err != nil {
+		return Entry{}, err
+	}
+
+	if err = v.store.Put(ctx, entry); err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// Lookup - запись по токену без расшифровки номера
+func (v *Vault) Lookup(ctx context.Context, token string) (Entry, error) {
+	entry, ok, err := v.store.Get(ctx, token)
+	if err != nil {
+		return Entry{}, err
+	}
+	if !ok {
+		return Entry{}, ErrNotFound
+	}
+
+	return entry, nil
+}
+
+// Detokenize - расшифровывает номер карты по токену, номер нужен только для авторизации платежа
+func (v *Vault) Detokenize(ctx context.Context, token string) (string, error) {
+	entry, err := v.Lookup(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	return v.keys.Open(entry.Token, entry.KeyVersion, entry.Ciphertext)
+}
+
+// Fingerprint - отпечаток номера карты (HMAC-SHA256), одинаковый для одного номера при любом ключе шифрования
+func (v *Vault) Fingerprint(number string) string {
+	mac := hmac.New(sha256.New, v.keys.fingerprint)
+	mac.Write([]byte(Digits(number)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Reencrypt - перешифровывает активным ключом номера, зашифрованные предыдущими ключами, и пересчитывает отпечатки
+// после замены ключа отпечатка. Возвращает количество измененных записей, после завершения предыдущие ключи можно
+// удалить из конфигурации
+func (v *Vault) Reencrypt(ctx context.Context) (int, error) {
+	entries, err := v.store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, entry := range entries {
+		pan, err := v.keys.Open(entry.Token, entry.KeyVersion, entry.Ciphertext)
+		if err != nil {
+			return count, fmt.Errorf("токен %s: %w", entry.Token, err)
+		}
+
+		fingerprint := v.Fingerprint(pan)
+		if entry.KeyVersion == v.keys.active && entry.Fingerprint == fingerprint {
+			continue
+		}
+
+		if entry.KeyVersion != v.keys.active {
+			if entry.KeyVersion, entry.Ciphertext, err = v.keys.Seal(entry.Token, pan); err != nil {
+				return count, err
+			}
+		}
+		entry.Fingerprint = fingerprint
+
+		if err = v.store.Put(ctx, entry); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Digits - цифры номера карты без пробелов и разделителей
+func Digits(number string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, number)
+}
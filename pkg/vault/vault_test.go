@@ -0,0 +1,159 @@
+package vault
+
+import (
+	"apiGateway/pkg/config"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPan = "4111 1111 1111 1111"
+
+func randomKey(t *testing.T) string {
+	raw := make([]byte, keySize)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestVault(t *testing.T) {
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "vault.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v1, fingerprint := randomKey(t), randomKey(t)
+	keys, err := NewKeys(config.Vault{ActiveKey: 1, Keys: []config.VaultKey{{Version: 1, Key: v1}},
+		FingerprintKey: fingerprint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault := New(store, keys)
+
+	if _, err = vault.Tokenize(ctx, "4111"); !errors.Is(err, ErrInvalidCard) {
+		t.Errorf("короткий номер: err = %v, want ErrInvalidCard", err)
+	}
+
+	first, err := vault.Tokenize(ctx, testPan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := vault.Tokenize(ctx, strings.ReplaceAll(testPan, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsToken(first.Token) || first.Token == second.Token || first.Last4 != "1111" || first.Brand != "visa" ||
+		first.KeyVersion != 1 {
+		t.Errorf("запись: %+v", first)
+	}
+	if first.Fingerprint != second.Fingerprint {
+		t.Errorf("отпечатки одного номера различаются")
+	}
+
+	// В файле хранится только зашифрованный номер
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), Digits(testPan)) {
+		t.Errorf("номер карты сохранен в открытом виде")
+	}
+
+	pan, err := vault.Detokenize(ctx, first.Token)
+	if err != nil || pan != Digits(testPan) {
+		t.Errorf("Detokenize() = %q, %v", pan, err)
+	}
+
+	if _, err = vault.Detokenize(ctx, TokenPrefix+"missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("неизвестный токен: err = %v, want ErrNotFound", err)
+	}
+
+	// Зашифрованный номер нельзя переставить в запись с другим токеном
+	swapped := second
+	swapped.Ciphertext = first.Ciphertext
+	if err = store.Put(ctx, swapped); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = vault.Detokenize(ctx, second.Token); err == nil {
+		t.Errorf("переставленный номер расшифрован")
+	}
+	if err = store.Put(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ротация: новый активный ключ, старые номера перешифровываются
+	rotated, err := NewKeys(config.Vault{ActiveKey: 2, Keys: []config.VaultKey{{Version: 1, Key: v1},
+		{Version: 2, Key: randomKey(t)}}, FingerprintKey: fingerprint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault = New(reloaded, rotated)
+
+	third, err := vault.Tokenize(ctx, testPan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.KeyVersion != 2 || third.Fingerprint != first.Fingerprint {
+		t.Errorf("запись после ротации: %+v", third)
+	}
+
+	count, err := vault.Reencrypt(ctx)
+	if err != nil || count != 2 {
+		t.Fatalf("Reencrypt() = %d, %v, want 2", count, err)
+	}
+	if count, err = vault.Reencrypt(ctx); err != nil || count != 0 {
+		t.Errorf("повторный Reencrypt() = %d, %v, want 0", count, err)
+	}
+
+	// После перешифрования предыдущий ключ не нужен
+	rotated.ciphers = map[int]cipher.AEAD{2: rotated.ciphers[2]}
+	for _, token := range []string{first.Token, second.Token, third.Token} {
+		if pan, err = vault.Detokenize(ctx, token); err != nil || pan != Digits(testPan) {
+			t.Errorf("Detokenize(%s) = %q, %v", token, pan, err)
+		}
+	}
+
+	// Замена ключа отпечатка: отпечатки пересчитываются при перешифровании
+	if rotated.fingerprint, err = decodeKey(randomKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if count, err = vault.Reencrypt(ctx); err != nil || count != 3 {
+		t.Fatalf("Reencrypt() после замены ключа отпечатка = %d, %v, want 3", count, err)
+	}
+	entry, err := vault.Lookup(ctx, first.Token)
+	if err != nil || entry.Fingerprint != vault.Fingerprint(testPan) || entry.Fingerprint == first.Fingerprint {
+		t.Errorf("отпечаток после замены ключа: %+v, %v", entry, err)
+	}
+}
+
+func TestNewKeys(t *testing.T) {
+	key := randomKey(t)
+
+	for name, cfg := range map[string]config.Vault{
+		"без ключей":    {ActiveKey: 1, FingerprintKey: key},
+		"нет активного": {ActiveKey: 2, Keys: []config.VaultKey{{Version: 1, Key: key}}, FingerprintKey: key},
+		"повтор версии": {ActiveKey: 1, Keys: []config.VaultKey{{Version: 1, Key: key}, {Version: 1, Key: key}},
+			FingerprintKey: key},
+		"короткий ключ":       {ActiveKey: 1, Keys: []config.VaultKey{{Version: 1, Key: "c2hvcnQ="}}, FingerprintKey: key},
+		"без ключа отпечатка": {ActiveKey: 1, Keys: []config.VaultKey{{Version: 1, Key: key}}},
+	} {
+		if _, err := NewKeys(cfg); err == nil {
+			t.Errorf("%s: ошибка не возвращена", name)
+		}
+	}
+}
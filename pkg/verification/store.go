@@ -0,0 +1,85 @@
+package verification
+
+import (
+	"apiGateway/pkg/filestore"
+	"apiGateway/pkg/notifier"
+	"apiGateway/pkg/utilities"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Store - подтвержденные телефоны и email пользователей. Подтверждение привязано к значению контакта:
+// после смены телефона или email его нужно подтвердить заново
+type Store interface {
+	// MarkVerified - отмечает контакт пользователя подтвержденным
+	MarkVerified(ctx context.Context, userId uint64, channel notifier.Channel, value string) error
+	// IsVerified - проверяет, что контакт пользователя подтвержден
+	IsVerified(ctx context.Context, userId uint64, channel notifier.Channel, value string) (bool, error)
+}
+
+// MemoryStore - подтверждения в памяти процесса, теряются при перезапуске (только для разработки и тестов)
+type MemoryStore struct {
+	mu       sync.Mutex
+	verified map[string]time.Time
+}
+
+// NewMemoryStore - создает хранилище подтверждений в памяти процесса
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{verified: make(map[string]time.Time)}
+}
+
+// MarkVerified - отмечает контакт пользователя подтвержденным
+func (s *MemoryStore) MarkVerified(_ context.Context, userId uint64, channel notifier.Channel, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.verified[key(userId, channel, value)] = time.Now().UTC()
+
+	return nil
+}
+
+// IsVerified - проверяет, что контакт пользователя подтвержден
+func (s *MemoryStore) IsVerified(_ context.Context, userId uint64, channel notifier.Channel,
+	value string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.verified[key(userId, channel, value)]
+
+	return ok, nil
+}
+
+// FileStore - подтверждения в JSON файле, файл перезаписывается целиком при каждом изменении
+type FileStore struct {
+	verified *filestore.Map[string, time.Time]
+}
+
+// NewFileStore - создает хранилище подтверждений в файле path, существующий файл загружается
+func NewFileStore(path string) (*FileStore, error) {
+	verified, err := filestore.Open[string, time.Time](path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{verified: verified}, nil
+}
+
+// MarkVerified - отмечает контакт пользователя подтвержденным
+func (s *FileStore) MarkVerified(_ context.Context, userId uint64, channel notifier.Channel, value string) error {
+	return s.verified.Put(key(userId, channel, value), time.Now().UTC())
+}
+
+// IsVerified - проверяет, что контакт пользователя подтвержден
+func (s *FileStore) IsVerified(_ context.Context, userId uint64, channel notifier.Channel,
+	value string) (bool, error) {
+	_, ok := s.verified.Get(key(userId, channel, value))
+
+	return ok, nil
+}
+
+// key - ключ подтверждения контакта, значение контакта хранится хэшем, чтобы файл не содержал телефоны и email
+func key(userId uint64, channel notifier.Channel, value string) string {
+	return fmt.Sprintf("%d:%s:%s", userId, channel, utilities.SHA256(value))
+}
@@ -0,0 +1,58 @@
+package verification
+
+import (
+	"apiGateway/pkg/notifier"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "verifications.json")
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.MarkVerified(ctx, 1, notifier.ChannelSMS, "+79991234567"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Подтверждение сохраняется после перезапуска
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		userId  uint64
+		channel notifier.Channel
+		value   string
+		want    bool
+	}{
+		{name: "Подтвержденный телефон", userId: 1, channel: notifier.ChannelSMS, value: "+79991234567", want: true},
+		{name: "Телефон изменен", userId: 1, channel: notifier.ChannelSMS, value: "+79991234568"},
+		{name: "Другой пользователь", userId: 2, channel: notifier.ChannelSMS, value: "+79991234567"},
+		{name: "Другой канал", userId: 1, channel: notifier.ChannelEmail, value: "+79991234567"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reloaded.IsVerified(ctx, tt.userId, tt.channel, tt.value)
+			if err != nil || got != tt.want {
+				t.Errorf("IsVerified() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "79991234567") {
+		t.Errorf("файл содержит телефон: %s", data)
+	}
+}